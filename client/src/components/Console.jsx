@@ -118,10 +118,8 @@ function Console() {
     }
     
     if (response.success) {
+      // message trae el texto del resultado, data el mismo resultado como objeto
       result += `✅ Éxito: ${response.message}\n`
-      if (response.data) {
-        result += `📄 Resultado: ${typeof response.data === 'string' ? response.data : JSON.stringify(response.data, null, 2)}\n`
-      }
     } else {
       result += `❌ Error: ${response.error}\n`
     }
@@ -143,9 +141,6 @@ function Console() {
       
       if (cmdResponse.success) {
         result += `    ✅ ${cmdResponse.message}\n`
-        if (cmdResponse.data) {
-          result += `    📄 ${typeof cmdResponse.data === 'string' ? cmdResponse.data : JSON.stringify(cmdResponse.data)}\n`
-        }
       } else {
         result += `    ❌ ${cmdResponse.error}\n`
       }
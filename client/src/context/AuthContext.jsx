@@ -73,6 +73,7 @@ export function AuthProvider({ children }) {
       setUser(null)
       setIsAuthenticated(false)
       localStorage.removeItem('mia_user')
+      localStorage.removeItem('mia_token')
       setIsLoading(false)
     }
   }
@@ -2,21 +2,46 @@ const API_BASE_URL = import.meta.env.VITE_API_URL
 //http://localhost:8080/api
 // import.meta.env.VITE_API_URL
 class ApiService {
+  // Cada cliente usa su propia sesion, el servidor devuelve en cada respuesta el token con el que hay que seguir
+  headers() {
+    const headers = {
+      'Content-Type': 'application/json',
+    }
+    const token = localStorage.getItem('mia_token')
+    if (token) {
+      headers['Authorization'] = `Bearer ${token}`
+    }
+    return headers
+  }
+
+  saveToken(result) {
+    if (result && result.token) {
+      localStorage.setItem('mia_token', result.token)
+    } else {
+      localStorage.removeItem('mia_token')
+    }
+  }
+
+  // Los errores llegan con su codigo HTTP y el JSON {success: false, code, error}, solo falla si no hay JSON
+  async readJSON(response) {
+    const result = await response.json().catch(() => null)
+    if (!response.ok && !result) {
+      throw new Error(`HTTP error! status: ${response.status}`)
+    }
+    return result
+  }
+
   async executeCommand(command) {
     try {
       const response = await fetch(`${API_BASE_URL}/command`, {
         method: 'POST',
-        headers: {
-          'Content-Type': 'application/json',
-        },
+        headers: this.headers(),
         body: JSON.stringify({ command }),
       })
 
-      if (!response.ok) {
-        throw new Error(`HTTP error! status: ${response.status}`)
-      }
-
-      return await response.json()
+      const result = await this.readJSON(response)
+      this.saveToken(result)
+      return result
     } catch (error) {
       console.error('Error ejecutando comando:', error)
       throw error
@@ -27,17 +52,13 @@ class ApiService {
     try {
       const response = await fetch(`${API_BASE_URL}/command`, {
         method: 'POST',
-        headers: {
-          'Content-Type': 'application/json',
-        },
+        headers: this.headers(),
         body: JSON.stringify({ command, input }),
       })
 
-      if (!response.ok) {
-        throw new Error(`HTTP error! status: ${response.status}`)
-      }
-
-      return await response.json()
+      const result = await this.readJSON(response)
+      this.saveToken(result)
+      return result
     } catch (error) {
       console.error('Error ejecutando comando con input:', error)
       throw error
@@ -48,17 +69,13 @@ class ApiService {
     try {
       const response = await fetch(`${API_BASE_URL}/batch`, {
         method: 'POST',
-        headers: {
-          'Content-Type': 'application/json',
-        },
+        headers: this.headers(),
         body: JSON.stringify({ commands }),
       })
 
-      if (!response.ok) {
-        throw new Error(`HTTP error! status: ${response.status}`)
-      }
-
-      return await response.json()
+      const result = await this.readJSON(response)
+      this.saveToken(result)
+      return result
     } catch (error) {
       console.error('Error ejecutando comandos en lote:', error)
       throw error
@@ -79,6 +96,7 @@ class ApiService {
     try {
       const loginCommand = `login -user=${usuario} -pass=${contraseña} -id=${idParticion}`
       
+      // El login abre una sesion nueva, no se manda el token anterior
       const response = await fetch(`${API_BASE_URL}/command`, {
         method: 'POST',
         headers: {
@@ -87,11 +105,8 @@ class ApiService {
         body: JSON.stringify({ command: loginCommand }),
       })
 
-      if (!response.ok) {
-        throw new Error(`HTTP error! status: ${response.status}`)
-      }
-
-      const result = await response.json()
+      const result = await this.readJSON(response)
+      this.saveToken(result)
       
       if (result.success) {
         // Login exitoso
@@ -124,17 +139,13 @@ class ApiService {
     try {
       const response = await fetch(`${API_BASE_URL}/command`, {
         method: 'POST',
-        headers: {
-          'Content-Type': 'application/json',
-        },
+        headers: this.headers(),
         body: JSON.stringify({ command: 'logout' }),
       })
 
-      if (!response.ok) {
-        throw new Error(`HTTP error! status: ${response.status}`)
-      }
-
-      return await response.json()
+      const result = await this.readJSON(response)
+      this.saveToken(result)
+      return result
     } catch (error) {
       console.error('Error en logout:', error)
       throw error
@@ -145,16 +156,10 @@ class ApiService {
     try {
       const response = await fetch(`${API_BASE_URL}/disks`, {
         method: 'GET',
-        headers: {
-          'Content-Type': 'application/json',
-        },
+        headers: this.headers(),
       })
 
-      if (!response.ok) {
-        throw new Error(`HTTP error! status: ${response.status}`)
-      }
-
-      return await response.json()
+      return await this.readJSON(response)
     } catch (error) {
       console.error('Error obteniendo discos:', error)
       throw error
@@ -165,16 +170,10 @@ class ApiService {
     try {
       const response = await fetch(`${API_BASE_URL}/partitions?disk=${diskId}`, {
         method: 'GET',
-        headers: {
-          'Content-Type': 'application/json',
-        },
+        headers: this.headers(),
       })
 
-      if (!response.ok) {
-        throw new Error(`HTTP error! status: ${response.status}`)
-      }
-
-      return await response.json()
+      return await this.readJSON(response)
     } catch (error) {
       console.error('Error obteniendo particiones:', error)
       throw error
@@ -190,22 +189,15 @@ class ApiService {
       
       const response = await fetch(url, {
         method: 'GET',
-        headers: {
-          'Content-Type': 'application/json',
-        },
+        headers: this.headers(),
         mode: 'cors',
       })
 
       console.log(`📊 Response status: ${response.status}`)
       console.log(`📊 Response headers:`, response.headers)
 
-      if (!response.ok) {
-        const errorText = await response.text()
-        console.error('❌ Error response:', response.status, errorText)
-        throw new Error(`HTTP ${response.status}: ${errorText}`)
-      }
-
-      const result = await response.json()
+      // Sin sesion en la particion llega 401 o 403 con el error en el JSON
+      const result = await this.readJSON(response)
       console.log('✅ Respuesta del servidor:', result)
       return result
     } catch (error) {
@@ -224,16 +216,10 @@ class ApiService {
     try {
       const response = await fetch(`${API_BASE_URL}/file-content?partition=${partitionId}&path=${encodeURIComponent(filePath)}`, {
         method: 'GET',
-        headers: {
-          'Content-Type': 'application/json',
-        },
+        headers: this.headers(),
       })
 
-      if (!response.ok) {
-        throw new Error(`HTTP error! status: ${response.status}`)
-      }
-
-      return await response.json()
+      return await this.readJSON(response)
     } catch (error) {
       console.error('Error obteniendo contenido del archivo:', error)
       throw error
@@ -2,7 +2,6 @@ package ext3
 
 import (
 	"encoding/binary"
-	"errors"
 	"fmt"
 	"server/reports"
 	"server/stores"
@@ -18,22 +17,23 @@ func GetLogicPartitions(diskName string) ([]string, []string, error) {
 	information := make([]string, 0)
 
 	mbr := &structures.MBR{}
-	path := stores.LoadedDiskPaths[diskName]
+	path := stores.LoadedDisks()[diskName]
 	err := mbr.DeserializeMBR(path)
 	if err != nil {
 		return nil, nil, err
 	}
-	for _, part := range mbr.Mbr_partitions {
-		if part.Part_type[0] != 'E' {
-			continue
-		}
-		return partitions, information, nil
-
+	logicals, _, err := mbr.GetLogicalPartitions(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, ebr := range logicals {
+		partitions = append(partitions, strings.TrimRight(string(ebr.Part_name[:]), "\x00"))
+		information = append(information, fmt.Sprintf("Start: %d Size: %d Fit: %c Mounted: %t", ebr.Part_start, ebr.Part_size, ebr.Part_fit[0], ebr.Part_mount[0] == '1'))
 	}
 	return partitions, information, nil
 }
 
-func GetAllContentByPath(diskName, partitionName, pathToGetInfo string) ([]string, []string, []string, []string, error) {
+func GetAllContentByPath(diskName, partitionName, pathToGetInfo string, userID, groupID int32) ([]string, []string, []string, []string, error) {
 	mbr := &structures.MBR{}
 	var idPartition string
 	var folderList []string
@@ -41,17 +41,21 @@ func GetAllContentByPath(diskName, partitionName, pathToGetInfo string) ([]strin
 	var fileInfo []string
 	var folderInfo []string
 
-	diskPath := stores.LoadedDiskPaths[diskName]
+	diskPath := stores.LoadedDisks()[diskName]
 	err := mbr.DeserializeMBR(diskPath)
 	if err != nil {
 		return nil, nil, nil, nil, err
 	}
-	for _, part := range mbr.Mbr_partitions {
+	partitions, err := mbr.GetAllPartitions(diskPath)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	for _, part := range partitions {
 		partName := strings.TrimRight(string(part.Part_name[:]), "\x00")
 		DestinationPartName := strings.Trim(partitionName, "\x00")
 		if strings.EqualFold(partName, DestinationPartName) {
 			if part.Part_status[0] == '0' {
-				return nil, nil, nil, nil, errors.New("particion no montada")
+				return nil, nil, nil, nil, structures.NewError(structures.ErrNotMounted, "particion no montada")
 			}
 			idPartition = strings.TrimRight(string(part.Part_id[:]), "\x00")
 			break
@@ -61,63 +65,34 @@ func GetAllContentByPath(diskName, partitionName, pathToGetInfo string) ([]strin
 	if err != nil {
 		return nil, nil, nil, nil, err
 	}
-	inodoBase, _, err := reports.UbicarInodo(superBlock, pathToGetInfo, diskPath)
+	_, inodoBase, err := superBlock.Lookup(diskPath, pathToGetInfo, userID, groupID)
 	if err != nil {
 		return nil, nil, nil, nil, err
 	}
 	if inodoBase.I_type[0] == '1' {
-		return nil, nil, nil, nil, errors.New("no se puede aplicar este reporte sobre un archivo")
+		return nil, nil, nil, nil, structures.NewError(structures.ErrInvalid, "no se puede aplicar este reporte sobre un archivo")
 	}
 
-	for i, blockIndex := range inodoBase.I_block {
-		if blockIndex == -1 {
-			continue
+	indexes, err := superBlock.FolderBlockIndexes(diskPath, inodoBase)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	for _, blockIndex := range indexes {
+		block := &structures.FolderBlock{}
+		err := block.Deserialize(diskPath, int64(superBlock.S_block_start+(blockIndex*superBlock.S_block_size)))
+		if err != nil {
+			return nil, nil, nil, nil, err
 		}
-		if i >= 14 {
-			pointerBlock := &structures.PointerBlock{}
-			err := pointerBlock.Deserialize(diskPath, int64(superBlock.S_block_start+(superBlock.S_block_size*blockIndex)))
-			if err != nil {
-				return nil, nil, nil, nil, err
-			}
-			for _, value := range pointerBlock.P_pointers {
-				if value == -1 {
-					continue
-				}
-				block := &structures.FolderBlock{}
-				err := block.Deserialize(diskPath, int64(superBlock.S_block_start+(value*superBlock.S_block_size)))
-				if err != nil {
-					return nil, nil, nil, nil, err
-				}
-				for i := 2; i < len(block.B_content); i++ {
-					content := block.B_content[i]
-					if content.B_inodo == -1 {
-						continue
-					}
-					fileList, folderList, fileInfo, folderInfo, err = getInformationByInode(fileList, folderList, fileInfo, folderInfo, content.B_inodo, superBlock, diskPath, strings.Trim(string(content.B_name[:]), "\x00"), idPartition)
-					if err != nil {
-						return nil, nil, nil, nil, err
-					}
-				}
+		for i := 2; i < len(block.B_content); i++ {
+			content := block.B_content[i]
+			if content.B_inodo == -1 {
+				continue
 			}
-
-		} else {
-			block := &structures.FolderBlock{}
-			err := block.Deserialize(diskPath, int64(superBlock.S_block_start+(blockIndex*superBlock.S_block_size)))
+			fileList, folderList, fileInfo, folderInfo, err = getInformationByInode(fileList, folderList, fileInfo, folderInfo, content.B_inodo, superBlock, diskPath, strings.Trim(string(content.B_name[:]), "\x00"), idPartition)
 			if err != nil {
 				return nil, nil, nil, nil, err
 			}
-			for i := 2; i < len(block.B_content); i++ {
-				content := block.B_content[i]
-				if content.B_inodo == -1 {
-					continue
-				}
-				fileList, folderList, fileInfo, folderInfo, err = getInformationByInode(fileList, folderList, fileInfo, folderInfo, content.B_inodo, superBlock, diskPath, strings.Trim(string(content.B_name[:]), "\x00"), idPartition)
-				if err != nil {
-					return nil, nil, nil, nil, err
-				}
-			}
 		}
-
 	}
 	return fileList, folderList, fileInfo, folderInfo, nil
 }
@@ -154,40 +129,6 @@ func getInformationByInode(fileList, folderList, fileInfo, folderInfo []string,
 	return fileList, folderList, fileInfo, folderInfo, nil
 }
 
-func GetContentFromFile(diskName, partitionName, pathToGetInfo string) (string, error) {
-	mbr := &structures.MBR{}
-	var result string
-	var idPartition string
-	diskPath := stores.LoadedDiskPaths[diskName]
-	err := mbr.DeserializeMBR(diskPath)
-	if err != nil {
-		return "", err
-	}
-	// Se obtiene el id
-	for _, part := range mbr.Mbr_partitions {
-		partName := strings.TrimRight(string(part.Part_name[:]), "\x00")
-		DestinationPartName := strings.Trim(partitionName, "\x00")
-		if strings.EqualFold(partName, DestinationPartName) {
-			if part.Part_status[0] == '0' {
-				return "", errors.New("particion no montada")
-			}
-			idPartition = strings.TrimRight(string(part.Part_id[:]), "\x00")
-			break
-		}
-	}
-	partitionSuperblock, _, partitionPath, err := stores.GetMountedPartitionSuperblock(idPartition)
-	if err != nil {
-		return "", err
-	}
-	parentDirs, destDir := utils.GetParentDirectories(pathToGetInfo)
-	content, err := partitionSuperblock.ContentFromFile(partitionPath, 0, parentDirs, destDir)
-	if err != nil {
-		return "", err
-	}
-	result += content
-	return result, nil
-}
-
 func getOwnerByID(id int32, idPartition string) (string, error) {
 	contentUsersTxt, err := reports.GetContetnUsersTxt(idPartition)
 	if err != nil {
@@ -204,7 +145,7 @@ func getOwnerByID(id int32, idPartition string) (string, error) {
 		}
 		return row[3], nil
 	}
-	return "", errors.New("no se encontro el usuario")
+	return "", structures.NewError(structures.ErrNotFound, "no se encontro el usuario")
 }
 
 func getGroupById(id int32, idPartition string) (string, error) {
@@ -223,96 +164,47 @@ func getGroupById(id int32, idPartition string) (string, error) {
 		}
 		return row[2], nil
 	}
-	return "", errors.New("no se encontro el usuario")
-}
-
-func GetJournal(diskName, partitionName string) ([]string, []string, []string, []string, error) {
-	mbr := &structures.MBR{}
-	var partitionStart int32
-	diskPath := stores.LoadedDiskPaths[diskName]
-	err := mbr.DeserializeMBR(diskPath)
-	if err != nil {
-		return nil, nil, nil, nil, err
-	}
-	for _, part := range mbr.Mbr_partitions {
-		partName := strings.TrimRight(string(part.Part_name[:]), "\x00")
-		DestinationPartName := strings.Trim(partitionName, "\x00")
-		if strings.EqualFold(partName, DestinationPartName) {
-			if part.Part_status[0] == '0' {
-				return nil, nil, nil, nil, errors.New("particion no montada")
-			}
-			partitionStart = part.Part_start
-			break
-		}
-	}
-	journal := &structures.Journal{}
-	err = journal.Deserialize(diskPath, int64(partitionStart+int32(binary.Size(structures.SuperBlock{}))))
-	if err != nil {
-		return nil, nil, nil, nil, err
-	}
-	commandList, pathList, contentList, dateList, err := getInformationJournals(journal, diskPath, make([]string, 0), make([]string, 0), make([]string, 0), make([]string, 0))
-	if err != nil {
-		return nil, nil, nil, nil, err
-	}
-	return commandList, pathList, contentList, dateList, nil
+	return "", structures.NewError(structures.ErrNotFound, "no se encontro el usuario")
 }
 
-func GetJournalForCommand(diskPath string, partitionStart int32) ([]string, []string, []string, []string, error) {
-	mbr := &structures.MBR{}
-	err := mbr.DeserializeMBR(diskPath)
+// GetJournalForCommand lee la cadena del journal para mostrarla, sin los passwords de mkusr, passwd y users.txt
+func GetJournalForCommand(sb *structures.SuperBlock, diskPath string, partitionStart int32) ([]string, []string, []string, []string, error) {
+	journals, _, err := sb.JournalChain(diskPath, partitionStart+int32(binary.Size(structures.SuperBlock{})))
 	if err != nil {
 		return nil, nil, nil, nil, err
 	}
-	journal := &structures.Journal{}
-	err = journal.Deserialize(diskPath, int64(partitionStart+int32(binary.Size(structures.SuperBlock{}))))
-	if err != nil {
-		return nil, nil, nil, nil, err
-	}
-	commandList, pathList, contentList, dateList, err := getInformationJournals(journal, diskPath, make([]string, 0), make([]string, 0), make([]string, 0), make([]string, 0))
-	if err != nil {
-		return nil, nil, nil, nil, err
+	var commandList, pathList, contentList, dateList []string
+	for _, journal := range journals {
+		command := strings.TrimRight(string(journal.J_content.I_operation[:]), "\x00")
+		path := strings.TrimRight(string(journal.J_content.I_path[:]), "\x00")
+		content := strings.TrimRight(string(journal.J_content.I_content[:]), "\x00")
+		commandList = append(commandList, command)
+		pathList = append(pathList, path)
+		contentList = append(contentList, utils.HideJournalPassword(command, path, content))
+		dateList = append(dateList, time.Unix(int64(journal.J_content.I_date), 0).Format("2006-01-02"))
 	}
 	return commandList, pathList, contentList, dateList, nil
 }
 
-func getInformationJournals(journal *structures.Journal, diskPath string, commandList, pathList, contentList, dateList []string) ([]string, []string, []string, []string, error) {
-	command := string(journal.J_content.I_operation[:])
-	path := string(journal.J_content.I_path[:])
-	content := string(journal.J_content.I_content[:])
-	date := time.Unix(int64(journal.J_content.I_date), 0).Format("2006-01-02")
-
-	commandList = append(commandList, command)
-	pathList = append(pathList, path)
-	contentList = append(contentList, content)
-	dateList = append(dateList, date)
-
-	if journal.J_next == -1 {
-		return commandList, pathList, contentList, dateList, nil
-	}
-
-	neoJournal := &structures.Journal{}
-	err := neoJournal.Deserialize(diskPath, int64(journal.J_next))
-	if err != nil {
-		return nil, nil, nil, nil, err
-	}
-	return getInformationJournals(neoJournal, diskPath, commandList, pathList, contentList, dateList)
-}
-
 func IsExt3(diskName, partitionName string) (bool, error) {
 	mbr := &structures.MBR{}
 	var idPartition string
-	diskPath := stores.LoadedDiskPaths[diskName]
+	diskPath := stores.LoadedDisks()[diskName]
 	err := mbr.DeserializeMBR(diskPath)
 	if err != nil {
 		return false, err
 	}
 	// Se obtiene el id
-	for _, part := range mbr.Mbr_partitions {
+	partitions, err := mbr.GetAllPartitions(diskPath)
+	if err != nil {
+		return false, err
+	}
+	for _, part := range partitions {
 		partName := strings.TrimRight(string(part.Part_name[:]), "\x00")
 		DestinationPartName := strings.Trim(partitionName, "\x00")
 		if strings.EqualFold(partName, DestinationPartName) {
 			if part.Part_status[0] == '0' {
-				return false, errors.New("particion no montada")
+				return false, structures.NewError(structures.ErrNotMounted, "particion no montada")
 			}
 			idPartition = strings.TrimRight(string(part.Part_id[:]), "\x00")
 			break
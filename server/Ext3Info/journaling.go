@@ -1,11 +1,11 @@
 package ext3
 
 import (
-	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	stores "server/stores"
+	"server/structures"
 	"server/utils"
 	"strings"
 )
@@ -74,8 +74,8 @@ func getContentJournaling(id string) ([]string, []string, []string, []string, er
 	}
 	outcome := sb.IsExt3()
 	if !outcome {
-		return nil, nil, nil, nil, errors.New("este comando no es aplicable porque el sistema de archivos no es ext3")
+		return nil, nil, nil, nil, structures.NewError(structures.ErrInvalid, "este comando no es aplicable porque el sistema de archivos no es ext3")
 	}
-	return GetJournalForCommand(diskPath, part.Part_start)
+	return GetJournalForCommand(sb, diskPath, part.Part_start)
 
 }
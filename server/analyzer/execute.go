@@ -1,10 +1,11 @@
 package analyzer
 
 import (
-	"errors"
 	"fmt"
 	"os"
-	"regexp"
+	commands "server/commands"
+	"server/lexer"
+	"server/stores"
 	"strings"
 )
 
@@ -12,72 +13,81 @@ type EXECUTE struct {
 	path string
 }
 
-func ParseExecute(tokens []string) (string, error) {
-	cmd := &EXECUTE{}
-	args := strings.Join(tokens, " ")
-	re := regexp.MustCompile(`-path="[^"]+"|-path=[^\s]+`)
-	matches := re.FindAllString(args, -1)
+// ExecuteLine es el resultado de una linea del script, Result o Error segun Success
+type ExecuteLine struct {
+	Command string          `json:"command"`
+	Success bool            `json:"success"`
+	Result  commands.Result `json:"result,omitempty"`
+	Error   string          `json:"error,omitempty"`
+}
 
-	if len(matches) != len(tokens) {
-		for _, token := range tokens {
-			if !re.MatchString(token) {
-				return "", fmt.Errorf("parámetro inválido: %s", token)
-			}
-		}
-	}
-	for _, match := range matches {
-		kv := strings.SplitN(match, "=", 2)
-		key := strings.ToLower(kv[0])
+type ExecuteResult struct {
+	Path  string        `json:"path"`
+	Lines []ExecuteLine `json:"lines"`
+}
 
-		switch key {
-		case "-path":
-			if len(kv) != 2 {
-				return "", fmt.Errorf("formato de parámetro inválido: %s", match)
-			}
-			value := kv[1]
-			if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
-				value = strings.Trim(value, "\"")
-			}
-			cmd.path = value
-		default:
-			return "", fmt.Errorf("parámetro desconocido: %s", key)
+func (result ExecuteResult) String() string {
+	outcome := "EXECUTE: ejecutado correctamente.\n"
+	for _, line := range result.Lines {
+		if !line.Success {
+			outcome += fmt.Sprintf("Error: %v\n", line.Error)
+			continue
 		}
+		outcome += fmt.Sprintf("%v\n", line.Result)
 	}
+	return outcome
+}
 
-	if cmd.path == "" {
-		return "", errors.New("faltan parámetros requeridos: -path")
+var executeParams = lexer.Schema{
+	{Name: "path", Required: true, Description: "Archivo de la computadora con los comandos"},
+}
+
+func init() {
+	commands.Register(commands.Command{
+		Name:        "execute",
+		Description: "Ejecuta los comandos de un script, una linea por comando",
+		Params:      executeParams,
+		Run:         ParseExecute,
+	})
+}
+
+func ParseExecute(session *stores.Session, tokens []string) (commands.Result, error) {
+	args, err := executeParams.Parse(tokens)
+	if err != nil {
+		return nil, err
 	}
+	cmd := &EXECUTE{path: args.String("path")}
 
-	outcomeCmd, err := commandExecute(cmd)
+	result, err := commandExecute(session, cmd)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return fmt.Sprintf("EXECUTE: ejecutado correctamente.\n%s", outcomeCmd), nil
+	return result, nil
 
 }
 
-func commandExecute(exec *EXECUTE) (string, error) {
+func commandExecute(session *stores.Session, exec *EXECUTE) (*ExecuteResult, error) {
 	commands, err := getCommands(exec.path)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	var outcome string
+	result := &ExecuteResult{Path: exec.path, Lines: []ExecuteLine{}}
 	for _, cmd := range commands {
 		if cmd == "exit" {
 			break
 		} else if strings.HasPrefix(cmd, "#") {
 			continue
 		}
-		msg, err := Analyzer(cmd)
+		msg, err := AnalyzerAs(session, cmd)
 		if err != nil {
-			outcome += fmt.Sprintf("Error: %v\n", err)
+			result.Lines = append(result.Lines, ExecuteLine{Command: cmd, Error: err.Error()})
 			continue
-		} else {
-			outcome += fmt.Sprintf("%v\n", msg)
+		} else if msg != nil {
+			result.Lines = append(result.Lines, ExecuteLine{Command: cmd, Success: true, Result: msg})
 		}
 	}
-	return outcome, nil
+	return result, nil
 
 }
 
@@ -0,0 +1,83 @@
+package analyzer
+
+import (
+	"server/stores"
+	"strings"
+)
+
+// Comandos que cambian el MBR o los EBR, toman el disco completo
+var diskCommands = map[string]bool{
+	"rmdisk":   true,
+	"fdisk":    true,
+	"mount":    true,
+	"unmount":  true,
+	"resizefs": true,
+}
+
+// Comandos que reciben -id y trabajan sobre esa particion
+var partitionCommands = map[string]bool{
+	"mkfs":     true,
+	"login":    true,
+	"rep":      true,
+	"recovery": true,
+	"loss":     true,
+	"fsck":     true,
+}
+
+// Comandos de la sesion que solo leen la particion
+var readOnlyCommands = map[string]bool{
+	"cat":  true,
+	"find": true,
+	"rep":  true,
+	"cd":   true,
+	"pwd":  true,
+}
+
+// Comandos que no tocan ningun disco, execute toma los candados en cada linea
+var unlockedCommands = map[string]bool{
+	"mkdisk":  true,
+	"mounted": true,
+	"execute": true,
+	"pause":   true,
+	"help":    true,
+}
+
+// lockCommand toma los candados del disco o la particion que toca el comando y devuelve la funcion que los libera
+func lockCommand(session *stores.Session, tokens []string) func() {
+	command := strings.ToLower(tokens[0])
+	if unlockedCommands[command] {
+		return func() {}
+	}
+	write := !readOnlyCommands[command]
+
+	if diskCommands[command] {
+		path := ""
+		if letter := paramValue(tokens[1:], "-driveletter"); letter != "" {
+			path = stores.GetPathDisk(strings.ToUpper(letter))
+		} else if id := paramValue(tokens[1:], "-id"); id != "" {
+			path = stores.MountedDiskPath(id)
+		}
+		if path == "" {
+			return func() {}
+		}
+		return stores.LockDisk(path, true)
+	}
+	if partitionCommands[command] {
+		return stores.LockPartition(paramValue(tokens[1:], "-id"), write)
+	}
+	// El resto trabaja sobre la particion de la sesion
+	if session.IdPartition == "" {
+		return func() {}
+	}
+	return stores.LockPartition(session.IdPartition, write)
+}
+
+func paramValue(tokens []string, name string) string {
+	for _, token := range tokens {
+		kv := strings.SplitN(token, "=", 2)
+		if len(kv) == 2 && strings.EqualFold(kv[0], name) {
+			return strings.Trim(kv[1], "\"")
+		}
+	}
+	return ""
+}
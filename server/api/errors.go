@@ -0,0 +1,61 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net/http"
+	"server/lexer"
+	"server/stores"
+	"server/structures"
+)
+
+// errorCodes traduce el tipo del error al codigo HTTP y al code de la respuesta, gana el primero que coincida
+var errorCodes = []struct {
+	kind   error
+	status int
+	code   string
+}{
+	{stores.ErrSession, http.StatusUnauthorized, "unauthorized"},
+	{structures.ErrNotMounted, http.StatusNotFound, "not_mounted"},
+	{structures.ErrNotFound, http.StatusNotFound, "not_found"},
+	{fs.ErrNotExist, http.StatusNotFound, "not_found"},
+	{structures.ErrPermission, http.StatusForbidden, "permission_denied"},
+	{structures.ErrNoSpace, http.StatusInsufficientStorage, "no_space"},
+	{structures.ErrExists, http.StatusConflict, "already_exists"},
+	{structures.ErrInvalid, http.StatusBadRequest, "invalid_argument"},
+	{lexer.ErrSyntax, http.StatusBadRequest, "invalid_argument"},
+	{structures.ErrCorrupt, http.StatusInternalServerError, "corrupt_filesystem"},
+}
+
+// errorStatus devuelve el codigo HTTP y el code del error, los errores sin tipo son internal
+func errorStatus(err error) (int, string) {
+	for _, entry := range errorCodes {
+		if errors.Is(err, entry.kind) {
+			return entry.status, entry.code
+		}
+	}
+	return http.StatusInternalServerError, "internal"
+}
+
+// writeError responde {success:false, code, error} con el codigo HTTP del tipo de error
+func writeError(w http.ResponseWriter, err error) {
+	status, code := errorStatus(err)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": false,
+		"code":    code,
+		"error":   err.Error(),
+	})
+}
+
+// errorResponse es la respuesta de un comando que fallo, en el lote cada resultado trae su code
+func errorResponse(err error) CommandResponse {
+	_, code := errorStatus(err)
+	return CommandResponse{
+		Success: false,
+		Error:   err.Error(),
+		Code:    code,
+	}
+}
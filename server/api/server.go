@@ -6,11 +6,11 @@ import (
 	"log"
 	"net/http"
 	"os"
-	"server/analyzer"
+	"server/commands"
 	"server/console"
+	"server/lexer"
 	"server/stores"
 	"server/structures"
-	"server/utils"
 	"strconv"
 	"strings"
 	"time"
@@ -23,13 +23,16 @@ type CommandRequest struct {
 
 type CommandResponse struct {
 	Success        bool        `json:"success"`
-	Message        string      `json:"message"`
-	Data           interface{} `json:"data,omitempty"`
+	Command        string      `json:"command,omitempty"` // Nombre del comando, dice el esquema de Data
+	Message        string      `json:"message"`           // El mismo texto que muestra la consola
+	Data           interface{} `json:"data,omitempty"`    // El resultado del comando, ver MANUAL_TECNICO.md
 	Error          string      `json:"error,omitempty"`
+	Code           string      `json:"code,omitempty"` // Tipo del error, ver MANUAL_TECNICO.md
 	RequiresInput  bool        `json:"requiresInput,omitempty"`
 	InputPrompt    string      `json:"inputPrompt,omitempty"`
 	InputType      string      `json:"inputType,omitempty"` // "enter", "yesno"
 	PendingCommand string      `json:"pendingCommand,omitempty"`
+	Token          string      `json:"token,omitempty"` // Sesion con la que debe seguir el cliente
 }
 
 type BatchCommandRequest struct {
@@ -40,6 +43,7 @@ type BatchCommandResponse struct {
 	Success bool              `json:"success"`
 	Results []CommandResponse `json:"results"`
 	Summary map[string]int    `json:"summary"`
+	Token   string            `json:"token,omitempty"`
 }
 
 func StartServer(port string) {
@@ -50,6 +54,7 @@ func StartServer(port string) {
 	http.HandleFunc("/api/filesystem", handleGetFileSystem)
 	http.HandleFunc("/api/file-content", handleGetFileContent)
 	http.HandleFunc("/api/health", handleHealth)
+	http.HandleFunc("/api/commands", handleGetCommands)
 
 	// Configurar CORS
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
@@ -82,6 +87,7 @@ func StartServer(port string) {
 	console.PrintInfo("   GET /api/filesystem?partition=<id>&path=<path> - Obtener contenido")
 	console.PrintInfo("   GET /api/file-content?partition=<id>&path=<path> - Obtener archivo")
 	console.PrintInfo("   GET /api/health - Estado del servidor")
+	console.PrintInfo("   GET /api/commands - Comandos y sus parametros")
 	console.PrintSeparator()
 
 	serverAddr := "0.0.0.0:" + port
@@ -118,38 +124,48 @@ func handleCommand(w http.ResponseWriter, r *http.Request) {
 
 	var req CommandRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response := CommandResponse{
-			Success: false,
-			Error:   "Error al decodificar el JSON: " + err.Error(),
-		}
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(response)
+		writeError(w, structures.Errorf(structures.ErrInvalid, "Error al decodificar el JSON: %w", err))
 		return
 	}
 
-	// Procesar comando directamente sin confirmaciones
-	result, err := analyzer.Analyzer(req.Command)
+	// Procesar comando directamente sin confirmaciones, con la sesion del cliente
+	result, token, err := executeWithSession(sessionToken(r), req.Command)
 
 	var response CommandResponse
+	status := http.StatusOK
 	if err != nil {
-		response = CommandResponse{
-			Success: false,
-			Error:   err.Error(),
-		}
+		response = errorResponse(err)
+		response.Token = token
+		status, _ = errorStatus(err)
 		console.PrintError(fmt.Sprintf("Error ejecutando comando '%s': %v", req.Command, err))
 	} else {
-		response = CommandResponse{
-			Success: true,
-			Message: "Comando ejecutado exitosamente",
-			Data:    result,
-		}
+		response = commandResponse(req.Command, result)
+		response.Token = token
 		console.PrintSuccess(fmt.Sprintf("Comando ejecutado: %s", req.Command))
 	}
 
 	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
 	json.NewEncoder(w).Encode(response)
 }
 
+// commandResponse arma la respuesta exitosa con el resultado tipado del comando
+func commandResponse(command string, result commands.Result) CommandResponse {
+	response := CommandResponse{
+		Success: true,
+		Message: "Comando ejecutado exitosamente",
+	}
+	if result == nil {
+		return response
+	}
+	if tokens, err := lexer.Tokenize(command); err == nil && len(tokens) > 0 {
+		response.Command = strings.ToLower(tokens[0])
+	}
+	response.Message = result.String()
+	response.Data = result
+	return response
+}
+
 func handleBatchCommands(w http.ResponseWriter, r *http.Request) {
 	enableCORS(w)
 
@@ -165,15 +181,7 @@ func handleBatchCommands(w http.ResponseWriter, r *http.Request) {
 
 	var req BatchCommandRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		response := BatchCommandResponse{
-			Success: false,
-			Results: []CommandResponse{{
-				Success: false,
-				Error:   "Error al decodificar el JSON: " + err.Error(),
-			}},
-		}
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(response)
+		writeError(w, structures.Errorf(structures.ErrInvalid, "Error al decodificar el JSON: %w", err))
 		return
 	}
 
@@ -182,6 +190,9 @@ func handleBatchCommands(w http.ResponseWriter, r *http.Request) {
 
 	console.PrintInfo(fmt.Sprintf("Ejecutando %d comandos en lote", len(req.Commands)))
 
+	// Un login o logout dentro del lote cambia la sesion de los comandos que siguen
+	token := sessionToken(r)
+
 	for i, command := range req.Commands {
 		command = strings.TrimSpace(command)
 
@@ -192,22 +203,17 @@ func handleBatchCommands(w http.ResponseWriter, r *http.Request) {
 
 		console.PrintCommand(fmt.Sprintf("[%d] %s", i+1, command))
 
-		result, err := analyzer.Analyzer(command)
+		var result commands.Result
+		var err error
+		result, token, err = executeWithSession(token, command)
 
 		var cmdResponse CommandResponse
 		if err != nil {
-			cmdResponse = CommandResponse{
-				Success: false,
-				Error:   err.Error(),
-			}
+			cmdResponse = errorResponse(err)
 			summary["error"]++
 			console.PrintError(fmt.Sprintf("Error en comando %d: %v", i+1, err))
 		} else {
-			cmdResponse = CommandResponse{
-				Success: true,
-				Message: "Comando ejecutado exitosamente",
-				Data:    result,
-			}
+			cmdResponse = commandResponse(command, result)
 			summary["success"]++
 			console.PrintSuccess(fmt.Sprintf("Comando %d ejecutado correctamente", i+1))
 		}
@@ -219,6 +225,7 @@ func handleBatchCommands(w http.ResponseWriter, r *http.Request) {
 		Success: summary["error"] == 0,
 		Results: results,
 		Summary: summary,
+		Token:   token,
 	}
 
 	console.PrintInfo(fmt.Sprintf("Lote completado: %d éxitos, %d errores", summary["success"], summary["error"]))
@@ -240,6 +247,29 @@ func handleHealth(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
+// handleGetCommands devuelve lo mismo que help, para que la consola web arme la ayuda y valide antes de enviar
+func handleGetCommands(w http.ResponseWriter, r *http.Request) {
+	enableCORS(w)
+
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if r.Method != "GET" {
+		http.Error(w, "Método no permitido", http.StatusMethodNotAllowed)
+		return
+	}
+
+	response := map[string]interface{}{
+		"success":  true,
+		"commands": commands.Registered(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
 func handleGetDisks(w http.ResponseWriter, r *http.Request) {
 	enableCORS(w)
 
@@ -257,14 +287,17 @@ func handleGetDisks(w http.ResponseWriter, r *http.Request) {
 	disks := []map[string]interface{}{}
 
 	// Debug: imprimir estado actual
-	console.PrintInfo(fmt.Sprintf("🔍 Consultando discos cargados: %d discos encontrados", len(stores.LoadedDiskPaths)))
+	loadedDisks := stores.LoadedDisks()
+	console.PrintInfo(fmt.Sprintf("🔍 Consultando discos cargados: %d discos encontrados", len(loadedDisks)))
 
-	for diskName, diskPath := range stores.LoadedDiskPaths {
+	for diskName, diskPath := range loadedDisks {
 		console.PrintInfo(fmt.Sprintf("  📀 Procesando disco: %s -> %s", diskName, diskPath))
 
 		// Leer información del MBR
 		mbr := &structures.MBR{}
+		unlock := stores.LockDisk(diskPath, false)
 		err := mbr.DeserializeMBR(diskPath)
+		unlock()
 		if err != nil {
 			console.PrintError(fmt.Sprintf("  ❌ Error al leer MBR del disco %s: %v", diskName, err))
 			continue // Saltar discos con errores
@@ -312,44 +345,38 @@ func handleGetPartitions(w http.ResponseWriter, r *http.Request) {
 	diskId := r.URL.Query().Get("disk")
 	if diskId == "" {
 		console.PrintError("Parámetro disk faltante en la solicitud")
-		http.Error(w, "Parámetro disk requerido", http.StatusBadRequest)
+		writeError(w, structures.NewError(structures.ErrInvalid, "Parámetro disk requerido"))
 		return
 	}
 
 	console.PrintInfo(fmt.Sprintf("🔍 Solicitud de particiones para disco: %s", diskId))
 
 	// Debug: Mostrar estado actual de discos cargados
-	console.PrintInfo(fmt.Sprintf("📊 Discos disponibles: %d", len(stores.LoadedDiskPaths)))
-	for letter, path := range stores.LoadedDiskPaths {
+	loadedDisks := stores.LoadedDisks()
+	console.PrintInfo(fmt.Sprintf("📊 Discos disponibles: %d", len(loadedDisks)))
+	for letter, path := range loadedDisks {
 		console.PrintInfo(fmt.Sprintf("  - %s: %s", letter, path))
 	}
 
 	// Obtener particiones reales del disco
-	diskPath, exists := stores.LoadedDiskPaths[diskId]
+	diskPath, exists := loadedDisks[diskId]
 	if !exists {
 		console.PrintError(fmt.Sprintf("❌ Disco %s no encontrado en discos cargados", diskId))
 
 		// Dar información detallada del error
-		response := map[string]interface{}{
-			"success": false,
-			"error":   fmt.Sprintf("Disco %s no encontrado. Discos disponibles: %v", diskId, getAvailableDiskIds()),
-		}
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(response)
+		writeError(w, structures.Errorf(structures.ErrNotFound, "Disco %s no encontrado. Discos disponibles: %v", diskId, getAvailableDiskIds()))
 		return
 	}
 
 	console.PrintInfo(fmt.Sprintf("✅ Disco encontrado: %s -> %s", diskId, diskPath))
 
+	// El MBR y los EBR no pueden cambiar mientras se leen
+	defer stores.LockDisk(diskPath, false)()
+
 	// Verificar que el archivo existe
 	if _, err := os.Stat(diskPath); os.IsNotExist(err) {
 		console.PrintError(fmt.Sprintf("❌ Archivo de disco no existe: %s", diskPath))
-		response := map[string]interface{}{
-			"success": false,
-			"error":   fmt.Sprintf("El archivo del disco %s no existe en %s", diskId, diskPath),
-		}
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(response)
+		writeError(w, structures.Errorf(structures.ErrNotFound, "El archivo del disco %s no existe en %s", diskId, diskPath))
 		return
 	}
 
@@ -357,12 +384,7 @@ func handleGetPartitions(w http.ResponseWriter, r *http.Request) {
 	err := mbr.DeserializeMBR(diskPath)
 	if err != nil {
 		console.PrintError(fmt.Sprintf("❌ Error al leer MBR del disco %s: %v", diskId, err))
-		response := map[string]interface{}{
-			"success": false,
-			"error":   fmt.Sprintf("Error al leer MBR del disco %s: %v", diskId, err),
-		}
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(response)
+		writeError(w, fmt.Errorf("Error al leer MBR del disco %s: %w", diskId, err))
 		return
 	}
 
@@ -371,7 +393,14 @@ func handleGetPartitions(w http.ResponseWriter, r *http.Request) {
 	partitions := []map[string]interface{}{}
 	partitionCount := 0
 
-	for i, partition := range mbr.Mbr_partitions {
+	allPartitions, err := mbr.GetAllPartitions(diskPath)
+	if err != nil {
+		console.PrintError(fmt.Sprintf("❌ Error al leer las particiones lógicas del disco %s: %v", diskId, err))
+		writeError(w, fmt.Errorf("Error al leer las particiones lógicas del disco %s: %w", diskId, err))
+		return
+	}
+
+	for i, partition := range allPartitions {
 		console.PrintInfo(fmt.Sprintf("  Partición %d: tipo=%c, start=%d, size=%d",
 			i, partition.Part_type[0], partition.Part_start, partition.Part_size))
 
@@ -443,7 +472,7 @@ func handleGetPartitions(w http.ResponseWriter, r *http.Request) {
 // Función auxiliar para obtener IDs de discos disponibles
 func getAvailableDiskIds() []string {
 	var ids []string
-	for id := range stores.LoadedDiskPaths {
+	for id := range stores.LoadedDisks() {
 		ids = append(ids, id)
 	}
 	return ids
@@ -466,38 +495,44 @@ func handleGetFileSystem(w http.ResponseWriter, r *http.Request) {
 	path := r.URL.Query().Get("path")
 
 	if partitionId == "" || path == "" {
-		http.Error(w, "Parámetros partition y path requeridos", http.StatusBadRequest)
+		writeError(w, structures.NewError(structures.ErrInvalid, "Parámetros partition y path requeridos"))
 		return
 	}
 
 	console.PrintInfo(fmt.Sprintf("📂 Solicitud filesystem - Partición: %s, Ruta: %s", partitionId, path))
 
+	// Se recorre con los permisos del usuario, sin sesion en la particion no se muestra nada
+	session, err := requestSession(r)
+	if err == nil && session.User == "" {
+		err = structures.NewError(stores.ErrSession, "debe iniciar sesion para ver el sistema de archivos")
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if !strings.EqualFold(session.IdPartition, partitionId) {
+		writeError(w, structures.NewError(structures.ErrPermission, "La sesion pertenece a la particion "+session.IdPartition))
+		return
+	}
+
 	// Verificar si la partición existe en particiones montadas
-	_, exists := stores.MountedPartitions[partitionId]
-	if !exists {
+	if stores.MountedDiskPath(partitionId) == "" {
 		console.PrintError(fmt.Sprintf("Partición %s no está montada", partitionId))
 
 		// Retornar error pero con estructura JSON válida
-		response := map[string]interface{}{
-			"success": false,
-			"error":   "La partición no está montada. Use el comando mount para montarla.",
-		}
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(response)
+		writeError(w, structures.NewError(structures.ErrNotMounted, "La partición no está montada. Use el comando mount para montarla."))
 		return
 	}
 
+	// Los comandos no pueden modificar la particion mientras se recorre
+	defer stores.LockPartition(partitionId, false)()
+
 	// Obtener contenido real del sistema de archivos
 	superBlock, _, diskPath, err := stores.GetMountedPartitionSuperblock(partitionId)
 	if err != nil {
 		console.PrintError(fmt.Sprintf("Error al obtener superblock: %v", err))
 
-		response := map[string]interface{}{
-			"success": false,
-			"error":   "Error al obtener información de la partición: " + err.Error(),
-		}
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(response)
+		writeError(w, fmt.Errorf("Error al obtener información de la partición: %w", err))
 		return
 	}
 
@@ -505,34 +540,21 @@ func handleGetFileSystem(w http.ResponseWriter, r *http.Request) {
 	if superBlock.S_magic != 0xEF53 {
 		console.PrintWarning("Partición no formateada")
 
-		response := map[string]interface{}{
-			"success": false,
-			"error":   "La partición no está formateada. Use el comando mkfs primero.",
-		}
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(response)
+		writeError(w, structures.NewError(structures.ErrInvalid, "La partición no está formateada. Use el comando mkfs primero."))
 		return
 	}
 
 	console.PrintInfo(fmt.Sprintf("✅ Superblock válido - Magic: 0x%X", superBlock.S_magic))
 
-	// Para la raíz, siempre usar inodo 0
-	var targetInodeIndex int32 = 0
-	if path != "/" {
-		// Navegar al directorio especificado
-		inodeIndex, err := navigateToPath(superBlock, diskPath, path)
-		if err != nil {
-			console.PrintError(fmt.Sprintf("Error al navegar: %v", err))
+	targetInodeIndex, targetInode, err := superBlock.Lookup(diskPath, path, session.UserID, session.GroupID)
+	if err == nil && targetInode.I_type[0] != '0' {
+		err = structures.Errorf(structures.ErrNotFound, "'%s' no es un directorio", path)
+	}
+	if err != nil {
+		console.PrintError(fmt.Sprintf("Error al navegar: %v", err))
 
-			response := map[string]interface{}{
-				"success": false,
-				"error":   "Ruta no encontrada: " + err.Error(),
-			}
-			w.Header().Set("Content-Type", "application/json")
-			json.NewEncoder(w).Encode(response)
-			return
-		}
-		targetInodeIndex = inodeIndex
+		writeError(w, fmt.Errorf("Ruta no encontrada: %w", err))
+		return
 	}
 
 	console.PrintInfo(fmt.Sprintf("🎯 Leyendo inodo: %d", targetInodeIndex))
@@ -542,12 +564,7 @@ func handleGetFileSystem(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		console.PrintError(fmt.Sprintf("Error al leer contenido: %v", err))
 
-		response := map[string]interface{}{
-			"success": false,
-			"error":   "Error al leer contenido del directorio: " + err.Error(),
-		}
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(response)
+		writeError(w, fmt.Errorf("Error al leer contenido del directorio: %w", err))
 		return
 	}
 
@@ -568,147 +585,6 @@ func handleGetFileSystem(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
-func navigateToPath(sb *structures.SuperBlock, diskPath string, path string) (int32, error) {
-	// Normalizar la ruta
-	if path == "/" {
-		return 0, nil // Inodo raíz
-	}
-
-	// Limpiar la ruta y dividir en componentes
-	path = strings.Trim(path, "/")
-	pathComponents := strings.Split(path, "/")
-
-	currentInodeIndex := int32(0) // Empezar desde la raíz
-
-	// Navegar componente por componente
-	for _, component := range pathComponents {
-		if component == "" {
-			continue
-		}
-
-		console.PrintInfo(fmt.Sprintf("Buscando componente: %s en inodo %d", component, currentInodeIndex))
-
-		nextInodeIndex, err := findInodeInDirectory(sb, diskPath, currentInodeIndex, component)
-		if err != nil {
-			return -1, fmt.Errorf("no se encontró '%s' en la ruta: %v", component, err)
-		}
-
-		// Verificar que el inodo encontrado sea un directorio
-		inode := &structures.Inode{}
-		err = inode.Deserialize(diskPath, int64(sb.S_inode_start+(nextInodeIndex*sb.S_inode_size)))
-		if err != nil {
-			return -1, err
-		}
-
-		if inode.I_type[0] != '0' {
-			return -1, fmt.Errorf("'%s' no es un directorio", component)
-		}
-
-		currentInodeIndex = nextInodeIndex
-	}
-
-	return currentInodeIndex, nil
-}
-
-func findInodeInDirectory(sb *structures.SuperBlock, diskPath string, dirInodeIndex int32, searchName string) (int32, error) {
-	inode := &structures.Inode{}
-	err := inode.Deserialize(diskPath, int64(sb.S_inode_start+(dirInodeIndex*sb.S_inode_size)))
-	if err != nil {
-		return -1, fmt.Errorf("error al deserializar inodo %d: %v", dirInodeIndex, err)
-	}
-
-	// Verificar que sea un directorio
-	if inode.I_type[0] != '0' {
-		return -1, fmt.Errorf("el inodo %d no es un directorio (tipo: %c)", dirInodeIndex, inode.I_type[0])
-	}
-
-	console.PrintInfo(fmt.Sprintf("Buscando '%s' en directorio inodo %d", searchName, dirInodeIndex))
-
-	// Buscar en todos los bloques del directorio
-	for i, blockIndex := range inode.I_block {
-		if blockIndex == -1 {
-			break
-		}
-
-		console.PrintInfo(fmt.Sprintf("Revisando bloque %d (índice %d)", i, blockIndex))
-
-		if i >= 14 {
-			// Manejar bloques indirectos
-			pointerBlock := &structures.PointerBlock{}
-			err := pointerBlock.Deserialize(diskPath, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
-			if err != nil {
-				console.PrintError(fmt.Sprintf("Error al deserializar bloque indirecto: %v", err))
-				continue
-			}
-
-			for _, ptrIndex := range pointerBlock.P_pointers {
-				if ptrIndex == -1 {
-					continue
-				}
-
-				folderBlock := &structures.FolderBlock{}
-				err := folderBlock.Deserialize(diskPath, int64(sb.S_block_start+(ptrIndex*sb.S_block_size)))
-				if err != nil {
-					continue
-				}
-
-				inodeIndex, found := searchInFolderBlock(folderBlock, searchName)
-				if found {
-					console.PrintInfo(fmt.Sprintf("Encontrado '%s' en inodo %d", searchName, inodeIndex))
-					return inodeIndex, nil
-				}
-			}
-		} else {
-			// Bloques directos
-			folderBlock := &structures.FolderBlock{}
-			err := folderBlock.Deserialize(diskPath, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
-			if err != nil {
-				console.PrintError(fmt.Sprintf("Error al deserializar bloque folder: %v", err))
-				continue
-			}
-
-			// Debug: imprimir contenido del bloque
-			console.PrintInfo(fmt.Sprintf("Contenido del bloque %d:", blockIndex))
-			for j, content := range folderBlock.B_content {
-				if content.B_inodo != -1 {
-					name := strings.TrimRight(string(content.B_name[:]), "\x00")
-					console.PrintInfo(fmt.Sprintf("  [%d] Nombre: '%s', Inodo: %d", j, name, content.B_inodo))
-				}
-			}
-
-			inodeIndex, found := searchInFolderBlock(folderBlock, searchName)
-			if found {
-				console.PrintInfo(fmt.Sprintf("Encontrado '%s' en inodo %d", searchName, inodeIndex))
-				return inodeIndex, nil
-			}
-		}
-	}
-
-	return -1, fmt.Errorf("no se encontró '%s' en el directorio inodo %d", searchName, dirInodeIndex)
-}
-
-func searchInFolderBlock(block *structures.FolderBlock, searchName string) (int32, bool) {
-	for i := 0; i < len(block.B_content); i++ {
-		content := block.B_content[i]
-		if content.B_inodo == -1 {
-			continue
-		}
-
-		contentName := strings.TrimRight(string(content.B_name[:]), "\x00")
-
-		// Saltar entradas especiales pero NO saltar entradas con guión
-		if contentName == "." || contentName == ".." || contentName == "" {
-			continue
-		}
-
-		// Importante: no saltar entradas con "-" porque pueden ser archivos válidos
-		if strings.EqualFold(contentName, searchName) {
-			return content.B_inodo, true
-		}
-	}
-	return -1, false
-}
-
 func getDirectoryContentFromInode(sb *structures.SuperBlock, diskPath string, inodeIndex int32, partitionId string) ([]map[string]interface{}, []map[string]interface{}, error) {
 	console.PrintInfo(fmt.Sprintf("🔍 Leyendo inodo %d en posición: %d", inodeIndex, sb.S_inode_start+(inodeIndex*sb.S_inode_size)))
 
@@ -728,55 +604,24 @@ func getDirectoryContentFromInode(sb *structures.SuperBlock, diskPath string, in
 	var folders []map[string]interface{}
 	var files []map[string]interface{}
 
-	// Recorrer todos los bloques del inodo
-	for i, blockIndex := range inode.I_block {
-		if blockIndex == -1 {
-			break
-		}
-
+	// Recorrer todos los bloques del inodo, directos e indirectos
+	blockIndexes, err := sb.FolderBlockIndexes(diskPath, inode)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error al leer los bloques del inodo %d: %v", inodeIndex, err)
+	}
+	for i, blockIndex := range blockIndexes {
 		console.PrintInfo(fmt.Sprintf("📦 Procesando bloque %d -> índice %d", i, blockIndex))
 
-		if i >= 14 {
-			// Manejar bloques indirectos
-			console.PrintInfo("🔗 Procesando bloque indirecto")
-			pointerBlock := &structures.PointerBlock{}
-			err := pointerBlock.Deserialize(diskPath, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
-			if err != nil {
-				console.PrintError(fmt.Sprintf("Error en bloque indirecto: %v", err))
-				continue
-			}
-
-			for j, ptrIndex := range pointerBlock.P_pointers {
-				if ptrIndex == -1 {
-					continue
-				}
-
-				console.PrintInfo(fmt.Sprintf("  📦 Sub-bloque %d -> índice %d", j, ptrIndex))
-
-				folderBlock := &structures.FolderBlock{}
-				err := folderBlock.Deserialize(diskPath, int64(sb.S_block_start+(ptrIndex*sb.S_block_size)))
-				if err != nil {
-					console.PrintError(fmt.Sprintf("Error al deserializar sub-bloque: %v", err))
-					continue
-				}
-
-				f, fl := processDirectoryBlock(folderBlock, sb, diskPath, partitionId)
-				folders = append(folders, f...)
-				files = append(files, fl...)
-			}
-		} else {
-			// Bloques directos
-			folderBlock := &structures.FolderBlock{}
-			err := folderBlock.Deserialize(diskPath, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
-			if err != nil {
-				console.PrintError(fmt.Sprintf("Error al deserializar bloque folder %d: %v", blockIndex, err))
-				continue
-			}
-
-			f, fl := processDirectoryBlock(folderBlock, sb, diskPath, partitionId)
-			folders = append(folders, f...)
-			files = append(files, fl...)
+		folderBlock := &structures.FolderBlock{}
+		err := folderBlock.Deserialize(diskPath, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
+		if err != nil {
+			console.PrintError(fmt.Sprintf("Error al deserializar bloque folder %d: %v", blockIndex, err))
+			continue
 		}
+
+		f, fl := processDirectoryBlock(folderBlock, sb, diskPath, partitionId)
+		folders = append(folders, f...)
+		files = append(files, fl...)
 	}
 
 	console.PrintInfo(fmt.Sprintf("✅ Contenido procesado: %d carpetas, %d archivos", len(folders), len(files)))
@@ -935,119 +780,6 @@ func getContetnUsersTxtSimple(partitionId string) (string, error) {
 	return content, nil
 }
 
-func processFileBlock(block *structures.FolderBlock, sb *structures.SuperBlock, diskPath string, partitionId string) ([]map[string]interface{}, []map[string]interface{}, error) {
-	var folders []map[string]interface{}
-	var files []map[string]interface{}
-
-	for i := 2; i < len(block.B_content); i++ { // Saltar "." y ".."
-		content := block.B_content[i]
-		if content.B_inodo == -1 {
-			continue
-		}
-
-		name := strings.TrimRight(string(content.B_name[:]), "\x00")
-		if name == "" {
-			continue
-		}
-
-		// Obtener información del inodo
-		itemInode := &structures.Inode{}
-		err := itemInode.Deserialize(diskPath, int64(sb.S_inode_start+(content.B_inodo*sb.S_inode_size)))
-		if err != nil {
-			continue
-		}
-
-		// Obtener permisos, propietario y grupo
-		permissions := getPermissionString(string(itemInode.I_perm[:]))
-		owner, _ := getOwnerByID(itemInode.I_uid, partitionId)
-		group, _ := getGroupByID(itemInode.I_gid, partitionId)
-		date := time.Unix(int64(itemInode.I_mtime), 0).Format("2006-01-02")
-
-		if itemInode.I_type[0] == '0' {
-			// Es una carpeta
-			folders = append(folders, map[string]interface{}{
-				"name":        name,
-				"permissions": permissions,
-				"owner":       owner,
-				"group":       group,
-				"size":        "4096",
-				"date":        date,
-			})
-		} else {
-			// Es un archivo
-			files = append(files, map[string]interface{}{
-				"name":        name,
-				"permissions": permissions,
-				"owner":       owner,
-				"group":       group,
-				"size":        fmt.Sprintf("%d", itemInode.I_size),
-				"date":        date,
-			})
-		}
-	}
-
-	return folders, files, nil
-}
-
-func getOwnerByID(id int32, partitionId string) (string, error) {
-	// Obtener contenido del users.txt
-	contentUsersTxt, err := getContetnUsersTxt(partitionId)
-	if err != nil {
-		return "unknown", err
-	}
-
-	strId := fmt.Sprintf("%d", id)
-	contentMatrix := getContentMatrixUsers(contentUsersTxt)
-
-	for _, row := range contentMatrix {
-		if len(row) < 4 {
-			continue
-		}
-		if row[0] == strId && row[1] == "U" {
-			return row[3], nil
-		}
-	}
-
-	return "unknown", nil
-}
-
-func getGroupByID(id int32, partitionId string) (string, error) {
-	// Obtener contenido del users.txt
-	contentUsersTxt, err := getContetnUsersTxt(partitionId)
-	if err != nil {
-		return "unknown", err
-	}
-
-	strId := fmt.Sprintf("%d", id)
-	contentMatrix := getContentMatrixUsers(contentUsersTxt)
-
-	for _, row := range contentMatrix {
-		if len(row) < 3 {
-			continue
-		}
-		if row[0] == strId && row[1] == "G" {
-			return row[2], nil
-		}
-	}
-
-	return "unknown", nil
-}
-
-func getContetnUsersTxt(partitionId string) (string, error) {
-	partitionSuperblock, _, partitionPath, err := stores.GetMountedPartitionSuperblock(partitionId)
-	if err != nil {
-		return "", err
-	}
-
-	parentDirs, destDir := utils.GetParentDirectories("/users.txt")
-	content, err := partitionSuperblock.ContentFromFile(partitionPath, 0, parentDirs, destDir)
-	if err != nil {
-		return "", err
-	}
-
-	return content, nil
-}
-
 func getContentMatrixUsers(contentUsers string) [][]string {
 	contentSplitedByEnters := strings.Split(contentUsers, "\n")
 	if len(contentSplitedByEnters) > 0 && contentSplitedByEnters[len(contentSplitedByEnters)-1] == "" {
@@ -1080,33 +812,48 @@ func handleGetFileContent(w http.ResponseWriter, r *http.Request) {
 	filePath := r.URL.Query().Get("path")
 
 	if partitionId == "" || filePath == "" {
-		http.Error(w, "Parámetros partition y path requeridos", http.StatusBadRequest)
+		writeError(w, structures.NewError(structures.ErrInvalid, "Parámetros partition y path requeridos"))
 		return
 	}
 
 	console.PrintInfo(fmt.Sprintf("Obteniendo contenido de archivo: %s en partición: %s", filePath, partitionId))
 
+	// El archivo se lee con los permisos del usuario de la sesion
+	session, err := requestSession(r)
+	if err == nil && session.User == "" {
+		err = structures.NewError(stores.ErrSession, "debe iniciar sesion para leer archivos")
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if !strings.EqualFold(session.IdPartition, partitionId) {
+		writeError(w, structures.NewError(structures.ErrPermission, "La sesion pertenece a la particion "+session.IdPartition))
+		return
+	}
+
+	defer stores.LockPartition(partitionId, false)()
+
 	// Obtener contenido del archivo
 	superBlock, _, diskPath, err := stores.GetMountedPartitionSuperblock(partitionId)
 	if err != nil {
 		console.PrintError(fmt.Sprintf("Error al obtener partición: %v", err))
-		http.Error(w, "Error al obtener partición: "+err.Error(), http.StatusInternalServerError)
+		writeError(w, fmt.Errorf("Error al obtener partición: %w", err))
 		return
 	}
 
 	// Verificar que la partición tenga un sistema de archivos formateado
 	if superBlock.S_magic != 0xEF53 {
 		console.PrintWarning("Partición no formateada")
-		http.Error(w, "La partición no está formateada", http.StatusBadRequest)
+		writeError(w, structures.NewError(structures.ErrInvalid, "La partición no está formateada"))
 		return
 	}
 
 	// Obtener contenido del archivo
-	parentDirs, fileName := utils.GetParentDirectories(filePath)
-	content, err := superBlock.ContentFromFile(diskPath, 0, parentDirs, fileName)
+	content, err := superBlock.ReadFileForUser(diskPath, filePath, session.UserID, session.GroupID)
 	if err != nil {
 		console.PrintError(fmt.Sprintf("Error al leer archivo: %v", err))
-		http.Error(w, "Error al leer archivo: "+err.Error(), http.StatusNotFound)
+		writeError(w, fmt.Errorf("Error al leer archivo: %w", err))
 		return
 	}
 
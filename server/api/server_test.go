@@ -0,0 +1,317 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"server/stores"
+	"server/utils"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestState deja los discos en un directorio temporal y reinicia los contadores de letras, que son globales entre pruebas
+func newTestState(t *testing.T) {
+	stores.SetPathDisk(t.TempDir())
+	stores.ClearAllDisks()
+	utils.SetLetterCounters(0, 0)
+}
+
+func postCommand(token, command string) (CommandResponse, error) {
+	body := strings.NewReader(fmt.Sprintf(`{"command": %q}`, command))
+	req := httptest.NewRequest(http.MethodPost, "/api/command", body)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	handleCommand(rec, req)
+
+	var response CommandResponse
+	err := json.NewDecoder(rec.Body).Decode(&response)
+	if err != nil {
+		return response, err
+	}
+	if !response.Success {
+		return response, fmt.Errorf("%s: %s", command, response.Error)
+	}
+	return response, nil
+}
+
+func getFileSystem(token, partitionId, path string) (map[string]interface{}, error) {
+	req := httptest.NewRequest(http.MethodGet, "/api/filesystem?partition="+partitionId+"&path="+path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	handleGetFileSystem(rec, req)
+
+	var response map[string]interface{}
+	err := json.NewDecoder(rec.Body).Decode(&response)
+	if err != nil {
+		return nil, err
+	}
+	if response["success"] != true {
+		return nil, fmt.Errorf("filesystem %s: %v", path, response["error"])
+	}
+	return response, nil
+}
+
+// Ejecutar con go test -race: mkfile escribe bitmaps, inodos y bloques mientras /api/filesystem recorre la misma particion
+func TestConcurrentMkfileAndFileSystem(t *testing.T) {
+	newTestState(t)
+
+	for _, command := range []string{
+		"mkdisk -size=2 -unit=M",
+		"fdisk -size=1 -unit=M -driveletter=A -name=P1",
+		"mount -driveletter=A -name=P1",
+		"mkfs -id=A105 -fs=3fs",
+	} {
+		if _, err := postCommand("", command); err != nil {
+			t.Fatal(err)
+		}
+	}
+	login, err := postCommand("", "login -user=root -pass=123 -id=A105")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := postCommand(login.Token, "mkdir -path=/docs"); err != nil {
+		t.Fatal(err)
+	}
+
+	const writers, readers, files = 4, 4, 10
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < files; i++ {
+				_, err := postCommand(login.Token, fmt.Sprintf("mkfile -path=/docs/w%d_%d.txt -size=150", w, i))
+				if err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(w)
+	}
+	for r := 0; r < readers; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < files; i++ {
+				if _, err := getFileSystem(login.Token, "A105", "/docs"); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	response, err := getFileSystem(login.Token, "A105", "/docs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := response["data"].(map[string]interface{})
+	if got := len(data["files"].([]interface{})); got != writers*files {
+		t.Fatalf("se esperaban %d archivos en /docs y hay %d", writers*files, got)
+	}
+}
+
+func TestCommandErrorStatus(t *testing.T) {
+	newTestState(t)
+
+	for _, c := range []struct {
+		token, command string
+		status         int
+		code           string
+	}{
+		{"", "comando_raro", http.StatusBadRequest, "invalid_argument"},
+		{"", `mkdir -path="/sin cerrar`, http.StatusBadRequest, "invalid_argument"},
+		{"", "mkgrp -name=docs", http.StatusForbidden, "permission_denied"},
+		{"", "unmount -id=Z999", http.StatusNotFound, "not_mounted"},
+		{"token-invalido", "mounted", http.StatusUnauthorized, "unauthorized"},
+	} {
+		body := strings.NewReader(fmt.Sprintf(`{"command": %q}`, c.command))
+		req := httptest.NewRequest(http.MethodPost, "/api/command", body)
+		if c.token != "" {
+			req.Header.Set("Authorization", "Bearer "+c.token)
+		}
+		rec := httptest.NewRecorder()
+		handleCommand(rec, req)
+
+		var response CommandResponse
+		if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		if rec.Code != c.status || response.Code != c.code {
+			t.Errorf("%s: se esperaba %d %s y llego %d %s (%s)", c.command, c.status, c.code, rec.Code, response.Code, response.Error)
+		}
+	}
+}
+
+// /api/filesystem recorre con los permisos de la sesion, sin sesion o con una de otra particion se rechaza
+func TestFileSystemRequiresSession(t *testing.T) {
+	newTestState(t)
+
+	for _, command := range []string{
+		"mkdisk -size=2 -unit=M",
+		"fdisk -size=500 -unit=K -driveletter=A -name=P1",
+		"fdisk -size=500 -unit=K -driveletter=A -name=P2",
+		"mount -driveletter=A -name=P1",
+		"mount -driveletter=A -name=P2",
+		"mkfs -id=A105 -fs=2fs",
+		"mkfs -id=A205 -fs=2fs",
+	} {
+		if _, err := postCommand("", command); err != nil {
+			t.Fatal(err)
+		}
+	}
+	login, err := postCommand("", "login -user=root -pass=123 -id=A205")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		token  string
+		status int
+		code   string
+	}{
+		{"", http.StatusUnauthorized, "unauthorized"},
+		{login.Token, http.StatusForbidden, "permission_denied"},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/api/filesystem?partition=A105&path=/", nil)
+		if c.token != "" {
+			req.Header.Set("Authorization", "Bearer "+c.token)
+		}
+		rec := httptest.NewRecorder()
+		handleGetFileSystem(rec, req)
+
+		var response CommandResponse
+		if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		if rec.Code != c.status || response.Code != c.code {
+			t.Errorf("token %q: se esperaba %d %s y llego %d %s (%s)", c.token, c.status, c.code, rec.Code, response.Code, response.Error)
+		}
+	}
+
+	if _, err := getFileSystem(login.Token, "A205", "/"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestInvalidJSON(t *testing.T) {
+	for _, c := range []struct {
+		path    string
+		handler http.HandlerFunc
+	}{
+		{"/api/command", handleCommand},
+		{"/api/batch", handleBatchCommands},
+	} {
+		req := httptest.NewRequest(http.MethodPost, c.path, strings.NewReader(`{"commands": `))
+		rec := httptest.NewRecorder()
+		c.handler(rec, req)
+
+		var response CommandResponse
+		if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+			t.Fatal(err)
+		}
+		if rec.Code != http.StatusBadRequest || response.Code != "invalid_argument" {
+			t.Errorf("%s: se esperaba 400 invalid_argument y llego %d %s", c.path, rec.Code, response.Code)
+		}
+	}
+}
+
+// Con dos particiones cada comando toma solo el candado de la suya: mientras un comando espera por A105, los de B105 siguen
+func TestConcurrentPartitions(t *testing.T) {
+	newTestState(t)
+
+	tokens := map[string]string{}
+	for _, letter := range []string{"A", "B"} {
+		id := letter + "105"
+		for _, command := range []string{
+			"mkdisk -size=2 -unit=M",
+			"fdisk -size=1 -unit=M -driveletter=" + letter + " -name=P1",
+			"mount -driveletter=" + letter + " -name=P1",
+			"mkfs -id=" + id + " -fs=2fs",
+		} {
+			if _, err := postCommand("", command); err != nil {
+				t.Fatal(err)
+			}
+		}
+		login, err := postCommand("", "login -user=root -pass=123 -id="+id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tokens[id] = login.Token
+	}
+
+	unlock := stores.LockPartition("A105", true)
+	blocked := make(chan error)
+	go func() {
+		_, err := postCommand(tokens["A105"], "mkdir -path=/esperando")
+		blocked <- err
+	}()
+	// Se le da tiempo al comando de A105 para que llegue al candado
+	time.Sleep(200 * time.Millisecond)
+	done := make(chan error)
+	go func() {
+		_, err := postCommand(tokens["B105"], "mkdir -path=/libre")
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("el comando en B105 espero al de A105")
+	}
+	unlock()
+	if err := <-blocked; err != nil {
+		t.Fatal(err)
+	}
+
+	const files = 10
+	var wg sync.WaitGroup
+	for id, token := range tokens {
+		wg.Add(1)
+		go func(id, token string) {
+			defer wg.Done()
+			for i := 0; i < files; i++ {
+				if _, err := postCommand(token, fmt.Sprintf("mkfile -path=/%s_%d.txt -size=100", id, i)); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(id, token)
+	}
+	wg.Wait()
+
+	// Cada archivo quedo en la particion de su sesion
+	for id, token := range tokens {
+		response, err := postCommand(token, "find -path=/ -name=*")
+		if err != nil {
+			t.Fatal(err)
+		}
+		found := fmt.Sprint(response.Data)
+		for i := 0; i < files; i++ {
+			if !strings.Contains(found, fmt.Sprintf("/%s_%d.txt", id, i)) {
+				t.Errorf("falta /%s_%d.txt en %s", id, i, id)
+			}
+		}
+		for other := range tokens {
+			if other != id && strings.Contains(found, other+"_") {
+				t.Errorf("%s tiene archivos de %s", id, other)
+			}
+		}
+	}
+	for id, folder := range map[string]string{"A105": "/esperando", "B105": "/libre"} {
+		if _, err := postCommand(tokens[id], "cd -path="+folder); err != nil {
+			t.Error(err)
+		}
+	}
+}
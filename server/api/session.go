@@ -0,0 +1,58 @@
+package api
+
+import (
+	"net/http"
+	"server/analyzer"
+	"server/commands"
+	"server/stores"
+	"strings"
+)
+
+// sessionToken lee el token del header Authorization: Bearer <token>
+func sessionToken(r *http.Request) string {
+	auth := strings.TrimSpace(r.Header.Get("Authorization"))
+	if len(auth) > 7 && strings.EqualFold(auth[:7], "Bearer ") {
+		return strings.TrimSpace(auth[7:])
+	}
+	return ""
+}
+
+// requestSession devuelve la sesion del request, sin token es una sesion vacia
+func requestSession(r *http.Request) (stores.Session, error) {
+	token := sessionToken(r)
+	if token == "" {
+		return stores.Session{}, nil
+	}
+	return stores.GetSession(token)
+}
+
+// executeWithSession ejecuta el comando con una copia de la sesion del token y devuelve el token con el que sigue el cliente:
+// uno nuevo si el comando hizo login, vacio si hizo logout. Los comandos no usan la sesion de la consola,
+// los de clientes distintos solo se esperan en los candados del disco o la particion que tocan
+func executeWithSession(token, command string) (commands.Result, string, error) {
+	session := stores.Session{}
+	if token != "" {
+		var err error
+		session, err = stores.GetSession(token)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	previous := session
+
+	result, err := analyzer.AnalyzerAs(&session, command)
+	switch {
+	case err == nil && previous.User == "" && session.User != "":
+		newToken, err := stores.NewSession(session)
+		if err != nil {
+			return nil, "", err
+		}
+		return result, newToken, nil
+	case previous.User != "" && session.User == "":
+		stores.RevokeSession(token)
+		return result, "", err
+	case token != "":
+		stores.UpdateSession(token, session)
+	}
+	return result, token, err
+}
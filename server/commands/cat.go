@@ -1,86 +1,76 @@
 package commands
 
 import (
-	"errors"
-	"fmt"
-	"regexp"
+	"server/lexer"
 	stores "server/stores"
-	utils "server/utils"
-	"strconv"
-	"strings"
 )
 
 type CAT struct {
-	files map[int]string
+	files []string //en el orden de -file1, -file2...
 }
 
-func ParseCat(tokens []string) (string, error) {
-	cmd := &CAT{}
-	cmd.files = make(map[int]string)
+type CatFile struct {
+	Path    string `json:"path"`
+	Content string `json:"content"`
+}
 
-	args := strings.Join(tokens, " ")
-	re := regexp.MustCompile(`-file\d+="[^"]+"|-file\d+=[^\s]+`)
-	matches := re.FindAllString(args, -1)
+type CatResult struct {
+	Files []CatFile `json:"files"`
+}
 
-	for _, match := range matches {
-		kv := strings.SplitN(match, "=", 2)
-		if len(kv) != 2 {
-			return "", fmt.Errorf("formato de parametro invalido: %s", match)
-		}
-		key, value := strings.ToLower(kv[0]), kv[1]
+func (result CatResult) String() string {
+	var content string
+	for _, file := range result.Files {
+		content += file.Content + "\n"
+	}
+	return content
+}
 
-		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
-			value = strings.Trim(value, "\"")
-		}
-		commandKey := regexp.MustCompile("[a-zA-Z-]+")
-		numberKey := regexp.MustCompile("[0-9]+")
+var catParams = lexer.Schema{
+	{Name: "file", Required: true, Numbered: true, Path: true, Description: "Ruta del archivo, -file1, -file2..."},
+}
 
-		letters := commandKey.FindString(key)
-		number := numberKey.FindString(key)
+func init() {
+	Register(Command{
+		Name:        "cat",
+		Description: "Muestra el contenido de uno o mas archivos",
+		Params:      catParams,
+		Run:         ParseCat,
+	})
+}
 
-		switch letters {
-		case "-file":
-			numberFile, err := strconv.Atoi(number)
-			if err != nil || numberFile <= 0 {
-				return "", errors.New("el numero de fileN debe ser mayor a 0")
-			}
-			if value == "" {
-				return "", errors.New("el fileN no puede estar vacio")
-			}
-			cmd.files[numberFile] = value
-		}
+func ParseCat(session *stores.Session, tokens []string) (Result, error) {
+	args, err := catParams.Parse(tokens)
+	if err != nil {
+		return nil, err
 	}
-
-	if len(cmd.files) == 0 {
-		return "", errors.New("falta al menos un parametro requerido: -fileN")
+	cmd := &CAT{}
+	for _, file := range args.Numbered("file") {
+		cmd.files = append(cmd.files, resolvePath(session, file))
 	}
 
 	// Logica de Cat
-	content, err := commandCat(cmd)
+	result, err := commandCat(session, cmd)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	fmt.Println(content)
-
-	return content, nil
+	return result, nil
 
 }
 
-func commandCat(cat *CAT) (string, error) {
+func commandCat(session *stores.Session, cat *CAT) (*CatResult, error) {
 	// Tomar en cuenta que el idPartition correspondara al id actual en el q este el usuario
-	var result string
-	partitionSuperblock, _, partitionPath, err := stores.GetMountedPartitionSuperblock(stores.LogedIdPartition)
+	result := &CatResult{Files: []CatFile{}}
+	partitionSuperblock, _, partitionPath, err := stores.GetMountedPartitionSuperblock(session.IdPartition)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	for _, pathToGetInfo := range cat.files {
-		parentDirs, destDir := utils.GetParentDirectories(pathToGetInfo)
-
-		content, err := partitionSuperblock.ContentFromFileCat(partitionPath, 0, parentDirs, destDir)
+		content, err := partitionSuperblock.ReadFileForUser(partitionPath, pathToGetInfo, session.UserID, session.GroupID)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
-		result += content + "\n"
+		result.Files = append(result.Files, CatFile{Path: pathToGetInfo, Content: content})
 	}
 	return result, nil
 }
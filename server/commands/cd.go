@@ -0,0 +1,105 @@
+package commands
+
+import (
+	"fmt"
+	"server/lexer"
+	"server/stores"
+	"server/structures"
+	utils "server/utils"
+)
+
+type CD struct {
+	path string
+}
+
+type CdResult struct {
+	Path string `json:"path"`
+}
+
+func (result CdResult) String() string {
+	return fmt.Sprintf("CD: %s", result.Path)
+}
+
+type PwdResult struct {
+	Path string `json:"path"`
+}
+
+func (result PwdResult) String() string {
+	return result.Path
+}
+
+var cdParams = lexer.Schema{
+	{Name: "path", Default: "/", Path: true, Description: "Carpeta destino"}, //sin -path se vuelve a la raiz
+}
+
+func init() {
+	Register(Command{
+		Name:        "cd",
+		Description: "Cambia el directorio de trabajo de la sesion",
+		Params:      cdParams,
+		Run:         ParseCd,
+	})
+}
+
+func ParseCd(session *stores.Session, tokens []string) (Result, error) {
+	args, err := cdParams.Parse(tokens)
+	if err != nil {
+		return nil, err
+	}
+	cmd := &CD{path: resolvePath(session, args.String("path"))}
+
+	err = commandCd(session, cmd)
+	if err != nil {
+		return nil, err
+	}
+	return CdResult{Path: session.WorkingDir}, nil
+}
+
+func commandCd(session *stores.Session, cd *CD) error {
+	if session.IdPartition == "" {
+		return structures.NewError(structures.ErrPermission, "no hay sesion activa")
+	}
+	sb, _, diskPath, err := stores.GetMountedPartitionSuperblock(session.IdPartition)
+	if err != nil {
+		return err
+	}
+	_, inode, err := sb.Lookup(diskPath, cd.path, session.UserID, session.GroupID)
+	if err != nil {
+		return err
+	}
+	if inode.I_type[0] != '0' {
+		return structures.Errorf(structures.ErrInvalid, "%s no es un directorio", cd.path)
+	}
+	session.WorkingDir = cd.path
+	return nil
+}
+
+var pwdParams = lexer.Schema{}
+
+func init() {
+	Register(Command{
+		Name:        "pwd",
+		Description: "Muestra el directorio de trabajo de la sesion",
+		Params:      pwdParams,
+		Run:         ParsePwd,
+	})
+}
+
+func ParsePwd(session *stores.Session, tokens []string) (Result, error) {
+	_, err := pwdParams.Parse(tokens)
+	if err != nil {
+		return nil, err
+	}
+	if session.IdPartition == "" {
+		return nil, structures.NewError(structures.ErrPermission, "no hay sesion activa")
+	}
+	return PwdResult{Path: session.WorkingDir}, nil
+}
+
+// resolvePath convierte la ruta de un parametro en absoluta desde el directorio de trabajo de la sesion
+func resolvePath(session *stores.Session, path string) string {
+	if path == "" {
+		return ""
+	}
+	return utils.ResolvePath(session.WorkingDir, path)
+}
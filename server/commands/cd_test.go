@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"errors"
+	"server/stores"
+	"server/structures"
+	"testing"
+)
+
+// Las rutas relativas de cd, mkdir y mkfile se resuelven desde el directorio de trabajo de la sesion
+func TestCdRelativePaths(t *testing.T) {
+	newTestState(t)
+	session := &stores.Session{}
+	run(t, session,
+		"mkdisk -size=1 -unit=M",
+		"fdisk -size=500 -unit=K -driveletter=A -name=P1",
+		"mount -driveletter=A -name=P1",
+		"mkfs -id=A105 -fs=2fs",
+	)
+	if _, err := tryRun(session, "cd -path=/"); !errors.Is(err, structures.ErrPermission) {
+		t.Fatalf("cd sin sesion deberia fallar con ErrPermission y llego %v", err)
+	}
+	run(t, session,
+		"login -user=root -pass=123 -id=A105",
+		"mkdir -r -path=/home/ana",
+		"mkfile -path=/home/nota.txt -size=5",
+	)
+
+	for _, c := range []struct {
+		command    string
+		workingDir string
+		kind       error // si falla el directorio no cambia
+	}{
+		{"cd -path=home", "/home", nil},
+		{"cd -path=ana", "/home/ana", nil},
+		{"cd -path=./../ana/.", "/home/ana", nil},
+		{"cd -path=..", "/home", nil},
+		{"cd -path=nota.txt", "/home", structures.ErrInvalid},
+		{"cd -path=no/existe", "/home", structures.ErrNotFound},
+		{"cd -path=../../..", "/", nil},
+		{"cd -path=/home/ana", "/home/ana", nil},
+		{"cd", "/", nil},
+	} {
+		_, err := tryRun(session, c.command)
+		if c.kind == nil && err != nil {
+			t.Fatalf("%s: %v", c.command, err)
+		}
+		if c.kind != nil && !errors.Is(err, c.kind) {
+			t.Fatalf("%s: se esperaba %v y llego %v", c.command, c.kind, err)
+		}
+		result, err := tryRun(session, "pwd")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.String() != c.workingDir {
+			t.Fatalf("%s: pwd devolvio %s y se esperaba %s", c.command, result, c.workingDir)
+		}
+	}
+
+	run(t, session,
+		"cd -path=/home/ana",
+		"mkdir -r -path=docs/viejos",
+		"mkfile -path=docs/a.txt -size=12",
+		"mkfile -path=../b.txt -size=3",
+		"cd -path=docs/viejos",
+		"mkfile -path=./c.txt -size=7",
+	)
+	for path, content := range map[string]string{
+		"/home/ana/docs/a.txt":        "012345678901",
+		"/home/b.txt":                 "012",
+		"/home/ana/docs/viejos/c.txt": "0123456",
+	} {
+		if got := readFile(t, "A105", path); got != content {
+			t.Errorf("%s tiene %q y se esperaba %q", path, got, content)
+		}
+	}
+	checkFsck(t, "A105")
+
+	// Un login nuevo empieza en la raiz
+	run(t, session, "logout", "login -user=root -pass=123 -id=A105")
+	if result, _ := tryRun(session, "pwd"); result == nil || result.String() != "/" {
+		t.Errorf("despues de login pwd devolvio %v", result)
+	}
+}
@@ -0,0 +1,129 @@
+package commands
+
+import (
+	"encoding/binary"
+	"fmt"
+	"server/lexer"
+	stores "server/stores"
+	"server/structures"
+	"time"
+)
+
+type CHGRP struct {
+	user  string
+	group string
+}
+
+type ChgrpResult struct {
+	User  string `json:"user"`
+	Group string `json:"group"`
+}
+
+func (result ChgrpResult) String() string {
+	return fmt.Sprintf("CHGRP: usuario %s movido al grupo %s exitosamente", result.User, result.Group)
+}
+
+var chgrpParams = lexer.Schema{
+	{Name: "user", Required: true, Description: "Nombre del usuario"},
+	{Name: "grp", Required: true, Description: "Grupo nuevo"},
+}
+
+func init() {
+	Register(Command{
+		Name:        "chgrp",
+		Description: "Cambia el grupo de un usuario, solo root",
+		Params:      chgrpParams,
+		Run:         ParseChgrp,
+	})
+}
+
+func ParseChgrp(session *stores.Session, tokens []string) (Result, error) {
+	args, err := chgrpParams.Parse(tokens)
+	if err != nil {
+		return nil, err
+	}
+	cmd := &CHGRP{user: args.String("user"), group: args.String("grp")}
+	if len(cmd.user) > 10 {
+		return nil, structures.NewError(structures.ErrInvalid, "el user de usuario no se puede exceder de 10 caracteres")
+	}
+	if len(cmd.group) > 10 {
+		return nil, structures.NewError(structures.ErrInvalid, "el group de usuario no se puede exceder de 10 caracteres")
+	}
+
+	err = commandChgrp(session, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return ChgrpResult{User: cmd.user, Group: cmd.group}, nil
+}
+
+func commandChgrp(session *stores.Session, chgrp *CHGRP) error {
+	if session.IdPartition == "" {
+		return structures.NewError(structures.ErrPermission, "no hay sesion activa")
+	}
+	if !session.IsRoot() {
+		return structures.NewError(structures.ErrPermission, "este comando solo lo puede ejecutar el usuario root")
+	}
+	contentUsersTxt, err := getContetnUsersTxt(session.IdPartition)
+	if err != nil {
+		return err
+	}
+	contentMatrix := getContentMatrixUsers(contentUsersTxt)
+	if !activeGroupExists(chgrp.group, contentMatrix) {
+		return structures.NewError(structures.ErrNotFound, "el grupo especificado no existe")
+	}
+	row := findActiveUser(chgrp.user, contentMatrix)
+	if row == nil {
+		return structures.NewError(structures.ErrNotFound, "el nombre de usuario no existe")
+	}
+	row[2] = chgrp.group
+
+	partitionSuperblock, mountedPartition, partitionPath, err := stores.GetMountedPartitionSuperblock(session.IdPartition)
+	if err != nil {
+		return err
+	}
+	err = checkJournalSpace(partitionSuperblock, partitionPath, mountedPartition, 1)
+	if err != nil {
+		return err
+	}
+	err = OverrideUserstxt(partitionSuperblock, partitionPath, reformUserstxt(contentMatrix))
+	if err != nil {
+		return err
+	}
+	if partitionSuperblock.IsExt3() {
+		journalDirectory := &structures.Journal{
+			J_next: -1,
+			J_content: structures.Information{
+				I_operation: [10]byte{'c', 'h', 'g', 'r', 'p'},
+				I_path:      [74]byte{},
+				I_content:   [64]byte{},
+				I_date:      float32(time.Now().Unix()),
+			},
+		}
+		fullContent := fmt.Sprintf("%s/%s", chgrp.user, chgrp.group)
+		copy(journalDirectory.J_content.I_content[:], fullContent)
+		err = partitionSuperblock.AddJournal(journalDirectory, partitionPath, int32(mountedPartition.Part_start+int32(binary.Size(structures.SuperBlock{}))))
+		if err != nil {
+			return err
+		}
+	}
+	err = partitionSuperblock.Serialize(partitionPath, int64(mountedPartition.Part_start))
+	if err != nil {
+		return err
+	}
+	// Si root se movio a si mismo la sesion toma el grupo nuevo
+	if chgrp.user == session.User {
+		return setUpIDs(session, chgrp.user, contentMatrix)
+	}
+	return nil
+}
+
+func activeGroupExists(nameGroup string, matrix [][]string) bool {
+	for _, row := range matrix {
+		if row[0] != "0" && row[1] == "G" && row[2] == nameGroup {
+			return true
+		}
+	}
+	return false
+}
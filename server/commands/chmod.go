@@ -0,0 +1,119 @@
+package commands
+
+import (
+	"encoding/binary"
+	"fmt"
+	"regexp"
+	"server/lexer"
+	"server/stores"
+	"server/structures"
+	"time"
+)
+
+type CHMOD struct {
+	path string
+	ugo  string
+	r    bool
+}
+
+type ChmodResult struct {
+	Path        string `json:"path"`
+	Permissions string `json:"permissions"`
+	Recursive   bool   `json:"recursive"`
+}
+
+func (result ChmodResult) String() string {
+	return fmt.Sprintf("CHMOD: permisos de %s cambiados a %s", result.Path, result.Permissions)
+}
+
+var chmodParams = lexer.Schema{
+	{Name: "path", Required: true, Path: true, Description: "Ruta del archivo o carpeta"},
+	{Name: "ugo", Required: true, Description: "Permisos de usuario, grupo y otros, como 764"},
+	{Name: "r", Kind: lexer.Flag, Description: "Aplica tambien al contenido de la carpeta"},
+}
+
+func init() {
+	Register(Command{
+		Name:        "chmod",
+		Description: "Cambia los permisos de un archivo o carpeta",
+		Params:      chmodParams,
+		Run:         ParseChmod,
+	})
+}
+
+func ParseChmod(session *stores.Session, tokens []string) (Result, error) {
+	args, err := chmodParams.Parse(tokens)
+	if err != nil {
+		return nil, err
+	}
+	cmd := &CHMOD{path: resolvePath(session, args.String("path")), ugo: args.String("ugo"), r: args.Flag("r")}
+	if !regexp.MustCompile(`^[0-7]{3}$`).MatchString(cmd.ugo) {
+		return nil, structures.NewError(structures.ErrInvalid, "el ugo debe estar formado por 3 digitos entre 0 y 7")
+	}
+
+	err = commandChmod(session, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return ChmodResult{Path: cmd.path, Permissions: cmd.ugo, Recursive: cmd.r}, nil
+}
+
+func commandChmod(session *stores.Session, chmod *CHMOD) error {
+	if session.IdPartition == "" {
+		return structures.NewError(structures.ErrPermission, "no hay sesion activa")
+	}
+	sb, partition, diskPath, err := stores.GetMountedPartitionSuperblock(session.IdPartition)
+	if err != nil {
+		return err
+	}
+	err = checkJournalSpace(sb, diskPath, partition, 1)
+	if err != nil {
+		return err
+	}
+
+	err = chmodPath(session, sb, diskPath, chmod.path, chmod.ugo, chmod.r)
+	if err != nil {
+		return err
+	}
+
+	if sb.IsExt3() {
+		journalDirectory := &structures.Journal{
+			J_next: -1,
+			J_content: structures.Information{
+				I_operation: [10]byte{'c', 'h', 'm', 'o', 'd'},
+				I_path:      [74]byte{},
+				I_content:   [64]byte{},
+				I_date:      float32(time.Now().Unix()),
+			},
+		}
+		copy(journalDirectory.J_content.I_path[:], chmod.path)
+		copy(journalDirectory.J_content.I_content[:], fmt.Sprintf("%s/%t", chmod.ugo, chmod.r))
+		err = sb.AddJournal(journalDirectory, diskPath, int32(partition.Part_start+int32(binary.Size(structures.SuperBlock{}))))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func chmodPath(session *stores.Session, sb *structures.SuperBlock, diskPath, path, ugo string, recursive bool) error {
+	indexInode, inode, err := sb.Lookup(diskPath, path, session.UserID, session.GroupID)
+	if err != nil {
+		return err
+	}
+	outcome, err := inode.HasPermissionsChmod(session.UserID, session.GroupID)
+	if err != nil {
+		return err
+	}
+	if !outcome {
+		return structures.NewError(structures.ErrPermission, "solo el root o el propietario pueden cambiar los permisos")
+	}
+
+	if recursive {
+		// Los hijos que no pertenezcan al usuario se omiten dentro de ChmodRecursive
+		return sb.ChmodRecursive(diskPath, indexInode, ugo, session.UserID, session.GroupID)
+	}
+	copy(inode.I_perm[:], []byte(ugo))
+	return inode.Serialize(diskPath, int64(sb.S_inode_start+indexInode*sb.S_inode_size))
+}
@@ -0,0 +1,140 @@
+package commands
+
+import (
+	"encoding/binary"
+	"fmt"
+	"server/lexer"
+	"server/stores"
+	"server/structures"
+	"strconv"
+	"time"
+)
+
+type CHOWN struct {
+	path    string
+	usuario string
+	r       bool
+}
+
+type ChownResult struct {
+	Path      string `json:"path"`
+	Owner     string `json:"owner"`
+	Recursive bool   `json:"recursive"`
+}
+
+func (result ChownResult) String() string {
+	return fmt.Sprintf("CHOWN: %s ahora pertenece a %s", result.Path, result.Owner)
+}
+
+var chownParams = lexer.Schema{
+	{Name: "path", Required: true, Path: true, Description: "Ruta del archivo o carpeta"},
+	{Name: "usuario", Required: true, Description: "Usuario nuevo"},
+	{Name: "r", Kind: lexer.Flag, Description: "Aplica tambien al contenido de la carpeta"},
+}
+
+func init() {
+	Register(Command{
+		Name:        "chown",
+		Description: "Cambia el propietario de un archivo o carpeta",
+		Params:      chownParams,
+		Run:         ParseChown,
+	})
+}
+
+func ParseChown(session *stores.Session, tokens []string) (Result, error) {
+	args, err := chownParams.Parse(tokens)
+	if err != nil {
+		return nil, err
+	}
+	cmd := &CHOWN{path: resolvePath(session, args.String("path")), usuario: args.String("usuario"), r: args.Flag("r")}
+
+	err = commandChown(session, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return ChownResult{Path: cmd.path, Owner: cmd.usuario, Recursive: cmd.r}, nil
+}
+
+func commandChown(session *stores.Session, chown *CHOWN) error {
+	if session.IdPartition == "" {
+		return structures.NewError(structures.ErrPermission, "no hay sesion activa")
+	}
+	sb, partition, diskPath, err := stores.GetMountedPartitionSuperblock(session.IdPartition)
+	if err != nil {
+		return err
+	}
+	err = checkJournalSpace(sb, diskPath, partition, 1)
+	if err != nil {
+		return err
+	}
+
+	contentUsersTxt, err := getContetnUsersTxt(session.IdPartition)
+	if err != nil {
+		return err
+	}
+	neoOwnerID, err := getUserID(chown.usuario, getContentMatrixUsers(contentUsersTxt))
+	if err != nil {
+		return err
+	}
+
+	err = chownPath(session, sb, diskPath, chown.path, neoOwnerID, chown.r)
+	if err != nil {
+		return err
+	}
+
+	if sb.IsExt3() {
+		journalDirectory := &structures.Journal{
+			J_next: -1,
+			J_content: structures.Information{
+				I_operation: [10]byte{'c', 'h', 'o', 'w', 'n'},
+				I_path:      [74]byte{},
+				I_content:   [64]byte{},
+				I_date:      float32(time.Now().Unix()),
+			},
+		}
+		copy(journalDirectory.J_content.I_path[:], chown.path)
+		copy(journalDirectory.J_content.I_content[:], fmt.Sprintf("%s/%t", chown.usuario, chown.r))
+		err = sb.AddJournal(journalDirectory, diskPath, int32(partition.Part_start+int32(binary.Size(structures.SuperBlock{}))))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func chownPath(session *stores.Session, sb *structures.SuperBlock, diskPath, path string, neoOwnerID int32, recursive bool) error {
+	indexInode, inode, err := sb.Lookup(diskPath, path, session.UserID, session.GroupID)
+	if err != nil {
+		return err
+	}
+	outcome, err := inode.HasPermissionsChmod(session.UserID, session.GroupID)
+	if err != nil {
+		return err
+	}
+	if !outcome {
+		return structures.NewError(structures.ErrPermission, "solo el root o el propietario pueden cambiar el propietario")
+	}
+
+	if recursive {
+		return sb.ChownRecursive(diskPath, indexInode, session.UserID, session.GroupID, neoOwnerID, true)
+	}
+	inode.I_uid = neoOwnerID
+	return inode.Serialize(diskPath, int64(sb.S_inode_start+indexInode*sb.S_inode_size))
+}
+
+func getUserID(userName string, matrix [][]string) (int32, error) {
+	for _, row := range matrix {
+		if row[1] != "U" || row[0] == "0" {
+			continue
+		}
+		if row[3] == userName {
+			num, err := strconv.Atoi(row[0])
+			if err != nil {
+				return 0, err
+			}
+			return int32(num), nil
+		}
+	}
+	return 0, structures.Errorf(structures.ErrNotFound, "el usuario %s no existe", userName)
+}
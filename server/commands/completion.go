@@ -0,0 +1,136 @@
+package commands
+
+import (
+	"server/lexer"
+	"server/stores"
+	"server/structures"
+	"server/utils"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Complete es el autocompletado de la consola: recibe la linea hasta el cursor y devuelve donde empieza
+// la palabra a completar y las opciones, que la reemplazan completa.
+// Completa nombres de comandos, parametros, valores permitidos, ids montados y rutas de la particion de la sesion.
+func Complete(line string) (int, []string) {
+	start := strings.LastIndexAny(line, " \t") + 1
+	word := line[start:]
+	fields := strings.Fields(line[:start])
+	if len(fields) == 0 {
+		return start, completeCommandNames(word)
+	}
+	command, found := Lookup(fields[0])
+	if !found {
+		return start, nil
+	}
+	if command.Name == "help" {
+		if len(fields) == 1 {
+			return start, completeCommandNames(word)
+		}
+		return start, nil
+	}
+	if !strings.HasPrefix(word, "-") {
+		return start, nil
+	}
+
+	name, value, hasValue := strings.Cut(word[1:], "=")
+	if !hasValue {
+		return start, completeParamNames(command.Params, fields[1:], name)
+	}
+	param, found := command.Params.Lookup(name)
+	if !found {
+		return start, nil
+	}
+	valueStart := start + len(word) - len(value)
+	switch {
+	case len(param.Values) > 0:
+		return valueStart, withPrefix(param.Values, value)
+	case param.Name == "id":
+		return valueStart, withPrefix(mountedIDs(), value)
+	case param.Path:
+		return valueStart, completePath(value)
+	}
+	return start, nil
+}
+
+func completeCommandNames(prefix string) []string {
+	var names []string
+	for _, command := range Registered() {
+		names = append(names, command.Name)
+	}
+	return withPrefix(names, prefix)
+}
+
+// completeParamNames ofrece los parametros que faltan en la linea, los numerados con el siguiente numero
+func completeParamNames(schema lexer.Schema, tokens []string, prefix string) []string {
+	used := make(map[string]int)
+	for _, token := range tokens {
+		key, _, _ := strings.Cut(token, "=")
+		if param, found := schema.Lookup(strings.TrimPrefix(key, "-")); found {
+			used[param.Name]++
+		}
+	}
+
+	var options []string
+	for _, param := range schema {
+		switch {
+		case param.Numbered:
+			options = append(options, "-"+param.Name+strconv.Itoa(used[param.Name]+1)+"=")
+		case used[param.Name] > 0: //ya esta en la linea
+		case param.Kind == lexer.Flag:
+			options = append(options, "-"+param.Name)
+		default:
+			options = append(options, "-"+param.Name+"=")
+		}
+	}
+	return withPrefix(options, "-"+prefix)
+}
+
+// completePath lista las entradas de la carpeta escrita hasta la ultima /, con los permisos del usuario de la sesion.
+// Las carpetas terminan en / para seguir completando.
+func completePath(value string) []string {
+	session := stores.CurrentSession()
+	if session.IdPartition == "" {
+		return nil
+	}
+	defer stores.LockPartition(session.IdPartition, false)()
+	sb, _, diskPath, err := stores.GetMountedPartitionSuperblock(session.IdPartition)
+	if err != nil || sb.S_magic != 0xEF53 {
+		return nil
+	}
+
+	dir := value[:strings.LastIndex(value, "/")+1]
+	_, folder, err := sb.Lookup(diskPath, utils.ResolvePath(session.WorkingDir, dir), session.UserID, session.GroupID)
+	if err != nil || folder.I_type[0] != '0' {
+		return nil
+	}
+	entries, err := sb.FolderEntries(diskPath, folder)
+	if err != nil {
+		return nil
+	}
+
+	var options []string
+	for _, entry := range entries {
+		option := dir + strings.Trim(string(entry.B_name[:]), "\x00 ")
+		inode := &structures.Inode{}
+		err := inode.Deserialize(diskPath, int64(sb.S_inode_start+entry.B_inodo*sb.S_inode_size))
+		if err == nil && inode.I_type[0] == '0' {
+			option += "/"
+		}
+		options = append(options, option)
+	}
+	sort.Strings(options)
+	return withPrefix(options, value)
+}
+
+// withPrefix filtra las opciones que empiezan con prefix sin distinguir mayusculas
+func withPrefix(options []string, prefix string) []string {
+	var matches []string
+	for _, option := range options {
+		if len(option) >= len(prefix) && strings.EqualFold(option[:len(prefix)], prefix) {
+			matches = append(matches, option)
+		}
+	}
+	return matches
+}
@@ -0,0 +1,159 @@
+package commands
+
+import (
+	"encoding/binary"
+	"fmt"
+	"path/filepath"
+	"server/lexer"
+	"server/stores"
+	"server/structures"
+	"strings"
+	"time"
+)
+
+type COPY struct {
+	path    string
+	destino string
+}
+
+type CopyResult struct {
+	Path        string `json:"path"`
+	Destination string `json:"destination"`
+}
+
+func (result CopyResult) String() string {
+	return fmt.Sprintf("COPY: %s copiado en %s", result.Path, result.Destination)
+}
+
+var copyParams = lexer.Schema{
+	{Name: "path", Required: true, Path: true, Description: "Ruta del archivo o carpeta"},
+	{Name: "destino", Required: true, Path: true, Description: "Carpeta destino"},
+}
+
+func init() {
+	Register(Command{
+		Name:        "copy",
+		Description: "Copia un archivo o carpeta dentro de otra carpeta",
+		Params:      copyParams,
+		Run:         ParseCopy,
+	})
+}
+
+func ParseCopy(session *stores.Session, tokens []string) (Result, error) {
+	args, err := copyParams.Parse(tokens)
+	if err != nil {
+		return nil, err
+	}
+	cmd := &COPY{path: resolvePath(session, args.String("path")), destino: resolvePath(session, args.String("destino"))}
+
+	err = commandCopy(session, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return CopyResult{Path: cmd.path, Destination: cmd.destino}, nil
+}
+
+func commandCopy(session *stores.Session, cp *COPY) error {
+	if session.IdPartition == "" {
+		return structures.NewError(structures.ErrPermission, "no hay sesion activa")
+	}
+	sb, partition, diskPath, err := stores.GetMountedPartitionSuperblock(session.IdPartition)
+	if err != nil {
+		return err
+	}
+	err = checkJournalSpace(sb, diskPath, partition, 1)
+	if err != nil {
+		return err
+	}
+
+	err = copyPath(session, sb, diskPath, cp.path, cp.destino)
+	if err != nil {
+		return err
+	}
+
+	if sb.IsExt3() {
+		journalDirectory := &structures.Journal{
+			J_next: -1,
+			J_content: structures.Information{
+				I_operation: [10]byte{'c', 'o', 'p', 'y'},
+				I_path:      [74]byte{},
+				I_content:   [64]byte{},
+				I_date:      float32(time.Now().Unix()),
+			},
+		}
+		copy(journalDirectory.J_content.I_path[:], cp.path)
+		copy(journalDirectory.J_content.I_content[:], cp.destino)
+		err = sb.AddJournal(journalDirectory, diskPath, int32(partition.Part_start+int32(binary.Size(structures.SuperBlock{}))))
+		if err != nil {
+			return err
+		}
+	}
+
+	err = sb.Serialize(diskPath, int64(partition.Part_start))
+	if err != nil {
+		return fmt.Errorf("error al serializar el superbloque: %w", err)
+	}
+	return nil
+}
+
+func copyPath(session *stores.Session, sb *structures.SuperBlock, diskPath, path, destino string) error {
+	source := filepath.Clean(path)
+	target := filepath.Clean(destino)
+	if source == "/" {
+		return structures.NewError(structures.ErrInvalid, "no se puede copiar la raiz")
+	}
+	if target == source || strings.HasPrefix(target, source+"/") {
+		return structures.NewError(structures.ErrInvalid, "no se puede copiar un directorio dentro de si mismo")
+	}
+
+	indexInode, inode, err := sb.Lookup(diskPath, source, session.UserID, session.GroupID)
+	if err != nil {
+		return err
+	}
+	err = sb.ProtectUsersFile(diskPath, indexInode, "copiar")
+	if err != nil {
+		return err
+	}
+	outcome, err := inode.HasPermissionsToRead(session.UserID, session.GroupID)
+	if err != nil {
+		return err
+	}
+	if !outcome {
+		return structures.NewError(structures.ErrPermission, "inaccesible por falta de permisos de lectura")
+	}
+
+	destIndex, destInode, err := sb.Lookup(diskPath, target, session.UserID, session.GroupID)
+	if err != nil {
+		return err
+	}
+	outcome, err = destInode.HasPermissionsToWrite(session.UserID, session.GroupID)
+	if err != nil {
+		return err
+	}
+	if !outcome {
+		return structures.NewError(structures.ErrPermission, "no tiene permisos de escritura en el destino")
+	}
+
+	// Se valida el espacio antes de tocar el disco para no dejar la copia a medias
+	name := filepath.Base(source)
+	inodes, blocks, err := sb.CopyUsage(diskPath, indexInode, session.UserID, session.GroupID)
+	if err != nil {
+		return err
+	}
+	err = sb.CheckSpaceForEntry(diskPath, destIndex, name, inodes, blocks)
+	if err != nil {
+		return err
+	}
+
+	var copyIndex int32
+	if inode.I_type[0] == '0' {
+		copyIndex, err = sb.CopyInode0(diskPath, indexInode, destIndex, session.UserID, session.GroupID)
+	} else {
+		copyIndex, err = sb.CopyInode1(diskPath, indexInode, session.UserID, session.GroupID)
+	}
+	if err != nil {
+		return err
+	}
+	return sb.AddEntryToFolder(diskPath, destIndex, name, copyIndex)
+}
@@ -0,0 +1,118 @@
+package commands
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"server/lexer"
+	"server/stores"
+	"server/structures"
+	"time"
+)
+
+type EDIT struct {
+	path      string
+	contenido string
+}
+
+type EditResult struct {
+	Path string `json:"path"`
+}
+
+func (result EditResult) String() string {
+	return fmt.Sprintf("EDIT: %s editado exitosamente", result.Path)
+}
+
+var editParams = lexer.Schema{
+	{Name: "path", Required: true, Path: true, Description: "Ruta del archivo"},
+	{Name: "contenido", Required: true, Description: "Archivo de la computadora con el contenido nuevo"},
+}
+
+func init() {
+	Register(Command{
+		Name:        "edit",
+		Description: "Reemplaza el contenido de un archivo",
+		Params:      editParams,
+		Run:         ParseEdit,
+	})
+}
+
+func ParseEdit(session *stores.Session, tokens []string) (Result, error) {
+	args, err := editParams.Parse(tokens)
+	if err != nil {
+		return nil, err
+	}
+	cmd := &EDIT{path: resolvePath(session, args.String("path")), contenido: args.String("contenido")}
+
+	err = commandEdit(session, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return EditResult{Path: cmd.path}, nil
+}
+
+func commandEdit(session *stores.Session, edit *EDIT) error {
+	if session.IdPartition == "" {
+		return structures.NewError(structures.ErrPermission, "no hay sesion activa")
+	}
+	sb, partition, diskPath, err := stores.GetMountedPartitionSuperblock(session.IdPartition)
+	if err != nil {
+		return err
+	}
+
+	fileContent, err := os.ReadFile(edit.contenido)
+	if err != nil {
+		return err
+	}
+	// El contenido se registra en bloques de 64 bytes igual que en mkfile
+	journalStart := partition.Part_start + int32(binary.Size(structures.SuperBlock{}))
+	contentList := journalChunks(string(fileContent))
+	if sb.IsExt3() {
+		err = sb.CheckJournalSpace(diskPath, journalStart, len(contentList))
+		if err != nil {
+			return err
+		}
+	}
+	err = editFile(session, sb, diskPath, edit.path, string(fileContent))
+	if err != nil {
+		return err
+	}
+
+	if sb.IsExt3() {
+		for _, content := range contentList {
+			journalDirectory := &structures.Journal{
+				J_next: -1,
+				J_content: structures.Information{
+					I_operation: [10]byte{'e', 'd', 'i', 't'},
+					I_path:      [74]byte{},
+					I_content:   [64]byte{},
+					I_date:      float32(time.Now().Unix()),
+				},
+			}
+			copy(journalDirectory.J_content.I_path[:], edit.path)
+			copy(journalDirectory.J_content.I_content[:], content)
+			err = sb.AddJournal(journalDirectory, diskPath, journalStart)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	err = sb.Serialize(diskPath, int64(partition.Part_start))
+	if err != nil {
+		return fmt.Errorf("error al serializar el superbloque: %w", err)
+	}
+	return nil
+}
+
+func editFile(session *stores.Session, sb *structures.SuperBlock, diskPath, path, content string) error {
+	indexInode, inode, err := sb.Lookup(diskPath, path, session.UserID, session.GroupID)
+	if err != nil {
+		return err
+	}
+	if inode.I_type[0] != '1' {
+		return structures.NewError(structures.ErrInvalid, "la ruta indicada no es un archivo")
+	}
+	return sb.OverwriteFile(diskPath, indexInode, content, session.UserID, session.GroupID)
+}
@@ -1,15 +1,14 @@
 package commands
 
 import (
-	"errors"
+	"encoding/binary"
 	"fmt"
 	"io"
 	"os"
-	"regexp"
+	"server/lexer"
 	stores "server/stores"
 	"server/structures"
 	"server/utils"
-	"strconv"
 	"strings"
 )
 
@@ -24,121 +23,89 @@ type FDISK struct {
 	add    int
 }
 
-func ParseFdisk(tokens []string) (string, error) {
-	cmd := &FDISK{}
-
-	args := strings.Join(tokens, " ")
-	re := regexp.MustCompile(`-size=\d+|-unit=[kKmMbB]|-fit=[bBfF]{2}|-driveletter=[A-Za-z]|-type=[pPeElL]|-name="[^"]+"|-name=[^\s]+|-delete=[fFuUlL]+|-add=-?\d+`)
-	matches := re.FindAllString(args, -1)
-
-	for _, match := range matches {
-		kv := strings.SplitN(match, "=", 2)
-		if len(kv) != 2 {
-			return "", fmt.Errorf("formato de parametro invalid: %s", match)
-		}
-		key, value := strings.ToLower(kv[0]), kv[1]
-		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
-			value = strings.Trim(value, "\"")
-		}
+// FdiskResult.Action es create, delete o add
+type FdiskResult struct {
+	Action string `json:"action"`
+	Disk   string `json:"disk"`
+	Name   string `json:"name"`
+}
 
-		switch key {
-		case "-size":
-			size, err := strconv.Atoi(value)
-			if err != nil || size <= 0 {
-				return "", err
-			}
-			cmd.size = size
-		case "-unit":
-			value = strings.ToUpper(value)
-			if value != "K" && value != "M" && value != "B" {
-				return "", errors.New("la unidad debe ser K o M o B")
-			}
-			cmd.unit = strings.ToUpper(value)
-		case "-fit":
-			value = strings.ToUpper(value)
-			if value != "BF" && value != "FF" && value != "WF" {
-				return "", errors.New("el ajuste debe ser BF, FF o WF")
-			}
-			cmd.fit = value
-		case "-driveletter":
-			if value == "" {
-				return "", errors.New("el driveletter no puede estar vacío")
-			}
-			cmd.path = value
-		case "-type":
-			value = strings.ToUpper(value)
-			if value != "P" && value != "E" {
-				return "", errors.New("el tipo debe ser P o E")
-			}
-			cmd.typ = value
-		case "-name":
-			if value == "" {
-				return  "", errors.New("el nombre no puede estar vacío")
-			}
-			cmd.name = value
-		case "-delete":
-			value = strings.ToLower(value)
-			if value != "fast" && value != "full" {
-				return "", errors.New("para -delete se debe de indicar si sera fast o full")
-			}
-			cmd.delete = value
-		case "-add":
-			size, err := strconv.Atoi(value)
-			if err != nil {
-				return "", err
-			}
-			cmd.add = size
-		default:
-			return "", fmt.Errorf("parámetro desconocido: %s", key)
-		}
+func (result FdiskResult) String() string {
+	switch result.Action {
+	case "delete":
+		return fmt.Sprintf("FDISK: %s eliminado exitosamente", result.Name)
+	case "add":
+		return fmt.Sprintf("FDISK: %s add exitosamente", result.Name)
 	}
+	return fmt.Sprintf("FDISK: %s creado exitosamente", result.Name)
+}
 
-	if cmd.delete == "" && cmd.add == 0 {
-		if cmd.size == 0 {
-			return "", errors.New("faltan parámetros requeridos: -size")
-		}
-	}
-	if cmd.path == "" {
-		return "", errors.New("faltan parámetros requeridos: -driveletter")
-	}
-	cmd.path = stores.GetPathDisk(cmd.path)
-	if cmd.name == "" {
-		return "", errors.New("faltan parámetros requeridos: -name")
-	}
+var fdiskParams = lexer.Schema{
+	{Name: "size", Kind: lexer.Int, Description: "Tamano de la particion, requerido al crear"},
+	{Name: "unit", Default: "K", Values: []string{"B", "K", "M"}, Description: "Unidad de -size y -add"},
+	{Name: "fit", Values: []string{"BF", "FF", "WF"}, Description: "Ajuste de la particion, sin el usa el del disco"},
+	{Name: "driveletter", Kind: lexer.Letter, Required: true, Description: "Letra del disco"},
+	{Name: "type", Default: "P", Values: []string{"P", "E", "L"}, Description: "Particion primaria, extendida o logica"},
+	{Name: "name", Required: true, Description: "Nombre de la particion"},
+	{Name: "delete", Values: []string{"fast", "full"}, Description: "Elimina la particion"},
+	{Name: "add", Kind: lexer.Int, Description: "Espacio a agregar, negativo para quitar"},
+}
 
-	if cmd.unit == "" {
-		cmd.unit = "K"
-	}
+func init() {
+	Register(Command{
+		Name:        "fdisk",
+		Description: "Crea, elimina o cambia el tamano de una particion",
+		Params:      fdiskParams,
+		Run:         ParseFdisk,
+	})
+}
 
-	if cmd.fit == "" {
-		cmd.fit = "WF"
+func ParseFdisk(session *stores.Session, tokens []string) (Result, error) {
+	args, err := fdiskParams.Parse(tokens)
+	if err != nil {
+		return nil, err
+	}
+	cmd := &FDISK{
+		size:   args.Int("size"),
+		unit:   args.String("unit"),
+		fit:    args.String("fit"),
+		path:   stores.GetPathDisk(args.String("driveletter")),
+		typ:    args.String("type"),
+		name:   args.String("name"),
+		delete: args.String("delete"),
+		add:    args.Int("add"),
 	}
 
-	if cmd.typ == "" {
-		cmd.typ = "P"
+	if cmd.delete == "" && cmd.add == 0 {
+		if !args.Has("size") {
+			return nil, structures.NewError(structures.ErrInvalid, "faltan parametros requeridos: -size")
+		}
+		if cmd.size <= 0 {
+			return nil, structures.NewError(structures.ErrInvalid, "el tamano debe ser numero entero positivo")
+		}
 	}
 	if cmd.delete != "" && cmd.add != 0 {
-		return "", errors.New("no se puede tener add y delete en el mismo comando")
+		return nil, structures.NewError(structures.ErrInvalid, "no se puede tener add y delete en el mismo comando")
 	}
 
 	if cmd.delete != "" {
 		err := deletePartition(cmd)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
-		return fmt.Sprintf("FDISK: %s eliminado exitosamente", cmd.name), nil
+		return FdiskResult{Action: "delete", Disk: args.String("driveletter"), Name: cmd.name}, nil
 	} else if cmd.add != 0 {
 		err := addPartition(cmd)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
-		return fmt.Sprintf("FDISK: %s add exitosamente", cmd.name), nil
+		return FdiskResult{Action: "add", Disk: args.String("driveletter"), Name: cmd.name}, nil
 	} else {
 		err := commandFdisk(cmd)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
-		return fmt.Sprintf("FDISK: %s creado exitosamente", cmd.name), nil
+		return FdiskResult{Action: "create", Disk: args.String("driveletter"), Name: cmd.name}, nil
 	}
 }
 
@@ -159,6 +126,11 @@ func commandFdisk(fdisk *FDISK) error {
 		if err != nil {
 			return err
 		}
+	} else if fdisk.typ == "L" {
+		err = createLogicalPartition(fdisk, sizeBytes)
+		if err != nil {
+			return err
+		}
 	}
 	return nil
 
@@ -171,22 +143,19 @@ func createPrimaryPartition(fdisk *FDISK, sizeBytes int) error {
 	if err != nil {
 		return err
 	}
-	if !mbr.CanFitAnotherDisk(sizeBytes) {
-		return errors.New("no se puede crear una particion por falta de espacio")
-	}
-
 	// fmt.Println("\nMBR original: ")
 	// mbr.PrintMBR()
 
-	availablePartition, startPartition, indexPartition := mbr.GetFirstAvailablePartition()
-	if availablePartition == nil {
-		return errors.New("no hay partitciones disponibles")
+	fit := partitionFit(fdisk, &mbr)
+	availablePartition, startPartition, indexPartition, err := mbr.GetAvailablePartition(sizeBytes, fit[0])
+	if err != nil {
+		return err
 	}
 
 	// fmt.Println("\nParticion disponible:")
 	// availablePartition.PrintPartition()
 
-	availablePartition.CreatePartition(startPartition, sizeBytes, fdisk.typ, fdisk.fit, fdisk.name)
+	availablePartition.CreatePartition(startPartition, sizeBytes, fdisk.typ, fit, fdisk.name)
 
 	// fmt.Println("\nParticion creada (modificada):")
 	// availablePartition.PrintPartition()
@@ -205,6 +174,14 @@ func createPrimaryPartition(fdisk *FDISK, sizeBytes int) error {
 
 }
 
+// partitionFit usa el ajuste del comando y si no se indico el del disco
+func partitionFit(fdisk *FDISK, mbr *structures.MBR) string {
+	if fdisk.fit != "" {
+		return fdisk.fit
+	}
+	return string(mbr.Mbr_disk_fit[0]) + "F"
+}
+
 func createExtendedPartittion(fdisk *FDISK, sizeBytes int) error {
 	var mbr structures.MBR
 
@@ -213,23 +190,20 @@ func createExtendedPartittion(fdisk *FDISK, sizeBytes int) error {
 		return err
 	}
 
-	if !mbr.CanFitAnotherDisk(sizeBytes) {
-		return errors.New("no se puede crear una particion por falta de espacio")
-	}
-
 	// fmt.Println("\nMBR original: ")
 	// mbr.PrintMBR()
 
 	if mbr.IsThereExtendedPartition() {
-		return errors.New("no se puede crear mas de 1 particion extendida por disco")
+		return structures.NewError(structures.ErrExists, "no se puede crear mas de 1 particion extendida por disco")
 	}
 
-	availablePartition, startPartition, indexPartition := mbr.GetFirstAvailablePartition()
-	if availablePartition == nil {
-		return errors.New("no hay partitciones disponibles")
+	fit := partitionFit(fdisk, &mbr)
+	availablePartition, startPartition, indexPartition, err := mbr.GetAvailablePartition(sizeBytes, fit[0])
+	if err != nil {
+		return err
 	}
 
-	availablePartition.CreatePartition(startPartition, sizeBytes, fdisk.typ, fdisk.fit, fdisk.name)
+	availablePartition.CreatePartition(startPartition, sizeBytes, fdisk.typ, fit, fdisk.name)
 
 	// fmt.Println("\nParticion creada (modificada):")
 	// availablePartition.PrintPartition()
@@ -244,10 +218,29 @@ func createExtendedPartittion(fdisk *FDISK, sizeBytes int) error {
 		return err
 	}
 
-	return nil
+	// La extendida empieza con un EBR vacio que encabeza la cadena de logicas
+	return structures.NewEmptyEBR(-1).Serialize(fdisk.path, int64(startPartition))
 
 }
 
+func createLogicalPartition(fdisk *FDISK, sizeBytes int) error {
+	var mbr structures.MBR
+
+	err := mbr.DeserializeMBR(fdisk.path)
+	if err != nil {
+		return err
+	}
+
+	if partition, _ := mbr.GetPartitionByName(fdisk.name); partition != nil {
+		return structures.Errorf(structures.ErrExists, "ya existe una particion con el nombre %s", fdisk.name)
+	}
+	if ebr, _, _ := mbr.GetLogicalPartitionByName(fdisk.path, fdisk.name); ebr != nil {
+		return structures.Errorf(structures.ErrExists, "ya existe una particion con el nombre %s", fdisk.name)
+	}
+
+	return mbr.CreateLogicalPartition(fdisk.path, sizeBytes, fdisk.fit, fdisk.name)
+}
+
 func deletePartition(fdisk *FDISK) error {
 	mbr := &structures.MBR{}
 	err := mbr.DeserializeMBR(fdisk.path)
@@ -265,7 +258,29 @@ func deletePartition(fdisk *FDISK) error {
 	}
 	var partitionStart int32
 	var partitionSize int32
-	if !logicPartition {
+	if logicPartition {
+		start, end, err := mbr.DeleteLogicalPartition(fdisk.path, fdisk.name)
+		if err != nil {
+			return err
+		}
+		partitionStart = start
+		partitionSize = end - start
+	} else {
+		if partition.Part_status[0] == '1' {
+			return structures.NewError(structures.ErrInvalid, "no se puede eliminar una particion montada")
+		}
+		// Las logicas montadas quedarian en la tabla de montajes apuntando a espacio borrado
+		if partition.Part_type[0] == 'E' {
+			logicals, _, err := mbr.GetLogicalPartitions(fdisk.path)
+			if err != nil {
+				return err
+			}
+			for _, logical := range logicals {
+				if logical.Part_mount[0] == '1' {
+					return structures.Errorf(structures.ErrInvalid, "no se puede eliminar la extendida, la logica %s esta montada", strings.Trim(string(logical.Part_name[:]), "\x00 "))
+				}
+			}
+		}
 		partitionStart = partition.Part_start
 		partitionSize = partition.Part_size
 		cleanPartition := &structures.PARTITION{
@@ -320,61 +335,100 @@ func addPartition(fdisk *FDISK) error {
 func shrinkPartition(fdisk *FDISK, sizeBytes int) error {
 	mbr := &structures.MBR{}
 	err := mbr.DeserializeMBR(fdisk.path)
-	var logicPartition bool
 	if err != nil {
 		return err
 	}
 	partition, indexPartition := mbr.GetPartitionByName(fdisk.name)
 	if partition == nil {
-		partition, err = mbr.GetExtendedPartition()
+		return resizeLogicalPartition(fdisk, mbr, -sizeBytes)
+	}
+	if sizeBytes > int(partition.Part_size) {
+		return structures.NewError(structures.ErrInvalid, "no se puede quitar bytes a la particion dado que quedaria en negativo el size")
+	}
+	// Reducir solo el MBR dejaria bloques del sistema de archivos fuera de la particion
+	sb := &structures.SuperBlock{}
+	if partition.Part_type[0] == 'E' {
+		end, err := extendedUsedEnd(fdisk.path, partition)
 		if err != nil {
 			return err
 		}
-		logicPartition = true
-	}
-	if !logicPartition {
-		if sizeBytes > int(partition.Part_size) {
-			return errors.New("no se puede quitar bytes a la particion dado que quedaria en negativo el size")
+		if partition.Part_start+partition.Part_size-int32(sizeBytes) < end {
+			return structures.Errorf(structures.ErrNoSpace, "no se puede reducir la extendida, sus logicas llegan hasta el byte %d", end)
 		}
-		partition.Part_size = partition.Part_size - int32(sizeBytes)
+	} else if sb.Deserialize(fdisk.path, int64(partition.Part_start)) == nil && sb.S_magic == 0xEF53 {
+		return structures.NewError(structures.ErrInvalid, "la particion tiene un sistema de archivos, use resizefs -add para reducirla")
+	}
+	partition.Part_size = partition.Part_size - int32(sizeBytes)
 
-		mbr.Mbr_partitions[indexPartition] = *partition
-		err = mbr.SerializeMBR(fdisk.path)
-		if err != nil {
-			return err
+	mbr.Mbr_partitions[indexPartition] = *partition
+	return mbr.SerializeMBR(fdisk.path)
+}
+
+// extendedUsedEnd devuelve donde termina la ultima logica de la extendida, o su ultimo EBR si no tiene logicas
+func extendedUsedEnd(diskPath string, extended *structures.PARTITION) (int32, error) {
+	ebrs, offsets, err := structures.ReadEBRChain(diskPath, extended.Part_start)
+	if err != nil {
+		return 0, err
+	}
+	end := extended.Part_start
+	for i := range ebrs {
+		end = max(end, offsets[i]+int32(binary.Size(structures.EBR{})))
+		if ebrs[i].IsUsed() {
+			end = max(end, ebrs[i].Part_start+ebrs[i].Part_size)
 		}
 	}
-	return nil
+	return end, nil
 }
 
 func increasePartition(fdisk *FDISK, sizeBytes int) error {
 	mbr := &structures.MBR{}
 	err := mbr.DeserializeMBR(fdisk.path)
-	var logicPartition bool
 	if err != nil {
 		return err
 	}
 	partition, indexPartition := mbr.GetPartitionByName(fdisk.name)
 	if partition == nil {
-		partition, err = mbr.GetExtendedPartition()
-		if err != nil {
-			return err
-		}
-		logicPartition = true
+		return resizeLogicalPartition(fdisk, mbr, sizeBytes)
+	}
+	outcome := isItPosibleToAdd(partition.Part_start+partition.Part_size, mbr, sizeBytes, indexPartition, mbr.Mbr_size)
+	if !outcome {
+		return structures.NewError(structures.ErrNoSpace, "no hay suficiente espacio como para adicionar bytes a la particion")
 	}
-	if !logicPartition {
-		outcome := isItPosibleToAdd(partition.Part_start+partition.Part_size, mbr, sizeBytes, indexPartition, mbr.Mbr_size)
-		if !outcome {
-			return errors.New("no hay suficiente espacio como para adicionar bytes a la particion")
+	partition.Part_size += int32(sizeBytes)
+	mbr.Mbr_partitions[indexPartition] = *partition
+	return mbr.SerializeMBR(fdisk.path)
+}
+
+// resizeLogicalPartition cambia el tamaño de la logica en su EBR, al crecer no puede pasar del siguiente EBR ni del final de la extendida
+func resizeLogicalPartition(fdisk *FDISK, mbr *structures.MBR, amount int) error {
+	ebr, offset, _ := mbr.GetLogicalPartitionByName(fdisk.path, fdisk.name)
+	if ebr == nil {
+		return structures.Errorf(structures.ErrNotFound, "la particion %s no existe", fdisk.name)
+	}
+	if amount < 0 {
+		// Con size 0 el EBR quedaria como libre y la logica desapareceria
+		if -amount >= int(ebr.Part_size) {
+			return structures.NewError(structures.ErrInvalid, "no se puede quitar bytes a la particion dado que quedaria en negativo el size")
 		}
-		partition.Part_size += int32(sizeBytes)
-		mbr.Mbr_partitions[indexPartition] = *partition
-		err = mbr.SerializeMBR(fdisk.path)
-		if err != nil {
-			return err
+		sb := &structures.SuperBlock{}
+		if sb.Deserialize(fdisk.path, int64(ebr.Part_start)) == nil && sb.S_magic == 0xEF53 {
+			return structures.NewError(structures.ErrInvalid, "la particion tiene un sistema de archivos, use resizefs -add para reducirla")
+		}
+	} else {
+		limit := ebr.Part_next
+		if limit == -1 {
+			extended, err := mbr.GetExtendedPartition()
+			if err != nil {
+				return err
+			}
+			limit = extended.Part_start + extended.Part_size
+		}
+		if int(ebr.Part_start+ebr.Part_size)+amount > int(limit) {
+			return structures.NewError(structures.ErrNoSpace, "no hay suficiente espacio como para adicionar bytes a la particion")
 		}
 	}
-	return nil
+	ebr.Part_size += int32(amount)
+	return ebr.Serialize(fdisk.path, int64(offset))
 }
 
 func isItPosibleToAdd(partitionEnd int32, mbr *structures.MBR, amountOfBytes int, indexPartition int, diskEnd int32) bool {
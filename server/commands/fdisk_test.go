@@ -0,0 +1,72 @@
+package commands
+
+import (
+	"errors"
+	"server/stores"
+	"server/structures"
+	"strconv"
+	"testing"
+)
+
+func readMBR(t *testing.T) (*structures.MBR, string) {
+	t.Helper()
+	diskPath := stores.GetPathDisk("A")
+	mbr := &structures.MBR{}
+	err := mbr.DeserializeMBR(diskPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return mbr, diskPath
+}
+
+// La extendida no se puede reducir por debajo de sus logicas ni eliminar con una logica montada
+func TestFdiskExtended(t *testing.T) {
+	newTestState(t)
+	session := &stores.Session{}
+	run(t, session,
+		"mkdisk -size=3 -unit=M",
+		"fdisk -size=200 -driveletter=A -name=P1",
+		"fdisk -size=1024 -driveletter=A -name=E1 -type=E",
+		"fdisk -size=300 -driveletter=A -name=L1 -type=L",
+		"fdisk -size=300 -driveletter=A -name=L2 -type=L",
+		"mount -driveletter=A -name=P1",
+		"mount -driveletter=A -name=L2",
+	)
+	mbr, diskPath := readMBR(t)
+	l2, _, _ := mbr.GetLogicalPartitionByName(diskPath, "L2")
+	extended, _ := mbr.GetPartitionByName("E1")
+	logicalsEnd := l2.Part_start + l2.Part_size
+
+	if _, err := tryRun(session, "fdisk -add=-900 -driveletter=A -name=E1"); !errors.Is(err, structures.ErrNoSpace) {
+		t.Fatalf("se redujo la extendida por debajo de sus logicas: %v", err)
+	}
+	// Se puede quitar justo hasta el final de L2
+	free := extended.Part_start + extended.Part_size - logicalsEnd
+	if _, err := tryRun(session, "fdisk -add=-"+strconv.Itoa(int(free+1))+" -unit=B -driveletter=A -name=E1"); !errors.Is(err, structures.ErrNoSpace) {
+		t.Fatalf("se quito un byte de L2: %v", err)
+	}
+	run(t, session, "fdisk -add=-"+strconv.Itoa(int(free))+" -unit=B -driveletter=A -name=E1")
+	mbr, _ = readMBR(t)
+	if extended, _ = mbr.GetPartitionByName("E1"); extended.Part_start+extended.Part_size != logicalsEnd {
+		t.Fatalf("la extendida termina en %d y L2 en %d", extended.Part_start+extended.Part_size, logicalsEnd)
+	}
+
+	for _, line := range []string{"fdisk -delete=full -driveletter=A -name=E1", "fdisk -delete=fast -driveletter=A -name=P1"} {
+		if _, err := tryRun(session, line); !errors.Is(err, structures.ErrInvalid) {
+			t.Fatalf("%s: se elimino con una particion montada: %v", line, err)
+		}
+	}
+	mbr, _ = readMBR(t)
+	if partition, _ := mbr.GetPartitionByName("E1"); partition == nil {
+		t.Fatal("la extendida ya no esta en el MBR")
+	}
+	if _, _, err := stores.GetMountedPartition("A205"); err != nil {
+		t.Fatalf("L2 dejo de estar montada: %v", err)
+	}
+
+	run(t, session, "unmount -id=A205", "fdisk -delete=full -driveletter=A -name=E1")
+	mbr, _ = readMBR(t)
+	if partition, _ := mbr.GetPartitionByName("E1"); partition != nil {
+		t.Fatal("la extendida sigue en el MBR")
+	}
+}
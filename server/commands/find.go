@@ -1,12 +1,10 @@
 package commands
 
 import (
-	"errors"
 	"fmt"
-	"regexp"
-	"server/reports"
+	"server/lexer"
 	"server/stores"
-	utils "server/utils"
+	"server/structures"
 	"strings"
 )
 
@@ -15,82 +13,97 @@ type FIND struct {
 	name string
 }
 
-// \.    .*             .{1}
-func ParseFind(tokens []string) (string, error) {
-	cmd := &FIND{}
-	args := strings.Join(tokens, " ")
-	re := regexp.MustCompile(`-path="[^"]+"|-path=[^\s]+|-name="[^"]+"|-name=[^\s]+`)
-	matches := re.FindAllString(args, -1)
-
-	for _, match := range matches {
-		kv := strings.SplitN(match, "=", 2)
-		if len(kv) != 2 {
-			return "", fmt.Errorf("formato de parametro invalido: %s", match)
-		}
-		key, value := strings.ToLower(kv[0]), kv[1]
+type FindResult struct {
+	Path    string   `json:"path"`
+	Name    string   `json:"name"`
+	Matches []string `json:"matches"`
+}
 
-		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
-			value = strings.Trim(value, "\"")
+// String arma el arbol de la busqueda, con las carpetas que llevan a cada coincidencia
+func (result FindResult) String() string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "FIND: %s\n", result.Path)
+	var printed []string
+	for _, match := range result.Matches {
+		names := strings.Split(strings.TrimPrefix(strings.TrimPrefix(match, result.Path), "/"), "/")
+		common := 0
+		for common < len(printed) && common < len(names) && printed[common] == names[common] {
+			common++
 		}
-
-		switch key {
-		case "-path":
-			if value == "" {
-				return "", errors.New("el path no puede estar vacio")
-			}
-			cmd.path = value
-		case "-name":
-			if value == "" {
-				return "", errors.New("el name no puede estar vacio")
-			}
-			value = strings.ReplaceAll(value, ".", "\\.")
-			value = strings.ReplaceAll(value, "*", ".+")
-			value = strings.ReplaceAll(value, "?", ".{1}")
-			cmd.name = "^" + value + "$"
-		default:
-			return "", fmt.Errorf("parametro desconocido: %s", key)
+		for level := common; level < len(names); level++ {
+			builder.WriteString(strings.Repeat("   ", level+1) + names[level] + "\n")
 		}
+		printed = names
 	}
-	if cmd.path == "" {
-		return "", errors.New("faltan parámetros requeridos: -path")
-	}
-	if cmd.name == "" {
-		return "", errors.New("faltan parámetros requeridos: -name")
-	}
+	builder.WriteString(" ")
+	return builder.String()
+}
+
+var findParams = lexer.Schema{
+	{Name: "path", Required: true, Path: true, Description: "Carpeta donde empieza la busqueda"},
+	{Name: "name", Required: true, Description: "Nombre a buscar, acepta * y ?"},
+}
+
+func init() {
+	Register(Command{
+		Name:        "find",
+		Description: "Busca archivos y carpetas por nombre",
+		Params:      findParams,
+		Run:         ParseFind,
+	})
+}
 
-	result, err := commandFind(cmd)
+// \.    .*             .{1}
+func ParseFind(session *stores.Session, tokens []string) (Result, error) {
+	args, err := findParams.Parse(tokens)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	fmt.Println(result)
+	value := args.String("name")
+	value = strings.ReplaceAll(value, ".", "\\.")
+	value = strings.ReplaceAll(value, "*", ".+")
+	value = strings.ReplaceAll(value, "?", ".{1}")
+	cmd := &FIND{path: resolvePath(session, args.String("path")), name: "^" + value + "$"}
 
-	return fmt.Sprintf("FIND: %s\n%s ", cmd.path, result), nil
+	matches, err := commandFind(session, cmd)
+	if err != nil {
+		return nil, err
+	}
+	result := FindResult{Path: cmd.path, Name: args.String("name"), Matches: matches}
+	return result, nil
 }
 
-func commandFind(find *FIND) (string, error) {
-	sb, _, diskPath, err := stores.GetMountedPartitionSuperblock(stores.LogedIdPartition)
+func commandFind(session *stores.Session, find *FIND) ([]string, error) {
+	sb, _, diskPath, err := stores.GetMountedPartitionSuperblock(session.IdPartition)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	inodoBase, offsetToSerialize, err := reports.UbicarInodo(sb, find.path, diskPath)
+	offsetToSerialize, inodoBase, err := sb.Lookup(diskPath, find.path, session.UserID, session.GroupID)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	outcome, err := inodoBase.HasPermissionsToRead(utils.LogedUserID, utils.LogedUserGroupID)
+	outcome, err := inodoBase.HasPermissionsToRead(session.UserID, session.GroupID)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	if !outcome {
-		return "", errors.New("accion prohibida por falta de permisos")
+		return nil, structures.NewError(structures.ErrPermission, "accion prohibida por falta de permisos")
 	}
 	tipoInodo, err := sb.TypeOfInode(diskPath, offsetToSerialize)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	if tipoInodo == 1 {
-		return "", errors.New("este comando solo es aplicable a carpetas no a archivos")
+		return nil, structures.NewError(structures.ErrInvalid, "este comando solo es aplicable a carpetas no a archivos")
 	}
 
-	return sb.CommandFind(diskPath, offsetToSerialize, 1, find.name)
+	matches, err := sb.CommandFind(diskPath, offsetToSerialize, find.path, find.name, session.UserID, session.GroupID)
+	if err != nil {
+		return nil, err
+	}
+	if matches == nil {
+		matches = []string{}
+	}
+	return matches, nil
 }
@@ -0,0 +1,91 @@
+package commands
+
+import (
+	"fmt"
+	"server/lexer"
+	"server/stores"
+	"strings"
+)
+
+type FSCK struct {
+	id     string
+	repair bool
+}
+
+type FsckResult struct {
+	ID       string   `json:"id"`
+	Problems []string `json:"problems"`
+	Repair   bool     `json:"repair"`
+	Repaired int      `json:"repaired"`
+	Pending  int      `json:"pending"`
+}
+
+func (result FsckResult) String() string {
+	if len(result.Problems) == 0 {
+		return fmt.Sprintf("FSCK: %s no tiene problemas", result.ID)
+	}
+
+	var output strings.Builder
+	fmt.Fprintf(&output, "FSCK: %s tiene %d problemas\n", result.ID, len(result.Problems))
+	for _, problem := range result.Problems {
+		fmt.Fprintf(&output, "  - %s\n", problem)
+	}
+	if !result.Repair {
+		output.WriteString("FSCK: use -repair para corregirlos")
+		return output.String()
+	}
+	fmt.Fprintf(&output, "FSCK: %d reparados, %d pendientes", result.Repaired, result.Pending)
+	return output.String()
+}
+
+var fsckParams = lexer.Schema{
+	{Name: "id", Required: true, Description: "Id de la particion montada"},
+	{Name: "repair", Kind: lexer.Flag, Description: "Corrige lo que encuentre"},
+}
+
+func init() {
+	Register(Command{
+		Name:        "fsck",
+		Description: "Revisa la consistencia del sistema de archivos",
+		Params:      fsckParams,
+		Run:         ParseFsck,
+	})
+}
+
+func ParseFsck(session *stores.Session, tokens []string) (Result, error) {
+	args, err := fsckParams.Parse(tokens)
+	if err != nil {
+		return nil, err
+	}
+	cmd := &FSCK{id: args.String("id"), repair: args.Flag("repair")}
+
+	result, err := commandFsck(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func commandFsck(fsck *FSCK) (*FsckResult, error) {
+	sb, partition, diskPath, err := stores.GetMountedPartitionSuperblock(fsck.id)
+	if err != nil {
+		return nil, err
+	}
+	report, err := sb.Fsck(diskPath, fsck.repair)
+	if err != nil {
+		return nil, err
+	}
+	result := &FsckResult{ID: fsck.id, Problems: report.Problems, Repair: fsck.repair, Repaired: report.Repaired, Pending: report.Pending}
+	if result.Problems == nil {
+		result.Problems = []string{}
+	}
+	if len(report.Problems) == 0 || !fsck.repair {
+		return result, nil
+	}
+
+	err = sb.Serialize(diskPath, int64(partition.Part_start))
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
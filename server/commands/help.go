@@ -0,0 +1,86 @@
+package commands
+
+import (
+	"fmt"
+	"server/stores"
+	"server/structures"
+	"strings"
+	"text/tabwriter"
+)
+
+func init() {
+	Register(Command{
+		Name:        "help",
+		Description: "Muestra los comandos disponibles, con help <comando> muestra sus parametros",
+		Run:         ParseHelp,
+	})
+}
+
+// HelpResult trae todos los comandos, o solo el pedido con help <comando>
+type HelpResult struct {
+	Commands []Command `json:"commands"`
+	detail   bool
+}
+
+func (result HelpResult) String() string {
+	if result.detail {
+		return commandHelp(result.Commands[0])
+	}
+	return commandHelpList(result.Commands)
+}
+
+// ParseHelp recibe el nombre del comando sin guion, no es un parametro
+func ParseHelp(session *stores.Session, tokens []string) (Result, error) {
+	switch len(tokens) {
+	case 0:
+		return HelpResult{Commands: Registered()}, nil
+	case 1:
+		command, found := Lookup(tokens[0])
+		if !found {
+			return nil, structures.Errorf(structures.ErrInvalid, "comando desconocido: %s", tokens[0])
+		}
+		return HelpResult{Commands: []Command{command}, detail: true}, nil
+	default:
+		return nil, structures.Errorf(structures.ErrInvalid, "parametro invalido: %s", tokens[1])
+	}
+}
+
+func commandHelpList(list []Command) string {
+	var builder strings.Builder
+	builder.WriteString("HELP: comandos disponibles\n")
+	writer := tabwriter.NewWriter(&builder, 0, 0, 2, ' ', 0)
+	for _, command := range list {
+		fmt.Fprintf(writer, "  %s\t%s\n", command.Name, command.Description)
+	}
+	writer.Flush()
+	builder.WriteString("Use help <comando> para ver sus parametros")
+	return builder.String()
+}
+
+func commandHelp(command Command) string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "HELP: %s\n%s\nUso: %s", command.Name, command.Description, command.Usage())
+	if len(command.Params) == 0 {
+		return builder.String()
+	}
+
+	builder.WriteString("\nParametros:\n")
+	writer := tabwriter.NewWriter(&builder, 0, 0, 2, ' ', 0)
+	for _, param := range command.Params {
+		name := "-" + param.Name
+		if param.Numbered {
+			name += "N"
+		}
+		required := "opcional"
+		if param.Required {
+			required = "requerido"
+		}
+		description := param.Description
+		if param.Default != "" {
+			description += fmt.Sprintf(" (por defecto %s)", param.Default)
+		}
+		fmt.Fprintf(writer, "  %s\t%s\t%s\t%s\n", name, param.Kind, required, description)
+	}
+	writer.Flush()
+	return strings.TrimSuffix(builder.String(), "\n")
+}
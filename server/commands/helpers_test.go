@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"server/lexer"
+	"server/stores"
+	"server/structures"
+	"server/utils"
+	"testing"
+)
+
+// newTestState deja los discos en un directorio temporal y reinicia los contadores de letras, que son globales entre pruebas
+func newTestState(t *testing.T) {
+	stores.SetPathDisk(t.TempDir())
+	stores.ClearAllDisks()
+	utils.SetLetterCounters(0, 0)
+}
+
+// tryRun ejecuta la linea como el analizador pero sin tomar los candados, las pruebas corren un comando a la vez
+func tryRun(session *stores.Session, line string) (Result, error) {
+	tokens, err := lexer.Tokenize(line)
+	if err != nil {
+		return nil, err
+	}
+	command, found := Lookup(tokens[0])
+	if !found {
+		return nil, structures.Errorf(structures.ErrInvalid, "comando desconocido: %v", tokens[0])
+	}
+	return command.Run(session, tokens[1:])
+}
+
+func run(t *testing.T, session *stores.Session, lines ...string) {
+	t.Helper()
+	for _, line := range lines {
+		if _, err := tryRun(session, line); err != nil {
+			t.Fatalf("%s: %v", line, err)
+		}
+	}
+}
+
+// readFile lee un archivo de la particion directo del disco, sin sesion ni permisos
+func readFile(t *testing.T, id, path string) string {
+	t.Helper()
+	sb, _, diskPath, err := stores.GetMountedPartitionSuperblock(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parentDirs, destDir := utils.GetParentDirectories(path)
+	content, err := sb.ContentFromFile(diskPath, 0, parentDirs, destDir)
+	if err != nil {
+		t.Fatalf("%s: %v", path, err)
+	}
+	return content
+}
+
+// checkFsck falla si fsck encuentra algun problema en la particion
+func checkFsck(t *testing.T, id string) {
+	t.Helper()
+	sb, _, diskPath, err := stores.GetMountedPartitionSuperblock(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := sb.Fsck(diskPath, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Problems) != 0 {
+		t.Fatalf("fsck encontro problemas en %s: %q", id, report.Problems)
+	}
+}
@@ -0,0 +1,114 @@
+package commands
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"server/stores"
+	"server/structures"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func journalLength(t *testing.T, id string) int {
+	t.Helper()
+	sb, partition, diskPath, err := stores.GetMountedPartitionSuperblock(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	journals, _, err := sb.JournalChain(diskPath, partition.Part_start+int32(binary.Size(structures.SuperBlock{})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(journals)
+}
+
+// Un archivo cuyos fragmentos no caben en el journal se rechaza antes de escribir nada,
+// el journal nunca pasa sus n entradas hacia el bitmap de inodos
+func TestJournalFull(t *testing.T) {
+	newTestState(t)
+	session := &stores.Session{}
+	run(t, session,
+		"mkdisk -size=1 -unit=M",
+		"fdisk -size=200 -unit=K -driveletter=A -name=P1",
+		"mount -driveletter=A -name=P1",
+		"mkfs -id=A105 -fs=3fs",
+		"login -user=root -pass=123 -id=A105",
+	)
+	sb, _, diskPath, err := stores.GetMountedPartitionSuperblock("A105")
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := int(sb.TotalInodes())
+	contenido := filepath.Join(t.TempDir(), "grande.txt")
+	err = os.WriteFile(contenido, []byte(strings.Repeat("x", 64*n)), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	run(t, session, "mkfile -path=/a.txt -size=10")
+
+	for _, line := range []string{
+		"mkfile -path=/grande.txt -size=" + strconv.Itoa(64*n),
+		"mkfile -r -path=/nueva/grande.txt -cont=" + contenido,
+		"edit -path=/a.txt -contenido=" + contenido,
+	} {
+		before := journalLength(t, "A105")
+		if _, err := tryRun(session, line); !errors.Is(err, structures.ErrNoSpace) {
+			t.Fatalf("%s: se esperaba ErrNoSpace y llego %v", line, err)
+		}
+		if journalLength(t, "A105") != before {
+			t.Fatalf("%s: el journal cambio aunque el comando fallo", line)
+		}
+		checkFsck(t, "A105")
+	}
+	if readFile(t, "A105", "/a.txt") != "0123456789" {
+		t.Fatal("edit cambio el archivo aunque no cabia en el journal")
+	}
+	if _, _, err := sb.Lookup(diskPath, "/nueva", 1, 1); err == nil {
+		t.Fatal("mkfile -r creo la carpeta aunque el archivo no cabia en el journal")
+	}
+
+	// Se llena el journal hasta la ultima entrada, la siguiente se rechaza
+	free := n - journalLength(t, "A105")
+	run(t, session, "mkfile -path=/lleno.txt -size="+strconv.Itoa(64*(free-1)))
+	if journalLength(t, "A105") != n {
+		t.Fatalf("el journal tiene %d entradas de %d", journalLength(t, "A105"), n)
+	}
+	if _, err := tryRun(session, "mkdir -path=/otra"); !errors.Is(err, structures.ErrNoSpace) {
+		t.Fatalf("con el journal lleno se esperaba ErrNoSpace y llego %v", err)
+	}
+	checkFsck(t, "A105")
+
+	// recovery repite un journal lleno sin quedarse sin espacio
+	tree := snapshotTree(t, "A105")
+	run(t, session, "loss -id=A105", "recovery -id=A105")
+	if got := snapshotTree(t, "A105"); !reflect.DeepEqual(got, tree) {
+		t.Fatal("recovery no dejo el arbol como estaba con el journal lleno")
+	}
+	if journalLength(t, "A105") != n {
+		t.Fatalf("despues de recovery el journal tiene %d entradas de %d", journalLength(t, "A105"), n)
+	}
+	checkFsck(t, "A105")
+}
+
+// Ninguna entrada del journal guarda un password en texto plano, ni la de users.txt que crea mkfs
+func TestJournalWithoutPasswords(t *testing.T) {
+	newUsersDisk(t)
+	sb, partition, diskPath, err := stores.GetMountedPartitionSuperblock("A105")
+	if err != nil {
+		t.Fatal(err)
+	}
+	journals, _, err := sb.JournalChain(diskPath, partition.Part_start+int32(binary.Size(structures.SuperBlock{})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, journal := range journals {
+		content := strings.TrimRight(string(journal.J_content.I_content[:]), "\x00")
+		if strings.Contains(content, "123") || strings.Contains(content, "clave") {
+			t.Errorf("%s guarda un password en el journal: %q", journal.J_content.I_operation[:], content)
+		}
+	}
+}
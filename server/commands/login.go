@@ -2,9 +2,8 @@ package commands
 
 import (
 	"encoding/binary"
-	"errors"
 	"fmt"
-	"regexp"
+	"server/lexer"
 	stores "server/stores"
 	"server/structures"
 	utils "server/utils"
@@ -19,65 +18,49 @@ type LOGIN struct {
 	Id       string
 }
 
-func ParseLogin(tokens []string) (string, error) {
-	cmd := &LOGIN{}
+type LoginResult struct {
+	User string `json:"user"`
+	ID   string `json:"id"`
+}
 
-	args := strings.Join(tokens, " ")
-	re := regexp.MustCompile(`-id=[a-zA-Z0-9]+|-user="[^"]+"|-user=[^\s]+|-pass="[^"]+"|-pass=[^\s]+`)
-	matches := re.FindAllString(args, -1)
-	for _, match := range matches {
-		kv := strings.SplitN(match, "=", 2)
-		if len(kv) != 2 {
-			return "", fmt.Errorf("formato de parametro invalido: %s", match)
-		}
-		key, value := strings.ToLower(kv[0]), kv[1]
+func (result LoginResult) String() string {
+	return fmt.Sprintf("LOGIN: %s logeado exitosamente", result.User)
+}
 
-		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
-			value = strings.Trim(value, "\"")
-		}
+var loginParams = lexer.Schema{
+	{Name: "user", Required: true, Description: "Usuario"},
+	{Name: "pass", Required: true, Description: "Contrasena"},
+	{Name: "id", Required: true, Description: "Id de la particion montada"},
+}
 
-		switch key {
-		case "-id":
-			if value == "" {
-				return "", errors.New("el id no puede estar vacio")
-			}
-			cmd.Id = value
-		case "-pass":
-			if value == "" {
-				return "", errors.New("el password no puede estar vacio")
-			}
-			cmd.Password = value
-		case "-user":
-			if value == "" {
-				return "", errors.New("el user no puede estar vacio")
-			}
-			cmd.User = value
-		default:
-			return "", fmt.Errorf("parametro desconocido: %s", key)
-		}
-	}
-	if cmd.Password == "" {
-		return "", errors.New("faltan parametros requeridos: -pass")
-	}
-	if cmd.User == "" {
-		return "", errors.New("faltan parametros requeridos: -user")
-	}
-	if cmd.Id == "" {
-		return "", errors.New("faltan parametros requeridos: -id")
+func init() {
+	Register(Command{
+		Name:        "login",
+		Description: "Inicia sesion en una particion montada",
+		Params:      loginParams,
+		Run:         ParseLogin,
+	})
+}
+
+func ParseLogin(session *stores.Session, tokens []string) (Result, error) {
+	args, err := loginParams.Parse(tokens)
+	if err != nil {
+		return nil, err
 	}
+	cmd := &LOGIN{User: args.String("user"), Password: args.String("pass"), Id: args.String("id")}
 
-	err := CommandLogin(cmd)
+	err = CommandLogin(session, cmd)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return fmt.Sprintf("LOGIN: %s logeado exitosamente", cmd.User), nil
+	return LoginResult{User: cmd.User, ID: session.IdPartition}, nil
 
 }
 
-func CommandLogin(login *LOGIN) error {
-	if stores.LogedIdPartition != "" {
-		return errors.New("se debe realizar un logout antes de un login")
+func CommandLogin(session *stores.Session, login *LOGIN) error {
+	if session.IdPartition != "" {
+		return structures.NewError(structures.ErrInvalid, "se debe realizar un logout antes de un login")
 	}
 	contentUsersTxt, err := getContetnUsersTxt(login.Id)
 	if err != nil {
@@ -87,15 +70,29 @@ func CommandLogin(login *LOGIN) error {
 
 	credentials := validateInformation(login.User, login.Password, contentMatrix)
 	if !credentials {
-		return errors.New("credenciales invalidas en el login")
+		return structures.NewError(structures.ErrPermission, "credenciales invalidas en el login")
 	}
-	stores.LogedIdPartition = login.Id
-	stores.LogedUser = login.User
-	err = setUpIDs(login.User, contentMatrix)
+	sb, part, diskPath, err := stores.GetMountedPartitionSuperblock(login.Id)
 	if err != nil {
 		return err
 	}
-	sb, part, diskPath, err := stores.GetMountedPartitionSuperblock(stores.LogedIdPartition)
+	// El login y, si hay que cambiar el password en texto plano por su hash, el passwd
+	entries := 1
+	if row := findActiveUser(login.User, contentMatrix); row != nil && !utils.IsPasswordHash(row[4]) {
+		entries = 2
+	}
+	err = checkJournalSpace(sb, diskPath, part, entries)
+	if err != nil {
+		return err
+	}
+	hash, err := upgradePassword(sb, diskPath, part, login.User, login.Password, contentMatrix)
+	if err != nil {
+		return err
+	}
+	session.IdPartition = login.Id
+	session.User = login.User
+	session.WorkingDir = "/"
+	err = setUpIDs(session, login.User, contentMatrix)
 	if err != nil {
 		return err
 	}
@@ -109,12 +106,17 @@ func CommandLogin(login *LOGIN) error {
 				I_date:      float32(time.Now().Unix()),
 			},
 		}
-		fullContent := fmt.Sprintf("%s/%s/%s", login.Id, login.User, login.Password)
+		// El password no se guarda en el journal
+		fullContent := fmt.Sprintf("%s/%s", login.Id, login.User)
 		copy(journalDirectory.J_content.I_content[:], fullContent)
 		err = sb.AddJournal(journalDirectory, diskPath, int32(part.Part_start+int32(binary.Size(structures.SuperBlock{}))))
 		if err != nil {
 			return err
 		}
+		// Va despues del login para que recovery lo repita con la sesion del usuario y no deje el password en texto plano
+		if hash != "" {
+			return journalPasswd(sb, diskPath, part, login.User, hash)
+		}
 	}
 	return nil
 }
@@ -144,13 +146,31 @@ func validateInformation(user, password string, matrix [][]string) bool {
 		if row[1] != "U" {
 			continue
 		}
-		if row[3] == user && row[4] == password {
+		if row[3] == user && utils.CheckPassword(row[4], password) {
 			return true
 		}
 	}
 	return false
 }
 
+// upgradePassword cambia el password en texto plano de un users.txt viejo por su hash y lo devuelve, vacio si no habia que cambiarlo
+func upgradePassword(sb *structures.SuperBlock, diskPath string, part *structures.PARTITION, user, password string, matrix [][]string) (string, error) {
+	row := findActiveUser(user, matrix)
+	if row == nil || utils.IsPasswordHash(row[4]) || row[4] != password {
+		return "", nil
+	}
+	hash, err := utils.HashPassword(password)
+	if err != nil {
+		return "", err
+	}
+	row[4] = hash
+	err = OverrideUserstxt(sb, diskPath, reformUserstxt(matrix))
+	if err != nil {
+		return "", err
+	}
+	return hash, sb.Serialize(diskPath, int64(part.Part_start))
+}
+
 func getContentMatrixUsers(contentUsers string) [][]string {
 	contentSplitedByEnters := splitContent(contentUsers, "\n")
 	contentSplitedByEnters = contentSplitedByEnters[:len(contentSplitedByEnters)-1]
@@ -161,7 +181,7 @@ func getContentMatrixUsers(contentUsers string) [][]string {
 	return contentMatrix
 }
 
-func setUpIDs(userName string, matrix [][]string) error {
+func setUpIDs(session *stores.Session, userName string, matrix [][]string) error {
 	var nameGroup string
 	for _, row := range matrix {
 		if row[1] != "U" {
@@ -172,7 +192,7 @@ func setUpIDs(userName string, matrix [][]string) error {
 			if err != nil {
 				return err
 			}
-			utils.LogedUserID = int32(num)
+			session.UserID = int32(num)
 
 			nameGroup = row[2]
 			break
@@ -187,7 +207,7 @@ func setUpIDs(userName string, matrix [][]string) error {
 			if err != nil {
 				return err
 			}
-			utils.LogedUserGroupID = int32(num)
+			session.GroupID = int32(num)
 			break
 		}
 	}
@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"encoding/binary"
+	"server/lexer"
+	"server/stores"
+	"server/structures"
+	"time"
+)
+
+var logoutParams = lexer.Schema{}
+
+type LogoutResult struct {
+	User string `json:"user"`
+	ID   string `json:"id"`
+}
+
+func (result LogoutResult) String() string {
+	return "LOGOUT"
+}
+
+func init() {
+	Register(Command{
+		Name:        "logout",
+		Description: "Cierra la sesion actual",
+		Params:      logoutParams,
+		Run:         ParseLogout,
+	})
+}
+
+func ParseLogout(session *stores.Session, tokens []string) (Result, error) {
+	_, err := logoutParams.Parse(tokens)
+	if err != nil {
+		return nil, err
+	}
+	result := LogoutResult{User: session.User, ID: session.IdPartition}
+
+	err = commandLogout(session)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func commandLogout(session *stores.Session) error {
+	if session.IdPartition == "" {
+		return structures.NewError(structures.ErrPermission, "no hay sesion iniciada como para hacer un logout")
+	}
+	temp := session.IdPartition
+	*session = stores.Session{}
+
+	sb, part, diskPath, err := stores.GetMountedPartitionSuperblock(temp)
+	if err != nil {
+		return err
+	}
+	if sb.IsExt3() {
+		journalDirectory := &structures.Journal{
+			J_next: -1,
+			J_content: structures.Information{
+				I_operation: [10]byte{'l', 'o', 'g', 'o', 'u', 't'},
+				I_path:      [74]byte{},
+				I_content:   [64]byte{},
+				I_date:      float32(time.Now().Unix()),
+			},
+		}
+		err = sb.AddJournal(journalDirectory, diskPath, int32(part.Part_start+int32(binary.Size(structures.SuperBlock{}))))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
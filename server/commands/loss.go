@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"fmt"
+	"server/lexer"
+	"server/stores"
+	"server/structures"
+)
+
+type LOSS struct {
+	id string
+}
+
+type LossResult struct {
+	ID string `json:"id"`
+}
+
+func (result LossResult) String() string {
+	return fmt.Sprintf("LOSS: %s perdida simulada exitosamente", result.ID)
+}
+
+var lossParams = lexer.Schema{
+	{Name: "id", Required: true, Description: "Id de la particion montada"},
+}
+
+func init() {
+	Register(Command{
+		Name:        "loss",
+		Description: "Simula la perdida de un sistema de archivos EXT3",
+		Params:      lossParams,
+		Run:         ParseLoss,
+	})
+}
+
+func ParseLoss(session *stores.Session, tokens []string) (Result, error) {
+	args, err := lossParams.Parse(tokens)
+	if err != nil {
+		return nil, err
+	}
+	cmd := &LOSS{id: args.String("id")}
+
+	err = commandLoss(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return LossResult{ID: cmd.id}, nil
+}
+
+func commandLoss(loss *LOSS) error {
+	sb, _, diskPath, err := stores.GetMountedPartitionSuperblock(loss.id)
+	if err != nil {
+		return err
+	}
+	if !sb.IsExt3() {
+		return structures.NewError(structures.ErrInvalid, "loss solo se puede aplicar a particiones EXT3")
+	}
+	// El superbloque y el journal se conservan para poder usar recovery
+	return wipeFilesystemArea(sb, diskPath)
+}
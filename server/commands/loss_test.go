@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"server/stores"
+	"server/structures"
+	"testing"
+)
+
+// loss deja en ceros desde el bitmap de inodos hasta el ultimo bloque y el resto del disco, con el superbloque
+// y el journal, queda igual
+func TestLoss(t *testing.T) {
+	newTestState(t)
+	session := &stores.Session{}
+	run(t, session,
+		"mkdisk -size=3 -unit=M",
+		"fdisk -size=500 -unit=K -driveletter=A -name=P1",
+		"fdisk -size=500 -unit=K -driveletter=A -name=P2",
+		"mount -driveletter=A -name=P1",
+		"mount -driveletter=A -name=P2",
+		"mkfs -id=A105 -fs=3fs",
+		"mkfs -id=A205 -fs=2fs",
+		"login -user=root -pass=123 -id=A105",
+		"mkdir -r -path=/docs/a",
+		"mkfile -path=/docs/a/f.txt -size=1500",
+	)
+	sb, _, diskPath, err := stores.GetMountedPartitionSuperblock("A105")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start, end := sb.S_bm_inode_start, sb.S_block_start+sb.TotalBlocks()*sb.S_block_size
+	before, err := os.ReadFile(diskPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	run(t, session, "loss -id=A105")
+
+	after, err := os.ReadFile(diskPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(after) != len(before) {
+		t.Fatalf("el disco media %d bytes y ahora mide %d", len(before), len(after))
+	}
+	if !bytes.Equal(after[start:end], make([]byte, end-start)) {
+		t.Error("quedan datos entre el bitmap de inodos y el final de los bloques")
+	}
+	if !bytes.Equal(after[:start], before[:start]) || !bytes.Equal(after[end:], before[end:]) {
+		t.Error("loss modifico el superbloque, el journal u otra parte del disco")
+	}
+
+	if _, err := tryRun(session, "loss -id=A205"); !errors.Is(err, structures.ErrInvalid) {
+		t.Errorf("loss sobre EXT2 deberia fallar con ErrInvalid y llego %v", err)
+	}
+}
@@ -2,13 +2,11 @@ package commands
 
 import (
 	"encoding/binary"
-	"errors"
 	"fmt"
-	"regexp"
+	"server/lexer"
 	stores "server/stores"
 	structures "server/structures"
 	utils "server/utils"
-	"strings"
 	"time"
 )
 
@@ -17,65 +15,58 @@ type MKDIR struct {
 	p    bool
 }
 
-func ParseMkdir(tokens []string) (string, error) {
-	cmd := &MKDIR{}
+type MkdirResult struct {
+	Path string `json:"path"`
+}
 
-	args := strings.Join(tokens, " ")
-	re := regexp.MustCompile(`-path="[^"]+"|-path=[^\s]+|-r`)
-	matches := re.FindAllString(args, -1)
+func (result MkdirResult) String() string {
+	return fmt.Sprintf("MKDIR: Directorio %s creado correctamente.", result.Path)
+}
 
-	if len(matches) != len(tokens) {
-		for _, token := range tokens {
-			if !re.MatchString(token) {
-				return "", fmt.Errorf("parámetro inválido: %s", token)
-			}
-		}
-	}
+var mkdirParams = lexer.Schema{
+	{Name: "path", Required: true, Path: true, Description: "Ruta de la carpeta"},
+	{Name: "r", Kind: lexer.Flag, Description: "Crea las carpetas padre que no existan"},
+}
 
-	for _, match := range matches {
-		kv := strings.SplitN(match, "=", 2)
-		key := strings.ToLower(kv[0])
-
-		switch key {
-		case "-path":
-			if len(kv) != 2 {
-				return "", fmt.Errorf("formato de parámetro inválido: %s", match)
-			}
-			value := kv[1]
-			if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
-				value = strings.Trim(value, "\"")
-			}
-			cmd.path = value
-		case "-r":
-			cmd.p = true
-		default:
-			return "", fmt.Errorf("parámetro desconocido: %s", key)
-		}
-	}
+func init() {
+	Register(Command{
+		Name:        "mkdir",
+		Description: "Crea una carpeta",
+		Params:      mkdirParams,
+		Run:         ParseMkdir,
+	})
+}
 
-	if cmd.path == "" {
-		return "", errors.New("faltan parámetros requeridos: -path")
+func ParseMkdir(session *stores.Session, tokens []string) (Result, error) {
+	args, err := mkdirParams.Parse(tokens)
+	if err != nil {
+		return nil, err
 	}
+	cmd := &MKDIR{path: resolvePath(session, args.String("path")), p: args.Flag("r")}
 
-	err := CommandMkdir(cmd)
+	err = CommandMkdir(session, cmd)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return fmt.Sprintf("MKDIR: Directorio %s creado correctamente.", cmd.path), nil
+	return MkdirResult{Path: cmd.path}, nil
 }
 
 // Aquí debería de estar logeado un usuario, por lo cual el usuario debería tener consigo el id de la partición
 // En este caso el ID va a estar quemado
 // var idPartition = "361A"
 
-func CommandMkdir(mkdir *MKDIR) error {
-	partitionSuperblock, mountedPartition, partitionPath, err := stores.GetMountedPartitionSuperblock(stores.LogedIdPartition)
+func CommandMkdir(session *stores.Session, mkdir *MKDIR) error {
+	partitionSuperblock, mountedPartition, partitionPath, err := stores.GetMountedPartitionSuperblock(session.IdPartition)
 	if err != nil {
 		return fmt.Errorf("error al obtener la partición montada: %w", err)
 	}
+	err = checkJournalSpace(partitionSuperblock, partitionPath, mountedPartition, 1)
+	if err != nil {
+		return err
+	}
 
-	err = createDirectory(mkdir.path, partitionSuperblock, partitionPath, mountedPartition, mkdir.p)
+	err = createDirectory(session, mkdir.path, partitionSuperblock, partitionPath, mountedPartition, mkdir.p)
 	if err != nil {
 		err = fmt.Errorf("error al crear el directorio: %w", err)
 	}
@@ -83,11 +74,11 @@ func CommandMkdir(mkdir *MKDIR) error {
 	return err
 }
 
-func createDirectory(dirPath string, sb *structures.SuperBlock, partitionPath string, mountedPartition *structures.PARTITION, flag bool) error {
+func createDirectory(session *stores.Session, dirPath string, sb *structures.SuperBlock, partitionPath string, mountedPartition *structures.PARTITION, flag bool) error {
 
 	parentDirs, destDir := utils.GetParentDirectories(dirPath)
 
-	err := sb.CreateFolder(partitionPath, parentDirs, destDir, flag)
+	err := sb.CreateFolder(partitionPath, parentDirs, destDir, flag, session.UserID, session.GroupID)
 	if err != nil {
 		return fmt.Errorf("error al crear el directorio: %w", err)
 	}
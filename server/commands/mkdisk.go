@@ -1,18 +1,14 @@
 package commands
 
 import (
-	"errors"
 	"fmt"
 	"math/rand"
 	"os"
+	"server/lexer"
 	stores "server/stores"
 	structures "server/structures"
 	utils "server/utils"
-	"strconv"
 	"time"
-
-	"regexp"
-	"strings"
 )
 
 type MKDISK struct {
@@ -22,68 +18,52 @@ type MKDISK struct {
 	path string
 }
 
-func ParseMkdisk(tokens []string) (string, error) {
-	cmd := &MKDISK{}
-	letterDisk := utils.GetLetterToDisk()
-	cmd.path = stores.GetPathDisk(letterDisk)
-	// Establecer "M" como unidad por defecto
-	cmd.unit = "M"
-
-	args := strings.Join(tokens, " ")
-	re := regexp.MustCompile(`-size=\d+|-unit=[kKmM]|-fit=[bBfFwW]{2}`)
-	matches := re.FindAllString(args, -1)
-	for _, match := range matches {
-		kv := strings.SplitN(match, "=", 2)
-		if len(kv) != 2 {
-			return "", fmt.Errorf("formato de parametro invalido: %s", match)
-		}
-		key, value := strings.ToLower(kv[0]), kv[1]
-
-		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
-			value = strings.Trim(value, "\"")
-		}
-
-		switch key {
-		case "-size":
-			size, err := strconv.Atoi(value)
-			if err != nil {
-				return "", errors.New("el tamano debe ser numero entero positivo")
-			}
-			cmd.size = size
-		case "-unit":
-			value = strings.ToUpper(value)
-			if value != "K" && value != "M" {
-				return "", errors.New("la unidd debe ser K o M")
-			}
-			cmd.unit = value
-		case "-fit":
-			value = strings.ToUpper(value)
-			if value != "BF" && value != "FF" && value != "WF" {
-				return "", errors.New("el ajuste debe ser BF, FF o WF")
-			}
-			cmd.fit = value
-		default:
-			return "", fmt.Errorf("parametro desconocido: %s", key)
-		}
-	}
+type MkdiskResult struct {
+	Disk string `json:"disk"`
+	Path string `json:"path"`
+}
+
+func (result MkdiskResult) String() string {
+	return fmt.Sprintf("MKDISK: %s creado exitosamente", result.Path)
+}
 
-	if cmd.size == 0 {
-		return "", errors.New("faltan parametros requeridos: -size")
+var mkdiskParams = lexer.Schema{
+	{Name: "size", Kind: lexer.Int, Required: true, Description: "Tamano del disco"},
+	{Name: "unit", Default: "M", Values: []string{"K", "M"}, Description: "Unidad de -size"},
+	{Name: "fit", Default: "FF", Values: []string{"BF", "FF", "WF"}, Description: "Ajuste con el que se crean las particiones"},
+}
+
+func init() {
+	Register(Command{
+		Name:        "mkdisk",
+		Description: "Crea un disco virtual con su MBR",
+		Params:      mkdiskParams,
+		Run:         ParseMkdisk,
+	})
+}
+
+func ParseMkdisk(session *stores.Session, tokens []string) (Result, error) {
+	args, err := mkdiskParams.Parse(tokens)
+	if err != nil {
+		return nil, err
 	}
-	// Remover la asignación condicional ya que cmd.unit ya tiene "M" por defecto
-	if cmd.fit == "" {
-		cmd.fit = "FF"
+	cmd := &MKDISK{size: args.Int("size"), unit: args.String("unit"), fit: args.String("fit")}
+	if cmd.size <= 0 {
+		return nil, structures.NewError(structures.ErrInvalid, "el tamano debe ser numero entero positivo")
 	}
-	err := commandMkdisk(cmd)
+	letterDisk := stores.NextDiskLetter()
+	cmd.path = stores.GetPathDisk(letterDisk)
+	err = commandMkdisk(cmd)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	// Usar la función de debug para agregar el disco
 	name := utils.GetNameByPath(cmd.path)
 	stores.AddLoadedDisk(name, cmd.path)
+	stores.SaveState()
 
-	return fmt.Sprintf("MKDISK: %s creado exitosamente", cmd.path), nil
+	return MkdiskResult{Disk: letterDisk, Path: cmd.path}, nil
 
 }
 
@@ -2,14 +2,12 @@ package commands
 
 import (
 	"encoding/binary"
-	"errors"
 	"fmt"
 	"os"
-	"regexp"
+	"server/lexer"
 	"server/stores"
 	"server/structures"
 	"server/utils"
-	"strconv"
 	"strings"
 	"time"
 )
@@ -21,91 +19,64 @@ type MKFILE struct {
 	cont string
 }
 
-func ParseMkfile(tokens []string) (string, error) {
-	cmd := &MKFILE{}
-	cmd.size = 0
-	args := strings.Join(tokens, " ")
-	re := regexp.MustCompile(`-size=-?\d+|-r|-path="[^"]+"|-path=[^\s]+|-cont="[^"]+"|-cont=[^\s]+`)
-	matches := re.FindAllString(args, -1)
-
-	for _, match := range matches {
+type MkfileResult struct {
+	Path string `json:"path"`
+}
 
-		if match == "-r" {
-			cmd.r = true
-			continue
-		}
+func (result MkfileResult) String() string {
+	return fmt.Sprintf("MKFILE: %s creado exitosamente", result.Path)
+}
 
-		kv := strings.SplitN(match, "=", 2)
-		if len(kv) != 2 {
-			return "", fmt.Errorf("formato de parametro invalido: %s", match)
-		}
-		key, value := strings.ToLower(kv[0]), kv[1]
+var mkfileParams = lexer.Schema{
+	{Name: "path", Required: true, Path: true, Description: "Ruta del archivo"},
+	{Name: "r", Kind: lexer.Flag, Description: "Crea las carpetas padre que no existan"},
+	{Name: "size", Kind: lexer.Int, Description: "Tamano del contenido generado con 0123456789"},
+	{Name: "cont", Description: "Archivo de la computadora con el contenido"},
+}
 
-		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
-			value = strings.Trim(value, "\"")
-		}
+func init() {
+	Register(Command{
+		Name:        "mkfile",
+		Description: "Crea un archivo",
+		Params:      mkfileParams,
+		Run:         ParseMkfile,
+	})
+}
 
-		switch key {
-		case "-size":
-			size, err := strconv.Atoi(value)
-			if err != nil {
-				return "", errors.New("el tamano debe ser numero entero positivo")
-			}
-			if size <= -1 {
-				return "", errors.New("no puede ser un numero negativo")
-			}
-			cmd.size = size
-		case "-path":
-			if value == "" {
-				return "", errors.New("el path no puede estar vacio")
-			}
-			cmd.path = value
-		case "-cont":
-			if value == "" {
-				return "", errors.New("el cont no puede estar vacio")
-			}
-			cmd.cont = value
-		}
+func ParseMkfile(session *stores.Session, tokens []string) (Result, error) {
+	args, err := mkfileParams.Parse(tokens)
+	if err != nil {
+		return nil, err
 	}
-
-	if cmd.path == "" {
-		return "", errors.New("faltan parametros requeridos: -path")
+	cmd := &MKFILE{path: resolvePath(session, args.String("path")), r: args.Flag("r"), size: args.Int("size"), cont: args.String("cont")}
+	if cmd.size < 0 {
+		return nil, structures.NewError(structures.ErrInvalid, "no puede ser un numero negativo")
 	}
-
-	err := CommandMkfile(cmd)
+	err = CommandMkfile(session, cmd)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return fmt.Sprintf("MKFILE: %s creado exitosamente", cmd.path), nil
+	return MkfileResult{Path: cmd.path}, nil
 }
 
-func CommandMkfile(mkfile *MKFILE) error {
+func CommandMkfile(session *stores.Session, mkfile *MKFILE) error {
 
-	partitionSuperblock, mountedPartition, partitionPath, err := stores.GetMountedPartitionSuperblock(stores.LogedIdPartition)
+	partitionSuperblock, mountedPartition, partitionPath, err := stores.GetMountedPartitionSuperblock(session.IdPartition)
 	if err != nil {
 		return err
 	}
-	err = createFile(partitionPath, partitionSuperblock, mountedPartition, mkfile.path, mkfile.r, mkfile.size, mkfile.cont)
+	err = createFile(session, partitionPath, partitionSuperblock, mountedPartition, mkfile.path, mkfile.r, mkfile.size, mkfile.cont)
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
-func createFile(diskPath string, sb *structures.SuperBlock, partition *structures.PARTITION, filePath string, createDir bool, sizeFile int, pathFileToGetInfo string) error {
+func createFile(session *stores.Session, diskPath string, sb *structures.SuperBlock, partition *structures.PARTITION, filePath string, createDir bool, sizeFile int, pathFileToGetInfo string) error {
 	var contentToWrite string
 	if sizeFile < 0 {
-		return fmt.Errorf("no puede venir un size negativo")
-	}
-	if createDir {
-		position := strings.LastIndex(filePath, "/")
-		dirPath := filePath[:position]
-		parentDirs, destDir := utils.GetParentDirectories(dirPath)
-		err := sb.CreateFolder(diskPath, parentDirs, destDir, true)
-		if err != nil {
-			return err
-		}
+		return structures.Errorf(structures.ErrInvalid, "no puede venir un size negativo")
 	}
 	if pathFileToGetInfo != "" {
 		fileContent, err := os.ReadFile(pathFileToGetInfo)
@@ -113,49 +84,35 @@ func createFile(diskPath string, sb *structures.SuperBlock, partition *structure
 			return err
 		}
 		contentToWrite = string(fileContent)
-		parentDirs, destDir := utils.GetParentDirectories(filePath)
-		err = sb.CreateFile(diskPath, 0, parentDirs, destDir, string(fileContent), int32(len(fileContent)), false)
-		if err != nil {
-			return err
-		}
 	} else if sizeFile > 0 {
-		content := getStringContent(sizeFile)
-		contentToWrite = content
-		parentDirs, destDir := utils.GetParentDirectories(filePath)
-		err := sb.CreateFile(diskPath, 0, parentDirs, destDir, content, int32(sizeFile), false)
+		contentToWrite = getStringContent(sizeFile)
+	}
+
+	journalStart := partition.Part_start + int32(binary.Size(structures.SuperBlock{}))
+	contentList := journalChunks(contentToWrite)
+	if sb.IsExt3() {
+		err := sb.CheckJournalSpace(diskPath, journalStart, len(contentList))
 		if err != nil {
 			return err
 		}
-	} else {
-		contentToWrite = ""
-		parentDirs, destDir := utils.GetParentDirectories(filePath)
-		err := sb.CreateFile(diskPath, 0, parentDirs, destDir, "", int32(0), false)
+	}
+	if createDir {
+		position := strings.LastIndex(filePath, "/")
+		dirPath := filePath[:position]
+		parentDirs, destDir := utils.GetParentDirectories(dirPath)
+		err := sb.CreateFolder(diskPath, parentDirs, destDir, true, session.UserID, session.GroupID)
 		if err != nil {
 			return err
 		}
 	}
+	parentDirs, destDir := utils.GetParentDirectories(filePath)
+	err := sb.CreateFile(diskPath, 0, parentDirs, destDir, contentToWrite, session.UserID, session.GroupID)
+	if err != nil {
+		return err
+	}
 
 	if sb.IsExt3() {
-		if contentToWrite != "" {
-			contentList := utils.SplitStringIntoChunks(contentToWrite)
-			for _, content := range contentList {
-				journalDirectory := &structures.Journal{
-					J_next: -1,
-					J_content: structures.Information{
-						I_operation: [10]byte{'m', 'k', 'f', 'i', 'l', 'e'},
-						I_path:      [74]byte{},
-						I_content:   [64]byte{},
-						I_date:      float32(time.Now().Unix()),
-					},
-				}
-				copy(journalDirectory.J_content.I_path[:], filePath)
-				copy(journalDirectory.J_content.I_content[:], content)
-				err := sb.AddJournal(journalDirectory, diskPath, int32(partition.Part_start+int32(binary.Size(structures.SuperBlock{}))))
-				if err != nil {
-					return err
-				}
-			}
-		} else {
+		for _, content := range contentList {
 			journalDirectory := &structures.Journal{
 				J_next: -1,
 				J_content: structures.Information{
@@ -166,21 +123,31 @@ func createFile(diskPath string, sb *structures.SuperBlock, partition *structure
 				},
 			}
 			copy(journalDirectory.J_content.I_path[:], filePath)
-			copy(journalDirectory.J_content.I_content[:], contentToWrite)
-			err := sb.AddJournal(journalDirectory, diskPath, int32(partition.Part_start+int32(binary.Size(structures.SuperBlock{}))))
+			copy(journalDirectory.J_content.I_content[:], content)
+			err := sb.AddJournal(journalDirectory, diskPath, journalStart)
 			if err != nil {
 				return err
 			}
 		}
 	}
 
-	err := sb.Serialize(diskPath, int64(partition.Part_start))
+	err = sb.Serialize(diskPath, int64(partition.Part_start))
 	if err != nil {
 		return err
 	}
 	return nil
 }
 
+// journalChunks parte el contenido en entradas de 64 bytes del journal. Si el ultimo queda lleno o no hay contenido
+// se agrega uno vacio para que recovery sepa donde termina el archivo
+func journalChunks(content string) []string {
+	chunks := utils.SplitStringIntoChunks(content)
+	if len(content)%64 == 0 {
+		chunks = append(chunks, "")
+	}
+	return chunks
+}
+
 func getStringContent(size int) string {
 	var buffer string = ""
 	numeros := "0123456789"
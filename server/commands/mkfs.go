@@ -2,13 +2,11 @@ package commands
 
 import (
 	"encoding/binary"
-	"errors"
 	"fmt"
 	"math"
-	"regexp"
+	"server/lexer"
 	stores "server/stores"
 	structures "server/structures"
-	"strings"
 	"time"
 )
 
@@ -18,57 +16,42 @@ type MKFS struct {
 	fs  string
 }
 
-func ParseMkfs(tokens []string) (string, error) {
-	cmd := &MKFS{}
+type MkfsResult struct {
+	ID string `json:"id"`
+	FS string `json:"fs"`
+}
 
-	args := strings.Join(tokens, " ")
-	re := regexp.MustCompile(`-id=[a-zA-Z0-9]+|-type=[fFuUlL]+|-fs=[23]fs`)
-	matches := re.FindAllString(args, -1)
-	for _, match := range matches {
-		kv := strings.SplitN(match, "=", 2)
-		if len(kv) != 2 {
-			return "", fmt.Errorf("formato de parametro invalido: %s", match)
-		}
-		key, value := strings.ToLower(kv[0]), kv[1]
+func (result MkfsResult) String() string {
+	return fmt.Sprintf("MKFS: %s formateado exitosamente", result.ID)
+}
 
-		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
-			value = strings.Trim(value, "\"")
-		}
+var mkfsParams = lexer.Schema{
+	{Name: "id", Required: true, Description: "Id de la particion montada"},
+	{Name: "type", Values: []string{"full"}, Description: "Formateo completo"},
+	{Name: "fs", Default: "2fs", Values: []string{"2fs", "3fs"}, Description: "Sistema de archivos"},
+}
 
-		switch key {
-		case "-id":
-			if value == "" {
-				return "", errors.New("el id no puede estar vacio")
-			}
-			cmd.id = value
-		case "-type":
-			value = strings.ToLower(value)
-			if value != "full" {
-				return "", errors.New("solo se acepta el tipo full")
-			}
-			cmd.typ = true
-		case "-fs":
-			if value != "2fs" && value != "3fs" {
-				return "", errors.New("el sistema de archivos debe ser 2fs o 3fs")
-			}
-
-			cmd.fs = value
-		default:
-			return "", fmt.Errorf("parametro desconocido: %s", key)
-		}
-	}
-	if cmd.id == "" {
-		return "", errors.New("faltan parametros requeridos: -id")
-	}
-	if cmd.fs == "" {
-		cmd.fs = "2fs"
+func init() {
+	Register(Command{
+		Name:        "mkfs",
+		Description: "Formatea una particion montada con EXT2 o EXT3",
+		Params:      mkfsParams,
+		Run:         ParseMkfs,
+	})
+}
+
+func ParseMkfs(session *stores.Session, tokens []string) (Result, error) {
+	args, err := mkfsParams.Parse(tokens)
+	if err != nil {
+		return nil, err
 	}
-	err := commandMkfs(cmd)
+	cmd := &MKFS{id: args.String("id"), typ: args.Has("type"), fs: args.String("fs")}
+	err = commandMkfs(cmd)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return fmt.Sprintf("MKFS: %s formateado exitosamente", cmd.id), nil
+	return MkfsResult{ID: cmd.id, FS: cmd.fs}, nil
 }
 
 func commandMkfs(mkfs *MKFS) error {
@@ -2,13 +2,10 @@ package commands
 
 import (
 	"encoding/binary"
-	"errors"
 	"fmt"
-	"regexp"
+	"server/lexer"
 	"server/stores"
 	"server/structures"
-	"server/utils"
-	"strings"
 	"time"
 )
 
@@ -16,66 +13,65 @@ type MKGRP struct {
 	name string
 }
 
-func ParseMkgrp(tokens []string) (string, error) {
-	cmd := &MKGRP{}
-
-	args := strings.Join(tokens, " ")
-	re := regexp.MustCompile(`-name="[^"]+"|-name=[^\s]+`)
-	matches := re.FindAllString(args, -1)
+type MkgrpResult struct {
+	Group string `json:"group"`
+}
 
-	for _, match := range matches {
-		kv := strings.SplitN(match, "=", 2)
-		if len(kv) != 2 {
-			return "", fmt.Errorf("formato de parametro invalido: %s", match)
-		}
-		key, value := strings.ToLower(kv[0]), kv[1]
+func (result MkgrpResult) String() string {
+	return fmt.Sprintf("MKGRP: grupo %s creado exitosamente", result.Group)
+}
 
-		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
-			value = strings.Trim(value, "\"")
-		}
+var mkgrpParams = lexer.Schema{
+	{Name: "name", Required: true, Description: "Nombre del grupo"},
+}
 
-		switch key {
-		case "-name":
-			if value == "" {
-				return "", errors.New("el nombre no puede venir vacio")
-			}
-			cmd.name = value
-		default:
-			return "", fmt.Errorf("parametro desconocido: %s", key)
-		}
-	}
+func init() {
+	Register(Command{
+		Name:        "mkgrp",
+		Description: "Crea un grupo, solo root",
+		Params:      mkgrpParams,
+		Run:         ParseMkgrp,
+	})
+}
 
-	if cmd.name == "" {
-		return "", errors.New("parametro obligatorio: -name")
+func ParseMkgrp(session *stores.Session, tokens []string) (Result, error) {
+	args, err := mkgrpParams.Parse(tokens)
+	if err != nil {
+		return nil, err
 	}
+	cmd := &MKGRP{name: args.String("name")}
 
-	err := CommmandMkgrp(cmd)
+	err = CommmandMkgrp(session, cmd)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	return fmt.Sprintf("MKGRP: grupo %s creado exitosamente", cmd.name), nil
+	return MkgrpResult{Group: cmd.name}, nil
 }
 
-func CommmandMkgrp(mkgrp *MKGRP) error {
-	if stores.LogedIdPartition == "" {
-		return errors.New("no hay sesion activa")
+func CommmandMkgrp(session *stores.Session, mkgrp *MKGRP) error {
+	if session.IdPartition == "" {
+		return structures.NewError(structures.ErrPermission, "no hay sesion activa")
 	}
-	if stores.LogedUser != "root" {
-		return errors.New("este comando solo lo puede ejecutar el usuario root")
+	if !session.IsRoot() {
+		return structures.NewError(structures.ErrPermission, "este comando solo lo puede ejecutar el usuario root")
 	}
-	contentUsersTxt, err := getContetnUsersTxt(stores.LogedIdPartition)
+	contentUsersTxt, err := getContetnUsersTxt(session.IdPartition)
 	if err != nil {
 		return err
 	}
 	contentMatrix := getContentMatrixUsers(contentUsersTxt)
 	outcome := soleNameGroup(mkgrp.name, contentMatrix)
 	if !outcome {
-		return errors.New("el nombre de grupo ya esta siendo utilizado")
+		return structures.NewError(structures.ErrExists, "el nombre de grupo ya esta siendo utilizado")
 	}
 	neoGroupID := getNeoNumber("G", contentMatrix)
 
 	contentUsersTxt += fmt.Sprintf("%d,G,%s\n", neoGroupID, mkgrp.name)
-	partitionSuperblock, mountedPartition, partitionPath, err := stores.GetMountedPartitionSuperblock(stores.LogedIdPartition)
+	partitionSuperblock, mountedPartition, partitionPath, err := stores.GetMountedPartitionSuperblock(session.IdPartition)
+	if err != nil {
+		return err
+	}
+	err = checkJournalSpace(partitionSuperblock, partitionPath, mountedPartition, 1)
 	if err != nil {
 		return err
 	}
@@ -138,56 +134,6 @@ func getNeoNumber(tipo string, matrix [][]string) int {
 }
 
 func OverrideUserstxt(sb *structures.SuperBlock, diskPath, content string) error {
-	inode := &structures.Inode{}
-	err := inode.Deserialize(diskPath, int64(sb.S_inode_start+sb.S_inode_size))
-	if err != nil {
-		return err
-	}
-	contentChunks := utils.SplitStringIntoChunks(content)
-	for i, indexFileBlock := range inode.I_block {
-		if len(contentChunks) == 0 {
-			break
-		}
-		if indexFileBlock != -1 {
-			fileBlock := &structures.FileBlock{}
-			err := fileBlock.Deserialize(diskPath, int64(sb.S_block_start+(indexFileBlock*sb.S_block_size)))
-			if err != nil {
-				return err
-			}
-			copy(fileBlock.B_content[:], []byte(contentChunks[0]))
-			contentChunks = utils.RemoveElement(contentChunks, 0)
-			fileBlock.Serialize(diskPath, int64(sb.S_block_start+(indexFileBlock*sb.S_block_size)))
-		} else {
-			if len(contentChunks) == 0 {
-				break
-			}
-			inode.I_block[i] = sb.S_blocks_count
-			contentBlock := &structures.FileBlock{
-				B_content: [64]byte{},
-			}
-			copy(contentBlock.B_content[:], []byte(contentChunks[0]))
-			contentChunks = utils.RemoveElement(contentChunks, 0)
-			err = contentBlock.Serialize(diskPath, int64(sb.S_first_blo))
-			if err != nil {
-				return err
-			}
-
-			err = sb.UpdateBitmapBlock(diskPath)
-			if err != nil {
-				return err
-			}
-			// fmt.Println("EL CONTADOR:", sb.S_blocks_count)
-			sb.S_blocks_count++
-			// fmt.Println("EL CONTADOR NUEVO:", sb.S_blocks_count)
-			sb.S_free_blocks_count--
-			sb.S_first_blo += sb.S_block_size
-
-		}
-	}
-	inode.I_size = int32(len(content))
-	err = inode.Serialize(diskPath, int64(sb.S_inode_start+sb.S_inode_size))
-	if err != nil {
-		return err
-	}
-	return nil
+	// users.txt siempre es el inodo 1
+	return sb.ReplaceFileContent(diskPath, 1, content)
 }
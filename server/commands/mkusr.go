@@ -2,12 +2,11 @@ package commands
 
 import (
 	"encoding/binary"
-	"errors"
 	"fmt"
-	"regexp"
+	"server/lexer"
 	stores "server/stores"
 	"server/structures"
-	"strings"
+	"server/utils"
 	"time"
 )
 
@@ -15,97 +14,90 @@ type MKUSR struct {
 	user     string
 	password string
 	group    string
+	hashed   bool // recovery reenvia el hash que quedo en el journal
 }
 
-func ParseMkusr(tokens []string) (string, error) {
-	cmd := &MKUSR{}
+type MkusrResult struct {
+	User  string `json:"user"`
+	Group string `json:"group"`
+}
 
-	args := strings.Join(tokens, " ")
-	re := regexp.MustCompile(`-user="[^"]+"|-user=[^\s]+|-grp="[^"]+"|-grp=[^\s]+|-pass="[^"]+"|-pass=[^\s]+`)
-	matches := re.FindAllString(args, -1)
+func (result MkusrResult) String() string {
+	return fmt.Sprintf("MKUSR: usuario %s creado exitosamente", result.User)
+}
 
-	for _, match := range matches {
-		kv := strings.SplitN(match, "=", 2)
-		if len(kv) != 2 {
-			return "", fmt.Errorf("formato de parametro invalido: %s", match)
-		}
-		key, value := strings.ToLower(kv[0]), kv[1]
+var mkusrParams = lexer.Schema{
+	{Name: "user", Required: true, Description: "Nombre del usuario, maximo 10 caracteres"},
+	{Name: "pass", Required: true, Description: "Contrasena, maximo 10 caracteres"},
+	{Name: "grp", Required: true, Description: "Grupo del usuario"},
+}
 
-		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
-			value = strings.Trim(value, "\"")
-		}
+func init() {
+	Register(Command{
+		Name:        "mkusr",
+		Description: "Crea un usuario, solo root",
+		Params:      mkusrParams,
+		Run:         ParseMkusr,
+	})
+}
 
-		switch key {
-		case "-user":
-			if value == "" {
-				return "", errors.New("el user no puede estar vacio")
-			}
-			if len(value) > 10 {
-				return "", errors.New("el user de usuario no se puede exceder de 10 caracteres")
-			}
-			cmd.user = value
-		case "-pass":
-			if value == "" {
-				return "", errors.New("el password no puede estar vacio")
-			}
-			if len(value) > 10 {
-				return "", errors.New("el pass de usuario no se puede exceder de 10 caracteres")
-			}
-			cmd.password = value
-		case "-grp":
-			if value == "" {
-				return "", errors.New("el grp no puede estar vacio")
-			}
-			if len(value) > 10 {
-				return "", errors.New("el group de usuario no se puede exceder de 10 caracteres")
-			}
-			cmd.group = value
-		default:
-			return "", fmt.Errorf("parametro desconocido: %s", key)
-		}
+func ParseMkusr(session *stores.Session, tokens []string) (Result, error) {
+	args, err := mkusrParams.Parse(tokens)
+	if err != nil {
+		return nil, err
 	}
-
-	if cmd.password == "" {
-		return "", errors.New("faltan parametros requeridos: -pass")
+	cmd := &MKUSR{user: args.String("user"), password: args.String("pass"), group: args.String("grp")}
+	if len(cmd.user) > 10 {
+		return nil, structures.NewError(structures.ErrInvalid, "el user de usuario no se puede exceder de 10 caracteres")
 	}
-	if cmd.user == "" {
-		return "", errors.New("faltan parametros requeridos: -user")
+	if len(cmd.password) > 10 {
+		return nil, structures.NewError(structures.ErrInvalid, "el pass de usuario no se puede exceder de 10 caracteres")
 	}
-	if cmd.group == "" {
-		return "", errors.New("faltan parametros requeridos: -grp")
+	if len(cmd.group) > 10 {
+		return nil, structures.NewError(structures.ErrInvalid, "el group de usuario no se puede exceder de 10 caracteres")
 	}
 
-	err := CommandMkusr(cmd)
+	err = CommandMkusr(session, cmd)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return fmt.Sprintf("MKUSR: usuario %s creado exitosamente", cmd.user), nil
+	return MkusrResult{User: cmd.user, Group: cmd.group}, nil
 }
 
-func CommandMkusr(mkusr *MKUSR) error {
-	if stores.LogedIdPartition == "" {
-		return errors.New("no hay sesion activa")
+func CommandMkusr(session *stores.Session, mkusr *MKUSR) error {
+	if session.IdPartition == "" {
+		return structures.NewError(structures.ErrPermission, "no hay sesion activa")
 	}
-	if stores.LogedUser != "root" {
-		return errors.New("este comando solo lo puede ejecutar el usuario root")
+	if !session.IsRoot() {
+		return structures.NewError(structures.ErrPermission, "este comando solo lo puede ejecutar el usuario root")
 	}
-	contentUsersTxt, err := getContetnUsersTxt(stores.LogedIdPartition)
+	contentUsersTxt, err := getContetnUsersTxt(session.IdPartition)
 	if err != nil {
 		return err
 	}
 	contentMatrix := getContentMatrixUsers(contentUsersTxt)
 	outcome := !soleNameGroup(mkusr.group, contentMatrix)
 	if !outcome {
-		return errors.New("el grupo especificado no existe")
+		return structures.NewError(structures.ErrNotFound, "el grupo especificado no existe")
 	}
 	outcome = soleNameUser(mkusr.user, contentMatrix)
 	if !outcome {
-		return errors.New("nombre de usuario no disponible")
+		return structures.NewError(structures.ErrExists, "nombre de usuario no disponible")
+	}
+	if !mkusr.hashed {
+		mkusr.password, err = utils.HashPassword(mkusr.password)
+		if err != nil {
+			return err
+		}
 	}
 	neoUserID := getNeoNumber("U", contentMatrix)
 	contentUsersTxt += fmt.Sprintf("%d,U,%s,%s,%s\n", neoUserID, mkusr.group, mkusr.user, mkusr.password)
-	partitionSuperblock, mountedPartition, partitionPath, err := stores.GetMountedPartitionSuperblock(stores.LogedIdPartition)
+	partitionSuperblock, mountedPartition, partitionPath, err := stores.GetMountedPartitionSuperblock(session.IdPartition)
+	if err != nil {
+		return err
+	}
+	err = checkJournalSpace(partitionSuperblock, partitionPath, mountedPartition, 1)
 	if err != nil {
 		return err
 	}
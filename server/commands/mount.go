@@ -1,68 +1,58 @@
 package commands
 
 import (
-	"errors"
 	"fmt"
-	"regexp"
+	"server/lexer"
 	"server/stores"
 	"server/structures"
-	"server/utils"
-	"strings"
 )
 
 type MOUNT struct {
 	path        string
 	name        string
 	driveLetter string
+	id          string //lo asigna commandMount
 }
 
-func ParseMount(tokens []string) (string, error) {
-	cmd := &MOUNT{}
-
-	args := strings.Join(tokens, " ")
-	re := regexp.MustCompile(`-driveletter=[A-Za-z]|-name="[^"]+"|-name=[^\s]+`)
-	matches := re.FindAllString(args, -1)
-
-	for _, match := range matches {
-		kv := strings.SplitN(match, "=", 2)
-		if len(kv) != 2 {
-			return "", fmt.Errorf("formato de parametro invalido: %s", match)
-		}
-		key, value := strings.ToLower(kv[0]), kv[1]
-
-		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
-			value = strings.Trim(value, "\"")
-		}
-		switch key {
-		case "-driveletter":
-			if value == "" {
-				return "", errors.New("el driveletter no puede estar vacio")
-			}
-			cmd.path = value
-		case "-name":
-			if value == "" {
-				return "", errors.New("el nombre no puede estar vacio")
-			}
-			cmd.name = value
-		default:
-			return "", fmt.Errorf("parametro desconocido: %s", key)
-		}
-	}
+type MountResult struct {
+	ID   string `json:"id"`
+	Disk string `json:"disk"`
+	Name string `json:"name"`
+}
 
-	if cmd.path == "" {
-		return "", errors.New("faltan parámetros requeridos: -driveletter")
-	}
-	if cmd.name == "" {
-		return "", errors.New("faltan parámetros requeridos: -name")
+func (result MountResult) String() string {
+	return fmt.Sprintf("MOUNT: %s montada exitosamente", result.Name)
+}
+
+var mountParams = lexer.Schema{
+	{Name: "driveletter", Kind: lexer.Letter, Required: true, Description: "Letra del disco"},
+	{Name: "name", Required: true, Description: "Nombre de la particion"},
+}
+
+func init() {
+	Register(Command{
+		Name:        "mount",
+		Description: "Monta una particion y le asigna un id",
+		Params:      mountParams,
+		Run:         ParseMount,
+	})
+}
+
+func ParseMount(session *stores.Session, tokens []string) (Result, error) {
+	args, err := mountParams.Parse(tokens)
+	if err != nil {
+		return nil, err
 	}
-	cmd.driveLetter = strings.ToUpper(cmd.path)
+	cmd := &MOUNT{driveLetter: args.String("driveletter"), name: args.String("name")}
 	cmd.path = stores.GetPathDisk(cmd.driveLetter)
-	err := commandMount(cmd)
+
+	err = commandMount(cmd)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
+	stores.SaveState()
 
-	return fmt.Sprintf("MOUNT: %s montada exitosamente", cmd.name), nil
+	return MountResult{ID: cmd.id, Disk: cmd.driveLetter, Name: cmd.name}, nil
 }
 func commandMount(mount *MOUNT) error {
 	var mbr structures.MBR
@@ -73,26 +63,27 @@ func commandMount(mount *MOUNT) error {
 	}
 	partition, indexPartition := mbr.GetPartitionByName(mount.name)
 	if partition == nil {
-		return errors.New("la particion no existe")
+		return mountLogicalPartition(&mbr, mount)
 	}
 
 	// fmt.Println("\nPartición disponible:")
 	// partition.PrintPartition()
 
 	if partition.Part_status[0] == '1' {
-		return errors.New("no se puede montar una particion ya montada")
+		return structures.NewError(structures.ErrExists, "no se puede montar una particion ya montada")
 	}
 
 	if partition.Part_type[0] == 'E' {
-		return errors.New("no se puede montar una particion extendida")
+		return structures.NewError(structures.ErrInvalid, "no se puede montar una particion extendida")
 	}
 
 	idPartition, err := generatePartitionID(mount)
 	if err != nil {
 		return err
 	}
+	mount.id = idPartition
 
-	stores.MountedPartitions[idPartition] = mount.path
+	stores.AddMountedPartition(idPartition, mount.path)
 	partition.MountPartition(indexPartition, idPartition)
 
 	// fmt.Println("\nPartición creada (modificada):")
@@ -107,8 +98,30 @@ func commandMount(mount *MOUNT) error {
 	return nil
 }
 
+func mountLogicalPartition(mbr *structures.MBR, mount *MOUNT) error {
+	ebr, offset, indexPartition := mbr.GetLogicalPartitionByName(mount.path, mount.name)
+	if ebr == nil {
+		return structures.NewError(structures.ErrNotFound, "la particion no existe")
+	}
+
+	if ebr.Part_mount[0] == '1' {
+		return structures.NewError(structures.ErrExists, "no se puede montar una particion ya montada")
+	}
+
+	idPartition, err := generatePartitionID(mount)
+	if err != nil {
+		return err
+	}
+	mount.id = idPartition
+
+	stores.AddMountedPartition(idPartition, mount.path)
+	ebr.MountPartition(indexPartition, idPartition)
+
+	return ebr.Serialize(mount.path, int64(offset))
+}
+
 func generatePartitionID(mount *MOUNT) (string, error) {
-	_, partitionCorrelative, err := utils.GetLetter(mount.path)
+	_, partitionCorrelative, err := stores.NextPartitionLetter(mount.path)
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"fmt"
+	"server/lexer"
+	"server/stores"
+	"sort"
+	"strings"
+)
+
+var mountedParams = lexer.Schema{}
+
+type MountedResult struct {
+	IDs []string `json:"ids"`
+}
+
+func (result MountedResult) String() string {
+	if len(result.IDs) == 0 {
+		return "No hay particiones montadas"
+	}
+	return fmt.Sprintf("Particiones montadas: %s", strings.Join(result.IDs, ", "))
+}
+
+func init() {
+	Register(Command{
+		Name:        "mounted",
+		Description: "Lista los ids de las particiones montadas",
+		Params:      mountedParams,
+		Run:         ParseMounted,
+	})
+}
+
+func ParseMounted(session *stores.Session, tokens []string) (Result, error) {
+	_, err := mountedParams.Parse(tokens)
+	if err != nil {
+		return nil, err
+	}
+
+	result := MountedResult{IDs: mountedIDs()}
+	return result, nil
+}
+
+// mountedIDs devuelve los ids montados ordenados, tambien los usa el autocompletado
+func mountedIDs() []string {
+	ids := stores.MountedIDs()
+	sort.Strings(ids)
+	return ids
+}
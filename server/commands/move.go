@@ -0,0 +1,168 @@
+package commands
+
+import (
+	"encoding/binary"
+	"fmt"
+	"path/filepath"
+	"server/lexer"
+	"server/stores"
+	"server/structures"
+	"strings"
+	"time"
+)
+
+type MOVE struct {
+	path    string
+	destino string
+}
+
+type MoveResult struct {
+	Path        string `json:"path"`
+	Destination string `json:"destination"`
+}
+
+func (result MoveResult) String() string {
+	return fmt.Sprintf("MOVE: %s movido a %s", result.Path, result.Destination)
+}
+
+var moveParams = lexer.Schema{
+	{Name: "path", Required: true, Path: true, Description: "Ruta del archivo o carpeta"},
+	{Name: "destino", Required: true, Path: true, Description: "Carpeta destino"},
+}
+
+func init() {
+	Register(Command{
+		Name:        "move",
+		Description: "Mueve un archivo o carpeta a otra carpeta",
+		Params:      moveParams,
+		Run:         ParseMove,
+	})
+}
+
+func ParseMove(session *stores.Session, tokens []string) (Result, error) {
+	args, err := moveParams.Parse(tokens)
+	if err != nil {
+		return nil, err
+	}
+	cmd := &MOVE{path: resolvePath(session, args.String("path")), destino: resolvePath(session, args.String("destino"))}
+
+	err = commandMove(session, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return MoveResult{Path: cmd.path, Destination: cmd.destino}, nil
+}
+
+func commandMove(session *stores.Session, move *MOVE) error {
+	if session.IdPartition == "" {
+		return structures.NewError(structures.ErrPermission, "no hay sesion activa")
+	}
+	sb, partition, diskPath, err := stores.GetMountedPartitionSuperblock(session.IdPartition)
+	if err != nil {
+		return err
+	}
+	err = checkJournalSpace(sb, diskPath, partition, 1)
+	if err != nil {
+		return err
+	}
+
+	err = movePath(session, sb, diskPath, move.path, move.destino)
+	if err != nil {
+		return err
+	}
+
+	if sb.IsExt3() {
+		journalDirectory := &structures.Journal{
+			J_next: -1,
+			J_content: structures.Information{
+				I_operation: [10]byte{'m', 'o', 'v', 'e'},
+				I_path:      [74]byte{},
+				I_content:   [64]byte{},
+				I_date:      float32(time.Now().Unix()),
+			},
+		}
+		copy(journalDirectory.J_content.I_path[:], move.path)
+		copy(journalDirectory.J_content.I_content[:], move.destino)
+		err = sb.AddJournal(journalDirectory, diskPath, int32(partition.Part_start+int32(binary.Size(structures.SuperBlock{}))))
+		if err != nil {
+			return err
+		}
+	}
+
+	err = sb.Serialize(diskPath, int64(partition.Part_start))
+	if err != nil {
+		return fmt.Errorf("error al serializar el superbloque: %w", err)
+	}
+	return nil
+}
+
+func movePath(session *stores.Session, sb *structures.SuperBlock, diskPath, path, destino string) error {
+	source := filepath.Clean(path)
+	target := filepath.Clean(destino)
+	if source == "/" {
+		return structures.NewError(structures.ErrInvalid, "no se puede mover la raiz")
+	}
+	if target == source || strings.HasPrefix(target, source+"/") {
+		return structures.NewError(structures.ErrInvalid, "no se puede mover un directorio dentro de si mismo")
+	}
+
+	indexInode, _, err := sb.Lookup(diskPath, source, session.UserID, session.GroupID)
+	if err != nil {
+		return err
+	}
+	err = sb.ProtectUsersFile(diskPath, indexInode, "mover")
+	if err != nil {
+		return err
+	}
+	parentIndex, parentInode, err := sb.Lookup(diskPath, filepath.Dir(source), session.UserID, session.GroupID)
+	if err != nil {
+		return err
+	}
+	destIndex, destInode, err := sb.Lookup(diskPath, target, session.UserID, session.GroupID)
+	if err != nil {
+		return err
+	}
+
+	outcome, err := parentInode.HasPermissionsToWrite(session.UserID, session.GroupID)
+	if err != nil {
+		return err
+	}
+	if !outcome {
+		return structures.NewError(structures.ErrPermission, "no tiene permisos de escritura en el directorio origen")
+	}
+	outcome, err = destInode.HasPermissionsToWrite(session.UserID, session.GroupID)
+	if err != nil {
+		return err
+	}
+	if !outcome {
+		return structures.NewError(structures.ErrPermission, "no tiene permisos de escritura en el destino")
+	}
+	err = sb.MoveTreePermissions(diskPath, indexInode, session.UserID, session.GroupID)
+	if err != nil {
+		return fmt.Errorf("no se movio %s: %w", path, err)
+	}
+
+	// Solo se reenlaza la entrada, los bloques de datos no se copian
+	name := filepath.Base(source)
+	err = sb.CheckSpaceForEntry(diskPath, destIndex, name, 0, 0)
+	if err != nil {
+		return err
+	}
+	err = sb.AddEntryToFolder(diskPath, destIndex, name, indexInode)
+	if err != nil {
+		return err
+	}
+	err = sb.RemoveEntryFromFolder(diskPath, parentIndex, indexInode)
+	if err != nil {
+		return err
+	}
+	tipoInodo, err := sb.TypeOfInode(diskPath, indexInode)
+	if err != nil {
+		return err
+	}
+	if tipoInodo == 0 {
+		return sb.SetParentOfFolder(diskPath, indexInode, destIndex)
+	}
+	return nil
+}
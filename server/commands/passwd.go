@@ -0,0 +1,131 @@
+package commands
+
+import (
+	"encoding/binary"
+	"fmt"
+	"server/lexer"
+	stores "server/stores"
+	"server/structures"
+	"server/utils"
+	"time"
+)
+
+type PASSWD struct {
+	user     string
+	password string
+	hashed   bool // recovery reenvia el hash que quedo en el journal
+}
+
+type PasswdResult struct {
+	User string `json:"user"`
+}
+
+func (result PasswdResult) String() string {
+	return fmt.Sprintf("PASSWD: password de %s cambiado exitosamente", result.User)
+}
+
+var passwdParams = lexer.Schema{
+	{Name: "user", Required: true, Description: "Nombre del usuario"},
+	{Name: "pass", Required: true, Description: "Contrasena nueva, maximo 10 caracteres"},
+}
+
+func init() {
+	Register(Command{
+		Name:        "passwd",
+		Description: "Cambia la contrasena de un usuario, root o el mismo usuario",
+		Params:      passwdParams,
+		Run:         ParsePasswd,
+	})
+}
+
+func ParsePasswd(session *stores.Session, tokens []string) (Result, error) {
+	args, err := passwdParams.Parse(tokens)
+	if err != nil {
+		return nil, err
+	}
+	cmd := &PASSWD{user: args.String("user"), password: args.String("pass")}
+	if len(cmd.user) > 10 {
+		return nil, structures.NewError(structures.ErrInvalid, "el user de usuario no se puede exceder de 10 caracteres")
+	}
+	if len(cmd.password) > 10 {
+		return nil, structures.NewError(structures.ErrInvalid, "el pass de usuario no se puede exceder de 10 caracteres")
+	}
+
+	err = commandPasswd(session, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return PasswdResult{User: cmd.user}, nil
+}
+
+func commandPasswd(session *stores.Session, passwd *PASSWD) error {
+	if session.IdPartition == "" {
+		return structures.NewError(structures.ErrPermission, "no hay sesion activa")
+	}
+	if !session.IsRoot() && session.User != passwd.user {
+		return structures.NewError(structures.ErrPermission, "solo root puede cambiar el password de otro usuario")
+	}
+	contentUsersTxt, err := getContetnUsersTxt(session.IdPartition)
+	if err != nil {
+		return err
+	}
+	contentMatrix := getContentMatrixUsers(contentUsersTxt)
+	row := findActiveUser(passwd.user, contentMatrix)
+	if row == nil {
+		return structures.NewError(structures.ErrNotFound, "el nombre de usuario no existe")
+	}
+	if !passwd.hashed {
+		passwd.password, err = utils.HashPassword(passwd.password)
+		if err != nil {
+			return err
+		}
+	}
+	row[4] = passwd.password
+
+	partitionSuperblock, mountedPartition, partitionPath, err := stores.GetMountedPartitionSuperblock(session.IdPartition)
+	if err != nil {
+		return err
+	}
+	err = checkJournalSpace(partitionSuperblock, partitionPath, mountedPartition, 1)
+	if err != nil {
+		return err
+	}
+	err = OverrideUserstxt(partitionSuperblock, partitionPath, reformUserstxt(contentMatrix))
+	if err != nil {
+		return err
+	}
+	if partitionSuperblock.IsExt3() {
+		err = journalPasswd(partitionSuperblock, partitionPath, mountedPartition, passwd.user, passwd.password)
+		if err != nil {
+			return err
+		}
+	}
+	return partitionSuperblock.Serialize(partitionPath, int64(mountedPartition.Part_start))
+}
+
+// journalPasswd guarda en el journal el hash con el que quedo el usuario, recovery lo reenvia a commandPasswd
+func journalPasswd(sb *structures.SuperBlock, diskPath string, part *structures.PARTITION, user, hash string) error {
+	journalDirectory := &structures.Journal{
+		J_next: -1,
+		J_content: structures.Information{
+			I_operation: [10]byte{'p', 'a', 's', 's', 'w', 'd'},
+			I_path:      [74]byte{},
+			I_content:   [64]byte{},
+			I_date:      float32(time.Now().Unix()),
+		},
+	}
+	fullContent := fmt.Sprintf("%s/%s", user, hash)
+	copy(journalDirectory.J_content.I_content[:], fullContent)
+	return sb.AddJournal(journalDirectory, diskPath, int32(part.Part_start+int32(binary.Size(structures.SuperBlock{}))))
+}
+
+// findActiveUser devuelve el registro del usuario que no esta eliminado
+func findActiveUser(userName string, matrix [][]string) []string {
+	for _, row := range matrix {
+		if row[0] != "0" && row[1] == "U" && len(row) == 5 && row[3] == userName {
+			return row
+		}
+	}
+	return nil
+}
@@ -1,2 +1,31 @@
 package commands
 
+import (
+	"server/lexer"
+	"server/stores"
+)
+
+var pauseParams = lexer.Schema{}
+
+type PauseResult struct{}
+
+func (result PauseResult) String() string {
+	return "PAUSE: Comando ejecutado"
+}
+
+func init() {
+	Register(Command{
+		Name:        "pause",
+		Description: "Pausa la ejecucion hasta que el usuario continue",
+		Params:      pauseParams,
+		Run:         ParsePause,
+	})
+}
+
+func ParsePause(session *stores.Session, tokens []string) (Result, error) {
+	_, err := pauseParams.Parse(tokens)
+	if err != nil {
+		return nil, err
+	}
+	return PauseResult{}, nil
+}
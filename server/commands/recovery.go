@@ -0,0 +1,308 @@
+package commands
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"server/lexer"
+	"server/stores"
+	"server/structures"
+	"server/utils"
+	"strconv"
+	"strings"
+)
+
+type RECOVERY struct {
+	id string
+}
+
+type RecoveryResult struct {
+	ID string `json:"id"`
+}
+
+func (result RecoveryResult) String() string {
+	return fmt.Sprintf("RECOVERY: %s recuperada exitosamente", result.ID)
+}
+
+// journalEntry guarda una operacion del journal ya armada, con los fragmentos de contenido unidos
+type journalEntry struct {
+	operation string
+	path      string
+	content   string
+}
+
+var recoveryParams = lexer.Schema{
+	{Name: "id", Required: true, Description: "Id de la particion montada"},
+}
+
+func init() {
+	Register(Command{
+		Name:        "recovery",
+		Description: "Recupera un sistema de archivos EXT3 con su journal",
+		Params:      recoveryParams,
+		Run:         ParseRecovery,
+	})
+}
+
+func ParseRecovery(session *stores.Session, tokens []string) (Result, error) {
+	args, err := recoveryParams.Parse(tokens)
+	if err != nil {
+		return nil, err
+	}
+	cmd := &RECOVERY{id: args.String("id")}
+
+	err = commandRecovery(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return RecoveryResult{ID: cmd.id}, nil
+}
+
+func commandRecovery(recovery *RECOVERY) error {
+	sb, partition, diskPath, err := stores.GetMountedPartitionSuperblock(recovery.id)
+	if err != nil {
+		return err
+	}
+	if !sb.IsExt3() {
+		return structures.NewError(structures.ErrInvalid, "recovery solo se puede aplicar a particiones EXT3")
+	}
+
+	journalStart := partition.Part_start + int32(binary.Size(structures.SuperBlock{}))
+	journals, offsets, err := sb.JournalChain(diskPath, journalStart)
+	if err != nil {
+		return err
+	}
+
+	// Se reconstruye el sistema de archivos vacio, igual que en mkfs pero sin volver a escribir el journal
+	neoSuperBlock := createSuperBlock(partition, calculateN(partition, "3fs"), "3fs")
+	err = wipeFilesystemArea(neoSuperBlock, diskPath)
+	if err != nil {
+		return err
+	}
+	err = neoSuperBlock.CreateBitMaps(diskPath)
+	if err != nil {
+		return err
+	}
+	err = neoSuperBlock.CreateUsersFile(diskPath, 0)
+	if err != nil {
+		return err
+	}
+	err = neoSuperBlock.Serialize(diskPath, int64(partition.Part_start))
+	if err != nil {
+		return err
+	}
+
+	// Los comandos repetidos vuelven a escribir sus entradas, mientras tanto el journal queda solo con la primera
+	// para que quepan aunque el original este casi lleno
+	head := journals[0]
+	head.J_next = -1
+	err = restoreJournalChain(diskPath, []structures.Journal{head}, offsets[:1], neoSuperBlock.S_bm_inode_start)
+	if err != nil {
+		return err
+	}
+
+	// Los comandos se repiten con su propia sesion, los login y logout del journal la cambian
+	session := &stores.Session{WorkingDir: "/"}
+	for _, entry := range groupJournalEntries(journals) {
+		err = replayJournalEntry(session, recovery.id, entry)
+		if err != nil {
+			return fmt.Errorf("error al recuperar %s %s: %w", entry.operation, entry.path, err)
+		}
+	}
+
+	// Los comandos repetidos agregan entradas al journal, se deja tal como estaba antes del recovery
+	return restoreJournalChain(diskPath, journals, offsets, neoSuperBlock.S_bm_inode_start)
+}
+
+func restoreJournalChain(diskPath string, journals []structures.Journal, offsets []int32, journalEnd int32) error {
+	for i := range journals {
+		err := journals[i].Serialize(diskPath, int64(offsets[i]))
+		if err != nil {
+			return err
+		}
+	}
+	last := offsets[len(offsets)-1] + int32(binary.Size(structures.Journal{}))
+	return zeroDiskRange(diskPath, last, journalEnd)
+}
+
+// groupJournalEntries une los fragmentos de 64 bytes de mkfile y edit que pertenecen al mismo archivo
+func groupJournalEntries(journals []structures.Journal) []journalEntry {
+	var entries []journalEntry
+	full := false
+	for _, journal := range journals {
+		entry := journalEntry{
+			operation: strings.TrimRight(string(journal.J_content.I_operation[:]), "\x00"),
+			path:      strings.TrimRight(string(journal.J_content.I_path[:]), "\x00"),
+			content:   strings.TrimRight(string(journal.J_content.I_content[:]), "\x00"),
+		}
+		chunked := entry.operation == "mkfile" || entry.operation == "edit"
+		if chunked && full && len(entries) > 0 {
+			previous := &entries[len(entries)-1]
+			if previous.operation == entry.operation && previous.path == entry.path {
+				previous.content += entry.content
+				full = len(entry.content) == len(journal.J_content.I_content)
+				continue
+			}
+		}
+		entries = append(entries, entry)
+		full = chunked && len(entry.content) == len(journal.J_content.I_content)
+	}
+	return entries
+}
+
+func replayJournalEntry(session *stores.Session, id string, entry journalEntry) error {
+	switch entry.operation {
+	case "mkdir":
+		// La raiz y users.txt ya los crea CreateUsersFile
+		if entry.path == "/" {
+			return nil
+		}
+		sb, partition, diskPath, err := stores.GetMountedPartitionSuperblock(id)
+		if err != nil {
+			return err
+		}
+		return createDirectory(session, entry.path, sb, diskPath, partition, true)
+	case "mkfile":
+		if entry.path == "/users.txt" {
+			return nil
+		}
+		return replayMkfile(session, id, entry.path, entry.content)
+	case "login":
+		parts := strings.Split(entry.content, "/")
+		if len(parts) < 2 {
+			return structures.NewError(structures.ErrCorrupt, "entrada de login invalida")
+		}
+		contentUsersTxt, err := getContetnUsersTxt(id)
+		if err != nil {
+			return err
+		}
+		session.IdPartition = id
+		session.User = parts[1]
+		return setUpIDs(session, parts[1], getContentMatrixUsers(contentUsersTxt))
+	case "logout":
+		*session = stores.Session{WorkingDir: "/"}
+		return nil
+	case "mkgrp":
+		return CommmandMkgrp(session, &MKGRP{name: entry.content})
+	case "rmgrp":
+		return CommandRmgrp(session, &RMGRP{name: entry.content})
+	case "mkusr":
+		parts := strings.Split(entry.content, "/")
+		if len(parts) < 3 {
+			return structures.NewError(structures.ErrCorrupt, "entrada de mkusr invalida")
+		}
+		return CommandMkusr(session, &MKUSR{
+			user:     parts[0],
+			password: strings.Join(parts[1:len(parts)-1], "/"),
+			group:    parts[len(parts)-1],
+			hashed:   true,
+		})
+	case "rmusr":
+		return CommandoRmusr(session, &RMUSR{user: entry.content})
+	case "passwd":
+		user, password, found := strings.Cut(entry.content, "/")
+		if !found {
+			return structures.NewError(structures.ErrCorrupt, "entrada de passwd invalida")
+		}
+		return commandPasswd(session, &PASSWD{user: user, password: password, hashed: true})
+	case "chgrp":
+		user, group, found := strings.Cut(entry.content, "/")
+		if !found {
+			return structures.NewError(structures.ErrCorrupt, "entrada de chgrp invalida")
+		}
+		return commandChgrp(session, &CHGRP{user: user, group: group})
+	case "chmod":
+		ugo, recursive, err := splitRecursiveContent(entry.content)
+		if err != nil {
+			return err
+		}
+		return commandChmod(session, &CHMOD{path: entry.path, ugo: ugo, r: recursive})
+	case "chown":
+		usuario, recursive, err := splitRecursiveContent(entry.content)
+		if err != nil {
+			return err
+		}
+		return commandChown(session, &CHOWN{path: entry.path, usuario: usuario, r: recursive})
+	case "copy":
+		return commandCopy(session, &COPY{path: entry.path, destino: entry.content})
+	case "move":
+		return commandMove(session, &MOVE{path: entry.path, destino: entry.content})
+	case "remove":
+		return commandRemove(session, &REMOVE{path: entry.path})
+	case "rename":
+		return commandRename(session, &RENAME{path: entry.path, name: entry.content})
+	case "edit":
+		sb, partition, diskPath, err := stores.GetMountedPartitionSuperblock(id)
+		if err != nil {
+			return err
+		}
+		err = editFile(session, sb, diskPath, entry.path, entry.content)
+		if err != nil {
+			return err
+		}
+		return sb.Serialize(diskPath, int64(partition.Part_start))
+	}
+	return structures.Errorf(structures.ErrCorrupt, "operacion desconocida en el journal: %s", entry.operation)
+}
+
+func replayMkfile(session *stores.Session, id, filePath, content string) error {
+	sb, partition, diskPath, err := stores.GetMountedPartitionSuperblock(id)
+	if err != nil {
+		return err
+	}
+	// Si el archivo se creo con -r sus carpetas padre no quedaron en el journal
+	position := strings.LastIndex(filePath, "/")
+	if position > 0 {
+		parentDirs, destDir := utils.GetParentDirectories(filePath[:position])
+		err = sb.CreateFolder(diskPath, parentDirs, destDir, true, session.UserID, session.GroupID)
+		if err != nil {
+			return err
+		}
+	}
+	parentDirs, destDir := utils.GetParentDirectories(filePath)
+	err = sb.CreateFile(diskPath, 0, parentDirs, destDir, content, session.UserID, session.GroupID)
+	if err != nil {
+		return err
+	}
+	return sb.Serialize(diskPath, int64(partition.Part_start))
+}
+
+// splitRecursiveContent separa el contenido "valor/true|false" que guardan chmod y chown
+func splitRecursiveContent(content string) (string, bool, error) {
+	position := strings.LastIndex(content, "/")
+	if position == -1 {
+		return "", false, structures.NewError(structures.ErrCorrupt, "contenido del journal invalido")
+	}
+	recursive, err := strconv.ParseBool(content[position+1:])
+	if err != nil {
+		return "", false, err
+	}
+	return content[:position], recursive, nil
+}
+
+// wipeFilesystemArea llena de ceros los bitmaps, la tabla de inodos y los bloques de la particion
+func wipeFilesystemArea(sb *structures.SuperBlock, diskPath string) error {
+	return zeroDiskRange(diskPath, sb.S_bm_inode_start, sb.S_block_start+sb.TotalBlocks()*sb.S_block_size)
+}
+
+func zeroDiskRange(diskPath string, start, end int32) error {
+	if end <= start {
+		return nil
+	}
+	file, err := os.OpenFile(diskPath, os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.WriteAt(make([]byte, end-start), int64(start))
+	return err
+}
+
+// checkJournalSpace se llama antes de que el comando escriba en el disco, en EXT2 no hay journal que revisar
+func checkJournalSpace(sb *structures.SuperBlock, diskPath string, partition *structures.PARTITION, entries int) error {
+	if !sb.IsExt3() {
+		return nil
+	}
+	return sb.CheckJournalSpace(diskPath, partition.Part_start+int32(binary.Size(structures.SuperBlock{})), entries)
+}
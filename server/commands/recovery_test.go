@@ -0,0 +1,184 @@
+package commands
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"server/stores"
+	"server/structures"
+	"strings"
+	"testing"
+)
+
+// snapshotTree recorre el arbol desde la raiz y describe cada inodo con su tipo, permisos, dueño y contenido
+func snapshotTree(t *testing.T, id string) map[string]string {
+	t.Helper()
+	sb, _, diskPath, err := stores.GetMountedPartitionSuperblock(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree := map[string]string{}
+	var walk func(path string, index int32)
+	walk = func(path string, index int32) {
+		inode := &structures.Inode{}
+		err := inode.Deserialize(diskPath, int64(sb.S_inode_start+sb.S_inode_size*index))
+		if err != nil {
+			t.Fatal(err)
+		}
+		description := fmt.Sprintf("%s %s %d %d", inode.I_type[:], inode.I_perm[:], inode.I_uid, inode.I_gid)
+		if inode.I_type[0] == '1' {
+			content, err := sb.ReadFileContent(diskPath, inode)
+			if err != nil {
+				t.Fatalf("%s: %v", path, err)
+			}
+			tree[path] = description + " " + content
+			return
+		}
+		tree[path] = description
+		entries, err := sb.FolderEntries(diskPath, inode)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		for _, entry := range entries {
+			name := strings.TrimRight(string(entry.B_name[:]), "\x00")
+			walk(strings.TrimSuffix(path, "/")+"/"+name, entry.B_inodo)
+		}
+	}
+	walk("/", 0)
+	return tree
+}
+
+// Despues de loss, recovery repite el journal y deja el mismo arbol, los mismos usuarios y el journal como estaba
+func TestRecovery(t *testing.T) {
+	newTestState(t)
+	contenido := filepath.Join(t.TempDir(), "nuevo.txt")
+	err := os.WriteFile(contenido, []byte(strings.Repeat("contenido editado ", 60)), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	session := &stores.Session{}
+	run(t, session,
+		"mkdisk -size=3 -unit=M",
+		"fdisk -size=1 -unit=M -driveletter=A -name=P1",
+		"fdisk -size=500 -unit=K -driveletter=A -name=P2",
+		"mount -driveletter=A -name=P1",
+		"mount -driveletter=A -name=P2",
+		"mkfs -id=A105 -fs=3fs",
+		"mkfs -id=A205 -fs=2fs",
+		"login -user=root -pass=123 -id=A105",
+		"mkgrp -name=devs",
+		"mkusr -user=ana -pass=clave -grp=devs",
+		"mkusr -user=luis -pass=otra -grp=devs",
+		"mkdir -r -path=/home/ana/docs",
+		"mkdir -path=/tmp",
+		"mkfile -path=/home/ana/docs/largo.txt -size=1200",
+		"mkfile -path=/home/ana/docs/corto.txt -size=20",
+		"mkfile -r -path=/var/log/sys.log -size=100",
+		"edit -path=/home/ana/docs/corto.txt -contenido="+contenido,
+		"copy -path=/home/ana/docs -destino=/tmp",
+		"move -path=/var/log/sys.log -destino=/tmp",
+		"rename -path=/tmp/sys.log -name=viejo.log",
+		"remove -path=/var",
+		"chown -r -path=/home/ana -usuario=ana",
+		"chmod -path=/home -ugo=755",
+		"chmod -r -path=/home/ana -ugo=750",
+		"rmusr -user=luis",
+		"passwd -user=ana -pass=nueva",
+		"logout",
+		"login -user=ana -pass=nueva -id=A105",
+		"mkfile -path=/home/ana/mio.txt -size=30",
+		"logout",
+	)
+
+	tree := snapshotTree(t, "A105")
+	if _, found := tree["/tmp/docs/largo.txt"]; !found {
+		t.Fatalf("el arbol no tiene la copia: %v", tree)
+	}
+	users := readFile(t, "A105", "/users.txt")
+	sb, partition, diskPath, err := stores.GetMountedPartitionSuperblock("A105")
+	if err != nil {
+		t.Fatal(err)
+	}
+	journalStart := partition.Part_start + int32(binary.Size(structures.SuperBlock{}))
+	journals, _, err := sb.JournalChain(diskPath, journalStart)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	run(t, session, "loss -id=A105")
+	if _, _, err := sb.Lookup(diskPath, "/home", 1, 1); err == nil {
+		t.Fatal("despues de loss todavia se encuentra /home")
+	}
+	run(t, session, "recovery -id=A105")
+
+	if got := snapshotTree(t, "A105"); !reflect.DeepEqual(got, tree) {
+		t.Errorf("el arbol recuperado no es igual:\n%v\n%v", got, tree)
+	}
+	if got := readFile(t, "A105", "/users.txt"); got != users {
+		t.Errorf("users.txt recuperado:\n%s\nse esperaba:\n%s", got, users)
+	}
+	recovered, _, err := sb.JournalChain(diskPath, journalStart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(recovered, journals) {
+		t.Errorf("el journal tiene %d entradas despues de recovery y tenia %d", len(recovered), len(journals))
+	}
+	checkFsck(t, "A105")
+
+	// La contraseña cambiada con passwd es la que vale despues de recuperar
+	run(t, session, "login -user=ana -pass=nueva -id=A105", "logout")
+
+	if _, err := tryRun(session, "recovery -id=A205"); !errors.Is(err, structures.ErrInvalid) {
+		t.Errorf("recovery sobre EXT2 deberia fallar con ErrInvalid y llego %v", err)
+	}
+}
+
+// Una cadena del journal que se sale de sus n entradas esta dañada, recovery no la repite ni toca el disco
+func TestRecoveryCorruptJournal(t *testing.T) {
+	newTestState(t)
+	session := &stores.Session{}
+	run(t, session,
+		"mkdisk -size=1 -unit=M",
+		"fdisk -size=200 -unit=K -driveletter=A -name=P1",
+		"mount -driveletter=A -name=P1",
+		"mkfs -id=A105 -fs=3fs",
+		"login -user=root -pass=123 -id=A105",
+		"mkdir -path=/docs",
+		"mkfile -path=/docs/a.txt -size=100",
+	)
+	sb, partition, diskPath, err := stores.GetMountedPartitionSuperblock("A105")
+	if err != nil {
+		t.Fatal(err)
+	}
+	journalStart := partition.Part_start + int32(binary.Size(structures.SuperBlock{}))
+	journals, offsets, err := sb.JournalChain(diskPath, journalStart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree := snapshotTree(t, "A105")
+	last := journals[len(journals)-1]
+
+	for _, next := range []int32{
+		sb.S_bm_inode_start,      // el bitmap de inodos
+		sb.S_bm_inode_start - 10, // una entrada que termina dentro del bitmap
+		journalStart,             // un ciclo que nunca llega a -1
+		journalStart - 1,         // antes del journal
+	} {
+		last.J_next = next
+		err = last.Serialize(diskPath, int64(offsets[len(offsets)-1]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tryRun(session, "recovery -id=A105"); !errors.Is(err, structures.ErrCorrupt) {
+			t.Fatalf("J_next %d: se esperaba ErrCorrupt y llego %v", next, err)
+		}
+		if got := snapshotTree(t, "A105"); !reflect.DeepEqual(got, tree) {
+			t.Fatalf("J_next %d: recovery cambio el sistema de archivos aunque el journal estaba dañado", next)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"server/lexer"
+	"server/stores"
+	"sort"
+	"strings"
+)
+
+// Result es la salida de un comando: String es el texto de la consola y los campos exportados el JSON de la API
+type Result interface {
+	String() string
+}
+
+// Command es lo que cada comando registra para el analizador, help y /api/commands
+type Command struct {
+	Name        string                                                         `json:"name"`
+	Description string                                                         `json:"description"`
+	Params      lexer.Schema                                                   `json:"params"`
+	Run         func(session *stores.Session, tokens []string) (Result, error) `json:"-"` //recibe la sesion y los tokens sin el nombre del comando
+}
+
+var registry = make(map[string]Command)
+
+// Register agrega el comando, cada archivo de comando lo llama en su init
+func Register(command Command) {
+	if command.Params == nil {
+		command.Params = lexer.Schema{}
+	}
+	registry[command.Name] = command
+}
+
+// Lookup busca el comando sin distinguir mayusculas
+func Lookup(name string) (Command, bool) {
+	command, found := registry[strings.ToLower(name)]
+	return command, found
+}
+
+// Registered devuelve los comandos ordenados por nombre
+func Registered() []Command {
+	list := make([]Command, 0, len(registry))
+	for _, command := range registry {
+		list = append(list, command)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// Usage es la linea de uso del comando, como mkdir -path=<texto> [-r]
+func (command Command) Usage() string {
+	usage := command.Params.Usage()
+	if usage == "" {
+		return command.Name
+	}
+	return command.Name + " " + usage
+}
@@ -0,0 +1,139 @@
+package commands
+
+import (
+	"encoding/binary"
+	"fmt"
+	"path/filepath"
+	"server/lexer"
+	"server/stores"
+	"server/structures"
+	"time"
+)
+
+type REMOVE struct {
+	path string
+}
+
+type RemoveResult struct {
+	Path string `json:"path"`
+}
+
+func (result RemoveResult) String() string {
+	return fmt.Sprintf("REMOVE: %s eliminado exitosamente", result.Path)
+}
+
+var removeParams = lexer.Schema{
+	{Name: "path", Required: true, Path: true, Description: "Ruta del archivo o carpeta"},
+}
+
+func init() {
+	Register(Command{
+		Name:        "remove",
+		Description: "Elimina un archivo o una carpeta con su contenido",
+		Params:      removeParams,
+		Run:         ParseRemove,
+	})
+}
+
+func ParseRemove(session *stores.Session, tokens []string) (Result, error) {
+	args, err := removeParams.Parse(tokens)
+	if err != nil {
+		return nil, err
+	}
+	cmd := &REMOVE{path: resolvePath(session, args.String("path"))}
+
+	err = commandRemove(session, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return RemoveResult{Path: cmd.path}, nil
+}
+
+func commandRemove(session *stores.Session, remove *REMOVE) error {
+	if session.IdPartition == "" {
+		return structures.NewError(structures.ErrPermission, "no hay sesion activa")
+	}
+	sb, partition, diskPath, err := stores.GetMountedPartitionSuperblock(session.IdPartition)
+	if err != nil {
+		return err
+	}
+	err = checkJournalSpace(sb, diskPath, partition, 1)
+	if err != nil {
+		return err
+	}
+
+	err = removePath(session, sb, diskPath, remove.path)
+	if err != nil {
+		return err
+	}
+
+	if sb.IsExt3() {
+		journalDirectory := &structures.Journal{
+			J_next: -1,
+			J_content: structures.Information{
+				I_operation: [10]byte{'r', 'e', 'm', 'o', 'v', 'e'},
+				I_path:      [74]byte{},
+				I_content:   [64]byte{},
+				I_date:      float32(time.Now().Unix()),
+			},
+		}
+		copy(journalDirectory.J_content.I_path[:], remove.path)
+		err = sb.AddJournal(journalDirectory, diskPath, int32(partition.Part_start+int32(binary.Size(structures.SuperBlock{}))))
+		if err != nil {
+			return err
+		}
+	}
+
+	err = sb.Serialize(diskPath, int64(partition.Part_start))
+	if err != nil {
+		return fmt.Errorf("error al serializar el superbloque: %w", err)
+	}
+	return nil
+}
+
+func removePath(session *stores.Session, sb *structures.SuperBlock, diskPath, path string) error {
+	target := filepath.Clean(path)
+	if target == "/" {
+		return structures.NewError(structures.ErrInvalid, "no se puede eliminar la raiz")
+	}
+	indexInode, inode, err := sb.Lookup(diskPath, target, session.UserID, session.GroupID)
+	if err != nil {
+		return err
+	}
+	err = sb.ProtectUsersFile(diskPath, indexInode, "eliminar")
+	if err != nil {
+		return err
+	}
+	parentIndex, parentInode, err := sb.Lookup(diskPath, filepath.Dir(target), session.UserID, session.GroupID)
+	if err != nil {
+		return err
+	}
+	outcome, err := parentInode.HasPermissionsToWrite(session.UserID, session.GroupID)
+	if err != nil {
+		return err
+	}
+	if !outcome {
+		return structures.NewError(structures.ErrPermission, "no tiene permisos de escritura en el directorio padre")
+	}
+
+	// Si algun elemento del subarbol no se puede escribir, se conserva todo el subarbol
+	err = sb.MoveTreePermissions(diskPath, indexInode, session.UserID, session.GroupID)
+	if err != nil {
+		return fmt.Errorf("no se elimino %s: %w", path, err)
+	}
+
+	var removed bool
+	if inode.I_type[0] == '0' {
+		removed, err = sb.RemoveInodo0(diskPath, indexInode, session.UserID, session.GroupID)
+	} else {
+		removed, err = sb.RemoveInodo1(diskPath, indexInode, session.UserID, session.GroupID)
+	}
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return structures.Errorf(structures.ErrPermission, "no se pudo eliminar %s por falta de permisos", path)
+	}
+	return sb.RemoveEntryFromFolder(diskPath, parentIndex, indexInode)
+}
@@ -0,0 +1,127 @@
+package commands
+
+import (
+	"encoding/binary"
+	"fmt"
+	"path/filepath"
+	"server/lexer"
+	"server/stores"
+	"server/structures"
+	"strings"
+	"time"
+)
+
+type RENAME struct {
+	path string
+	name string
+}
+
+type RenameResult struct {
+	Path string `json:"path"`
+	Name string `json:"name"`
+}
+
+func (result RenameResult) String() string {
+	return fmt.Sprintf("RENAME: %s renombrado a %s", result.Path, result.Name)
+}
+
+var renameParams = lexer.Schema{
+	{Name: "path", Required: true, Path: true, Description: "Ruta del archivo o carpeta"},
+	{Name: "name", Required: true, Description: "Nombre nuevo, maximo 12 caracteres"},
+}
+
+func init() {
+	Register(Command{
+		Name:        "rename",
+		Description: "Cambia el nombre de un archivo o carpeta",
+		Params:      renameParams,
+		Run:         ParseRename,
+	})
+}
+
+func ParseRename(session *stores.Session, tokens []string) (Result, error) {
+	args, err := renameParams.Parse(tokens)
+	if err != nil {
+		return nil, err
+	}
+	cmd := &RENAME{path: resolvePath(session, args.String("path")), name: args.String("name")}
+	if strings.Contains(cmd.name, "/") {
+		return nil, structures.NewError(structures.ErrInvalid, "el nombre no puede contener /")
+	}
+	if len(cmd.name) > 12 {
+		return nil, structures.NewError(structures.ErrInvalid, "el nombre no puede tener mas de 12 caracteres")
+	}
+
+	err = commandRename(session, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return RenameResult{Path: cmd.path, Name: cmd.name}, nil
+}
+
+func commandRename(session *stores.Session, rename *RENAME) error {
+	if session.IdPartition == "" {
+		return structures.NewError(structures.ErrPermission, "no hay sesion activa")
+	}
+	sb, partition, diskPath, err := stores.GetMountedPartitionSuperblock(session.IdPartition)
+	if err != nil {
+		return err
+	}
+	err = checkJournalSpace(sb, diskPath, partition, 1)
+	if err != nil {
+		return err
+	}
+
+	err = renamePath(session, sb, diskPath, rename.path, rename.name)
+	if err != nil {
+		return err
+	}
+
+	if sb.IsExt3() {
+		journalDirectory := &structures.Journal{
+			J_next: -1,
+			J_content: structures.Information{
+				I_operation: [10]byte{'r', 'e', 'n', 'a', 'm', 'e'},
+				I_path:      [74]byte{},
+				I_content:   [64]byte{},
+				I_date:      float32(time.Now().Unix()),
+			},
+		}
+		copy(journalDirectory.J_content.I_path[:], rename.path)
+		copy(journalDirectory.J_content.I_content[:], rename.name)
+		err = sb.AddJournal(journalDirectory, diskPath, int32(partition.Part_start+int32(binary.Size(structures.SuperBlock{}))))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func renamePath(session *stores.Session, sb *structures.SuperBlock, diskPath, path, name string) error {
+	target := filepath.Clean(path)
+	if target == "/" {
+		return structures.NewError(structures.ErrInvalid, "no se puede renombrar la raiz")
+	}
+	indexInode, _, err := sb.Lookup(diskPath, target, session.UserID, session.GroupID)
+	if err != nil {
+		return err
+	}
+	err = sb.ProtectUsersFile(diskPath, indexInode, "renombrar")
+	if err != nil {
+		return err
+	}
+	// El nombre es una entrada de la carpeta padre, es ella la que se modifica
+	parentIndex, parentInode, err := sb.Lookup(diskPath, filepath.Dir(target), session.UserID, session.GroupID)
+	if err != nil {
+		return err
+	}
+	outcome, err := parentInode.HasPermissionsToWrite(session.UserID, session.GroupID)
+	if err != nil {
+		return err
+	}
+	if !outcome {
+		return structures.NewError(structures.ErrPermission, "no tiene permisos de escritura en el directorio padre")
+	}
+	return sb.RenameEntryInFolder(diskPath, parentIndex, indexInode, name)
+}
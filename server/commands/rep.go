@@ -1,12 +1,12 @@
 package commands
 
 import (
-	"errors"
 	"fmt"
-	"regexp"
 	ext3 "server/Ext3Info"
+	"server/lexer"
 	"server/reports"
 	"server/stores"
+	"server/utils"
 	"strings"
 )
 
@@ -17,94 +17,55 @@ type REP struct {
 	ruta string
 }
 
-func ParseRep(tokens []string) (string, error) {
-	cmd := &REP{}
+type RepResult struct {
+	Name string `json:"name"`
+	ID   string `json:"id"`
+	Path string `json:"path"`
+}
 
-	args := strings.Join(tokens, " ")
-	re := regexp.MustCompile(`-id=[a-zA-Z0-9]+|-ruta="[^"]+"|-ruta=[^\s]+|-path="[^"]+"|-path=[^\s]+|-name=[a-zA-Z_]+`)
-	matches := re.FindAllString(args, -1)
+func (result RepResult) String() string {
+	return fmt.Sprintf("REP: el reporte %s fue generado con exito", result.Name)
+}
 
-	for _, match := range matches {
-		kv := strings.SplitN(match, "=", 2)
-		if len(kv) != 2 {
-			return "", fmt.Errorf("formato de parametro invalido: %s", match)
-		}
-		key, value := strings.ToLower(kv[0]), kv[1]
+var repParams = lexer.Schema{
+	{Name: "name", Required: true, Values: []string{"mbr", "disk", "inode", "block", "bm_inode", "bm_block", "tree", "sb", "file", "ls", "journaling"}, Description: "Tipo de reporte"},
+	{Name: "path", Required: true, Description: "Archivo de salida"},
+	{Name: "id", Required: true, Description: "Id de la particion montada"},
+	{Name: "ruta", Path: true, Description: "Archivo o carpeta para los reportes file y ls"},
+}
 
-		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
-			value = strings.Trim(value, "\"")
-		}
+func init() {
+	Register(Command{
+		Name:        "rep",
+		Description: "Genera un reporte de un disco o de una particion montada",
+		Params:      repParams,
+		Run:         ParseRep,
+	})
+}
 
-		switch key {
-		case "-ruta":
-			if value == "" {
-				return "", errors.New("el ruta no puede estar vacio")
-			}
-			cmd.ruta = value
-		case "-path":
-			if value == "" {
-				return "", errors.New("el path no puede estar vacio")
-			}
-			cmd.path = value
-		case "-id":
-			if value == "" {
-				return "", errors.New("el id no puede estar vacio")
-			}
-			cmd.id = value
-		case "-name":
-			if value == "" {
-				return "", errors.New("el name no puede estar vacio")
-			}
-			value = strings.ToLower(value)
-			switch value {
-			case "mbr":
-				cmd.name = "mbr"
-			case "disk":
-				cmd.name = "disk"
-			case "inode":
-				cmd.name = "inode"
-			case "block":
-				cmd.name = "block"
-			case "bm_inode":
-				cmd.name = "bm_inode"
-			case "bm_block":
-				cmd.name = "bm_block"
-			case "tree":
-				cmd.name = "tree"
-			case "sb":
-				cmd.name = "sb"
-			case "file":
-				cmd.name = "file"
-			case "ls":
-				cmd.name = "ls"
-			case "journaling":
-				cmd.name = "journaling"
-			default:
-				return "", fmt.Errorf("valor del nombre invalido: %s", value)
-			}
-		default:
-			return "", fmt.Errorf("parametro desconocido: %s", key)
-		}
-	}
-	if cmd.path == "" {
-		return "", errors.New("faltan parametros requeridos: -path")
-	}
-	if cmd.id == "" {
-		return "", errors.New("faltan parametros requeridos: -id")
+func ParseRep(session *stores.Session, tokens []string) (Result, error) {
+	args, err := repParams.Parse(tokens)
+	if err != nil {
+		return nil, err
 	}
-	if cmd.name == "" {
-		return "", errors.New("faltan parametros requeridos: -name")
+	cmd := &REP{name: args.String("name"), path: args.String("path"), id: args.String("id"), ruta: args.String("ruta")}
+
+	// -ruta es relativa al directorio de la sesion solo si el reporte es de la particion de la sesion
+	if strings.EqualFold(cmd.id, session.IdPartition) {
+		cmd.ruta = resolvePath(session, cmd.ruta)
+	} else if cmd.ruta != "" {
+		cmd.ruta = utils.ResolvePath("/", cmd.ruta)
 	}
 
-	err := commandRep(cmd)
+	err = commandRep(session, cmd)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	return fmt.Sprintf("REP: el reporte %s fue generado con exito", cmd.name), nil
+	return RepResult{Name: cmd.name, ID: cmd.id, Path: cmd.path}, nil
 
 }
 
-func commandRep(rep *REP) error {
+func commandRep(session *stores.Session, rep *REP) error {
 	mountedMbr, mountedSb, mountedDiskPath, err := stores.GetMountedPartitionRep(rep.id)
 	if err != nil {
 		return err
@@ -112,7 +73,7 @@ func commandRep(rep *REP) error {
 
 	switch rep.name {
 	case "mbr":
-		err = reports.ReportMBR(mountedMbr, rep.path, rep.id)
+		err = reports.ReportMBR(mountedMbr, rep.path, rep.id, mountedDiskPath)
 		if err != nil {
 			return err
 		}
@@ -153,12 +114,12 @@ func commandRep(rep *REP) error {
 		}
 	case "file":
 
-		err = reports.ReportFile(mountedSb, mountedDiskPath, rep.path, rep.ruta)
+		err = reports.ReportFile(session, mountedSb, mountedDiskPath, rep.path, rep.ruta)
 		if err != nil {
 			return err
 		}
 	case "ls":
-		err = reports.ReportLs(rep.path, rep.ruta)
+		err = reports.ReportLs(session, rep.path, rep.ruta)
 		if err != nil {
 			return err
 		}
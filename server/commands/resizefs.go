@@ -0,0 +1,203 @@
+package commands
+
+import (
+	"encoding/binary"
+	"fmt"
+	"server/lexer"
+	"server/stores"
+	"server/structures"
+	"server/utils"
+)
+
+type RESIZEFS struct {
+	id   string
+	add  int
+	unit string
+}
+
+type ResizefsResult struct {
+	ID         string `json:"id"`
+	Size       int32  `json:"size"`
+	Inodes     int32  `json:"inodes"`
+	Blocks     int32  `json:"blocks"`
+	FreeInodes int32  `json:"freeInodes"`
+	FreeBlocks int32  `json:"freeBlocks"`
+}
+
+func (result ResizefsResult) String() string {
+	return fmt.Sprintf("RESIZEFS: %s ahora tiene %d bytes, %d inodos y %d bloques (%d inodos y %d bloques libres)",
+		result.ID, result.Size, result.Inodes, result.Blocks, result.FreeInodes, result.FreeBlocks)
+}
+
+var resizefsParams = lexer.Schema{
+	{Name: "id", Required: true, Description: "Id de la particion montada"},
+	{Name: "add", Kind: lexer.Int, Description: "Espacio a agregar, negativo para quitar"},
+	{Name: "unit", Default: "K", Values: []string{"B", "K", "M"}, Description: "Unidad de -add"},
+}
+
+func init() {
+	Register(Command{
+		Name:        "resizefs",
+		Description: "Cambia el tamano de una particion y de su sistema de archivos",
+		Params:      resizefsParams,
+		Run:         ParseResizefs,
+	})
+}
+
+func ParseResizefs(session *stores.Session, tokens []string) (Result, error) {
+	args, err := resizefsParams.Parse(tokens)
+	if err != nil {
+		return nil, err
+	}
+	cmd := &RESIZEFS{id: args.String("id"), add: args.Int("add"), unit: args.String("unit")}
+
+	result, err := commandResizefs(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// commandResizefs cambia el tamaño de la particion con -add y reconstruye el sistema de archivos para el tamaño que quede.
+// Sin -add solo ajusta el sistema de archivos al tamaño actual, por ejemplo despues de un fdisk -add.
+func commandResizefs(resizefs *RESIZEFS) (*ResizefsResult, error) {
+	sb, partition, diskPath, err := stores.GetMountedPartitionSuperblock(resizefs.id)
+	if err != nil {
+		return nil, err
+	}
+	if sb.S_magic != 0xEF53 {
+		return nil, structures.NewError(structures.ErrInvalid, "la particion no tiene un sistema de archivos, use mkfs")
+	}
+	fs := "2fs"
+	if sb.IsExt3() {
+		fs = "3fs"
+	}
+
+	resized := *partition
+	if resizefs.add != 0 {
+		bytes, err := utils.ConvertToBytes(resizefs.add, resizefs.unit)
+		if err != nil {
+			return nil, err
+		}
+		resized.Part_size += int32(bytes)
+		err = checkPartitionResize(diskPath, partition, &resized)
+		if err != nil {
+			return nil, err
+		}
+	}
+	n := calculateN(&resized, fs)
+	if n <= 0 {
+		return nil, structures.NewError(structures.ErrNoSpace, "el tamaño de la particion no alcanza para un sistema de archivos")
+	}
+	neoSuperBlock := createSuperBlock(&resized, n, fs)
+
+	// El journal empieza en el mismo lugar, solo cambia cuantas entradas caben
+	var journals []structures.Journal
+	var offsets []int32
+	if sb.IsExt3() {
+		journals, offsets, err = sb.JournalChain(diskPath, partition.Part_start+int32(binary.Size(structures.SuperBlock{})))
+		if err != nil {
+			return nil, err
+		}
+		if int32(len(journals)) > n {
+			return nil, structures.Errorf(structures.ErrNoSpace, "no se puede reducir por debajo del espacio usado: el journal tiene %d entradas y con el nuevo tamaño caben %d", len(journals), n)
+		}
+	}
+
+	err = sb.Resize(diskPath, neoSuperBlock)
+	if err != nil {
+		return nil, err
+	}
+	if sb.IsExt3() {
+		err = restoreJournalChain(diskPath, journals, offsets, neoSuperBlock.S_bm_inode_start)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = neoSuperBlock.Serialize(diskPath, int64(partition.Part_start))
+	if err != nil {
+		return nil, err
+	}
+	if resized.Part_size != partition.Part_size {
+		err = savePartitionSize(diskPath, &resized)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &ResizefsResult{
+		ID:         resizefs.id,
+		Size:       resized.Part_size,
+		Inodes:     neoSuperBlock.TotalInodes(),
+		Blocks:     neoSuperBlock.TotalBlocks(),
+		FreeInodes: neoSuperBlock.S_free_inodes_count,
+		FreeBlocks: neoSuperBlock.S_free_blocks_count,
+	}, nil
+}
+
+// checkPartitionResize valida que la particion pueda crecer hasta la siguiente particion, o hasta el final de la extendida si es logica
+func checkPartitionResize(diskPath string, partition, resized *structures.PARTITION) error {
+	if resized.Part_size <= 0 {
+		return structures.NewError(structures.ErrInvalid, "no se puede quitar bytes a la particion dado que quedaria en negativo el size")
+	}
+	if resized.Part_size <= partition.Part_size {
+		return nil
+	}
+	mbr := &structures.MBR{}
+	err := mbr.DeserializeMBR(diskPath)
+	if err != nil {
+		return err
+	}
+	end := partition.Part_start + partition.Part_size
+	amount := int(resized.Part_size - partition.Part_size)
+	if partition.Part_type[0] != 'L' {
+		_, index, err := mbr.GetPartitionByID(string(partition.Part_id[:]))
+		if err != nil {
+			return err
+		}
+		if !isItPosibleToAdd(end, mbr, amount, index, mbr.Mbr_size) {
+			return structures.NewError(structures.ErrNoSpace, "no hay suficiente espacio como para adicionar bytes a la particion")
+		}
+		return nil
+	}
+
+	ebr, _, err := mbr.GetLogicalPartitionByID(diskPath, string(partition.Part_id[:]))
+	if err != nil {
+		return err
+	}
+	limit := ebr.Part_next
+	if limit == -1 {
+		extended, err := mbr.GetExtendedPartition()
+		if err != nil {
+			return err
+		}
+		limit = extended.Part_start + extended.Part_size
+	}
+	if int(end)+amount > int(limit) {
+		return structures.NewError(structures.ErrNoSpace, "no hay suficiente espacio como para adicionar bytes a la particion")
+	}
+	return nil
+}
+
+// savePartitionSize escribe el nuevo tamaño en el MBR o en el EBR de la logica
+func savePartitionSize(diskPath string, resized *structures.PARTITION) error {
+	mbr := &structures.MBR{}
+	err := mbr.DeserializeMBR(diskPath)
+	if err != nil {
+		return err
+	}
+	if resized.Part_type[0] != 'L' {
+		partition, _, err := mbr.GetPartitionByID(string(resized.Part_id[:]))
+		if err != nil {
+			return err
+		}
+		partition.Part_size = resized.Part_size
+		return mbr.SerializeMBR(diskPath)
+	}
+	ebr, offset, err := mbr.GetLogicalPartitionByID(diskPath, string(resized.Part_id[:]))
+	if err != nil {
+		return err
+	}
+	ebr.Part_size = resized.Part_size
+	return ebr.Serialize(diskPath, int64(offset))
+}
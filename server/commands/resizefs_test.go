@@ -0,0 +1,146 @@
+package commands
+
+import (
+	"encoding/binary"
+	"errors"
+	"server/stores"
+	"server/structures"
+	"strings"
+	"testing"
+)
+
+// resizedState es lo que se lee del disco despues de cada resizefs
+type resizedState struct {
+	size     int32
+	inodes   int32
+	journals int
+}
+
+// compacted pide ademas que los usados queden al inicio, como los deja resizefs sin los huecos que dejo remove
+func readResizedState(t *testing.T, id string, compacted bool) resizedState {
+	t.Helper()
+	sb, partition, diskPath, err := stores.GetMountedPartitionSuperblock(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := resizedState{size: partition.Part_size, inodes: sb.TotalInodes()}
+	if sb.IsExt3() {
+		journals, _, err := sb.JournalChain(diskPath, partition.Part_start+int32(binary.Size(structures.SuperBlock{})))
+		if err != nil {
+			t.Fatal(err)
+		}
+		state.journals = len(journals)
+	}
+	if want := calculateN(partition, map[bool]string{true: "3fs", false: "2fs"}[sb.IsExt3()]); state.inodes != want {
+		t.Fatalf("la particion mide %d bytes y tiene %d inodos, mkfs le daria %d", partition.Part_size, state.inodes, want)
+	}
+	if !compacted {
+		return state
+	}
+	if used := sb.TotalInodes() - sb.S_free_inodes_count; sb.S_inodes_count != used {
+		t.Fatalf("S_inodes_count es %d y hay %d inodos usados", sb.S_inodes_count, used)
+	}
+	if used := sb.TotalBlocks() - sb.S_free_blocks_count; sb.S_blocks_count != used {
+		t.Fatalf("S_blocks_count es %d y hay %d bloques usados", sb.S_blocks_count, used)
+	}
+	return state
+}
+
+func TestResizefsPrimary(t *testing.T) {
+	newTestState(t)
+	session := &stores.Session{}
+	run(t, session,
+		"mkdisk -size=3 -unit=M",
+		"fdisk -size=500 -unit=K -driveletter=A -name=P1",
+		"fdisk -size=500 -unit=K -driveletter=A -name=P2",
+		"mount -driveletter=A -name=P1",
+		"mount -driveletter=A -name=P2",
+		"mkfs -id=A205 -fs=3fs",
+		"login -user=root -pass=123 -id=A205",
+		"mkdir -r -path=/docs/a/b",
+		"mkfile -path=/docs/a/b/f.txt -size=2000",
+		"mkfile -path=/borrar.txt -size=1000",
+		"remove -path=/borrar.txt",
+	)
+	content := readFile(t, "A205", "/docs/a/b/f.txt")
+	users := readFile(t, "A205", "/users.txt")
+	before := readResizedState(t, "A205", false)
+
+	for _, c := range []struct {
+		command string
+		size    int32
+		kind    error // si falla el disco no cambia
+	}{
+		{"resizefs -id=A205 -add=500", 1000 * 1024, nil},
+		{"resizefs -id=A205 -add=-1020", 1000 * 1024, structures.ErrInvalid},
+		{"resizefs -id=A205 -add=-995", 1000 * 1024, structures.ErrNoSpace},
+		{"resizefs -id=A205 -add=5 -unit=M", 1000 * 1024, structures.ErrNoSpace},
+		{"resizefs -id=A205 -add=-600", 400 * 1024, nil},
+		{"resizefs -id=A205", 400 * 1024, nil},
+	} {
+		_, err := tryRun(session, c.command)
+		if c.kind == nil && err != nil {
+			t.Fatalf("%s: %v", c.command, err)
+		}
+		if c.kind != nil && !errors.Is(err, c.kind) {
+			t.Fatalf("%s: se esperaba %v y llego %v", c.command, c.kind, err)
+		}
+
+		state := readResizedState(t, "A205", true)
+		if state.size != c.size {
+			t.Fatalf("%s: la particion mide %d bytes en el MBR, se esperaban %d", c.command, state.size, c.size)
+		}
+		if state.journals != before.journals {
+			t.Fatalf("%s: el journal tiene %d entradas y antes tenia %d", c.command, state.journals, before.journals)
+		}
+		checkFsck(t, "A205")
+		if readFile(t, "A205", "/docs/a/b/f.txt") != content || readFile(t, "A205", "/users.txt") != users {
+			t.Fatalf("%s: cambio el contenido de los archivos", c.command)
+		}
+	}
+
+	// La particion vecina no cambio y el sistema redimensionado se sigue usando
+	_, p1, _, err := stores.GetMountedPartitionSuperblock("A105")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p1.Part_size != 500*1024 {
+		t.Fatalf("P1 mide %d bytes", p1.Part_size)
+	}
+	run(t, session, "mkfile -path=/docs/nuevo.txt -size=300")
+	checkFsck(t, "A205")
+}
+
+func TestResizefsLogical(t *testing.T) {
+	newTestState(t)
+	session := &stores.Session{}
+	run(t, session,
+		"mkdisk -size=3 -unit=M",
+		"fdisk -size=1 -unit=M -driveletter=A -name=Ext -type=E",
+		"fdisk -size=300 -unit=K -driveletter=A -name=L1 -type=L",
+		"mount -driveletter=A -name=L1",
+		"mkfs -id=A105 -fs=2fs",
+		"login -user=root -pass=123 -id=A105",
+		"mkfile -path=/a.txt -size=700",
+	)
+	content := readFile(t, "A105", "/a.txt")
+
+	run(t, session, "resizefs -id=A105 -add=200")
+	if state := readResizedState(t, "A105", true); state.size != 500*1024 {
+		t.Fatalf("la logica mide %d bytes en su EBR, se esperaban %d", state.size, 500*1024)
+	}
+	checkFsck(t, "A105")
+
+	// No puede pasar del final de la extendida
+	_, err := tryRun(session, "resizefs -id=A105 -add=600")
+	if !errors.Is(err, structures.ErrNoSpace) {
+		t.Fatalf("se esperaba ErrNoSpace y llego %v", err)
+	}
+	if state := readResizedState(t, "A105", true); state.size != 500*1024 {
+		t.Fatalf("la logica cambio a %d bytes despues de un resizefs que fallo", state.size)
+	}
+	if readFile(t, "A105", "/a.txt") != content || !strings.HasPrefix(readFile(t, "A105", "/users.txt"), "1,G,root") {
+		t.Fatal("cambio el contenido de los archivos")
+	}
+	checkFsck(t, "A105")
+}
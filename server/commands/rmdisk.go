@@ -1,71 +1,63 @@
 package commands
 
 import (
-	"errors"
 	"fmt"
 	"os"
-	"regexp"
+	"server/lexer"
 	"server/stores"
-	"strings"
+	"server/structures"
 )
 
 type RMDISK struct {
 	path string
 }
 
-func ParseRmdisk(tokens []string) (string, error) {
-	cmd := &RMDISK{}
-	args := strings.Join(tokens, " ")
-	re := regexp.MustCompile(`-driveletter=[A-Za-z]`)
-	matches := re.FindAllString(args, -1)
+type RmdiskResult struct {
+	Disk string `json:"disk"`
+	Path string `json:"path"`
+}
 
-	for _, match := range matches {
-		kv := strings.SplitN(match, "=", 2)
-		if len(kv) != 2 {
-			return "", fmt.Errorf("formato de parametro invalido: %s", match)
-		}
-		key, value := strings.ToLower(kv[0]), kv[1]
+func (result RmdiskResult) String() string {
+	return fmt.Sprintf("RMDISK: %s eliminado exitosamente", result.Path)
+}
 
-		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
-			value = strings.Trim(value, "\"")
-		}
+var rmdiskParams = lexer.Schema{
+	{Name: "driveletter", Kind: lexer.Letter, Required: true, Description: "Letra del disco"},
+}
 
-		switch key {
-		case "-driveletter":
-			if value == "'" {
-				return "", errors.New("el driveletter no puede venir vacio")
-			}
-			cmd.path = value
-		default:
-			return "", fmt.Errorf("parametro desconocido: %s", key)
-		}
-	}
+func init() {
+	Register(Command{
+		Name:        "rmdisk",
+		Description: "Elimina un disco virtual y desmonta sus particiones",
+		Params:      rmdiskParams,
+		Run:         ParseRmdisk,
+	})
+}
 
-	if cmd.path == "" {
-		return "", errors.New("faltan parametros requeridos: -driveletter")
+func ParseRmdisk(session *stores.Session, tokens []string) (Result, error) {
+	args, err := rmdiskParams.Parse(tokens)
+	if err != nil {
+		return nil, err
 	}
-	cmd.path = stores.GetPathDisk(cmd.path)
-	err := commandRmdisk(cmd)
+	cmd := &RMDISK{path: stores.GetPathDisk(args.String("driveletter"))}
+
+	err = commandRmdisk(cmd)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	stores.DeleteMountedPartitions(cmd.path)
-	return fmt.Sprintf("RMDISK: %s eliminado exitosamente", cmd.path), nil
+	stores.SaveState()
+	return RmdiskResult{Disk: args.String("driveletter"), Path: cmd.path}, nil
 
 }
 
 func commandRmdisk(rmdisk *RMDISK) error {
 	if !fileExists(rmdisk.path) {
-		return fmt.Errorf("el archivo no existe en el path solicitado")
+		return structures.Errorf(structures.ErrNotFound, "el archivo no existe en el path solicitado")
 	}
 
 	// Eliminar del mapa de discos cargados antes de eliminar el archivo
-	for diskName, diskPath := range stores.LoadedDiskPaths {
-		if diskPath == rmdisk.path {
-			delete(stores.LoadedDiskPaths, diskName)
-			break
-		}
-	}
+	stores.RemoveLoadedDisk(rmdisk.path)
 
 	err := os.Remove(rmdisk.path)
 	if err != nil {
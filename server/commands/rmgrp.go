@@ -2,9 +2,8 @@ package commands
 
 import (
 	"encoding/binary"
-	"errors"
 	"fmt"
-	"regexp"
+	"server/lexer"
 	stores "server/stores"
 	"server/structures"
 	"strings"
@@ -15,65 +14,65 @@ type RMGRP struct {
 	name string
 }
 
-func ParseRmgrp(tokens []string) (string, error) {
-	cmd := &RMGRP{}
+type RmgrpResult struct {
+	Group string `json:"group"`
+}
 
-	args := strings.Join(tokens, " ")
-	re := regexp.MustCompile(`-name="[^"]+"|-name=[^\s]+`)
-	matches := re.FindAllString(args, -1)
+func (result RmgrpResult) String() string {
+	return fmt.Sprintf("RMGRP: grupo %s eliminado exitosamente", result.Group)
+}
 
-	for _, match := range matches {
-		kv := strings.SplitN(match, "=", 2)
-		if len(kv) != 2 {
-			return "", fmt.Errorf("formato de parametro invalido: %s", match)
-		}
-		key, value := strings.ToLower(kv[0]), kv[1]
+var rmgrpParams = lexer.Schema{
+	{Name: "name", Required: true, Description: "Nombre del grupo"},
+}
 
-		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
-			value = strings.Trim(value, "\"")
-		}
+func init() {
+	Register(Command{
+		Name:        "rmgrp",
+		Description: "Elimina un grupo, solo root",
+		Params:      rmgrpParams,
+		Run:         ParseRmgrp,
+	})
+}
 
-		switch key {
-		case "-name":
-			if value == "" {
-				return "", errors.New("el nombre no puede venir vacio")
-			}
-			cmd.name = value
-		default:
-			return "", fmt.Errorf("parametro desconocido: %s", key)
-		}
+func ParseRmgrp(session *stores.Session, tokens []string) (Result, error) {
+	args, err := rmgrpParams.Parse(tokens)
+	if err != nil {
+		return nil, err
 	}
+	cmd := &RMGRP{name: args.String("name")}
 
-	if cmd.name == "" {
-		return "", errors.New("parametro obligatorio: -name")
-	}
-	err := CommandRmgrp(cmd)
+	err = CommandRmgrp(session, cmd)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return fmt.Sprintf("RMGRP: grupo %s eliminado exitosamente", cmd.name), nil
+	return RmgrpResult{Group: cmd.name}, nil
 
 }
 
-func CommandRmgrp(rmgrp *RMGRP) error {
-	if stores.LogedIdPartition == "" {
-		return errors.New("no hay sesion activa")
+func CommandRmgrp(session *stores.Session, rmgrp *RMGRP) error {
+	if session.IdPartition == "" {
+		return structures.NewError(structures.ErrPermission, "no hay sesion activa")
 	}
-	if stores.LogedUser != "root" {
-		return errors.New("este comando solo lo puede ejecutar el usuario root")
+	if !session.IsRoot() {
+		return structures.NewError(structures.ErrPermission, "este comando solo lo puede ejecutar el usuario root")
 	}
-	contentUsersTxt, err := getContetnUsersTxt(stores.LogedIdPartition)
+	contentUsersTxt, err := getContetnUsersTxt(session.IdPartition)
 	if err != nil {
 		return err
 	}
 	contentMatrix := getContentMatrixUsers(contentUsersTxt)
 	outcome := removeGroup(rmgrp.name, contentMatrix)
 	if !outcome {
-		return errors.New("no existe el nombre del grupo a eliminar")
+		return structures.NewError(structures.ErrNotFound, "no existe el nombre del grupo a eliminar")
 	}
 	contentUsersTxt = reformUserstxt(contentMatrix)
-	partitionSuperblock, mountedPartition, partitionPath, err := stores.GetMountedPartitionSuperblock(stores.LogedIdPartition)
+	partitionSuperblock, mountedPartition, partitionPath, err := stores.GetMountedPartitionSuperblock(session.IdPartition)
+	if err != nil {
+		return err
+	}
+	err = checkJournalSpace(partitionSuperblock, partitionPath, mountedPartition, 1)
 	if err != nil {
 		return err
 	}
@@ -111,7 +110,8 @@ func reformUserstxt(matrix [][]string) string {
 	for _, row := range matrix {
 		onlyRows = append(onlyRows, strings.Join(row, ","))
 	}
-	fullContent := strings.Join(onlyRows, "\n")
+	// Cada registro termina en salto de linea, getContentMatrixUsers descarta lo que sigue al ultimo
+	fullContent := strings.Join(onlyRows, "\n") + "\n"
 	return fullContent
 }
 
@@ -2,12 +2,10 @@ package commands
 
 import (
 	"encoding/binary"
-	"errors"
 	"fmt"
-	"regexp"
+	"server/lexer"
 	stores "server/stores"
 	"server/structures"
-	"strings"
 	"time"
 )
 
@@ -15,58 +13,53 @@ type RMUSR struct {
 	user string
 }
 
-func ParseRmusr(tokens []string) (string, error) {
-	cmd := &RMUSR{}
+type RmusrResult struct {
+	User string `json:"user"`
+}
 
-	args := strings.Join(tokens, " ")
-	re := regexp.MustCompile(`-user="[^"]+"|-user=[^\s]+`)
-	matches := re.FindAllString(args, -1)
+func (result RmusrResult) String() string {
+	return fmt.Sprintf("RMUSR: usuario %s eliminado exitosamente", result.User)
+}
 
-	for _, match := range matches {
-		kv := strings.SplitN(match, "=", 2)
-		if len(kv) != 2 {
-			return "", fmt.Errorf("formato de parametro invalido: %s", match)
-		}
-		key, value := strings.ToLower(kv[0]), kv[1]
+var rmusrParams = lexer.Schema{
+	{Name: "user", Required: true, Description: "Nombre del usuario"},
+}
 
-		if strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"") {
-			value = strings.Trim(value, "\"")
-		}
+func init() {
+	Register(Command{
+		Name:        "rmusr",
+		Description: "Elimina un usuario, solo root",
+		Params:      rmusrParams,
+		Run:         ParseRmusr,
+	})
+}
 
-		switch key {
-		case "-user":
-			if value == "" {
-				return "", errors.New("el user no puede estar vacio")
-			}
-			if len(value) > 10 {
-				return "", errors.New("el user de usuario no se puede exceder de 10 caracteres")
-			}
-			cmd.user = value
-		default:
-			return "", fmt.Errorf("parametro desconocido: %s", key)
-		}
+func ParseRmusr(session *stores.Session, tokens []string) (Result, error) {
+	args, err := rmusrParams.Parse(tokens)
+	if err != nil {
+		return nil, err
 	}
-
-	if cmd.user == "" {
-		return "", errors.New("faltan parametros requeridos: -user")
+	cmd := &RMUSR{user: args.String("user")}
+	if len(cmd.user) > 10 {
+		return nil, structures.NewError(structures.ErrInvalid, "el user de usuario no se puede exceder de 10 caracteres")
 	}
 
-	err := CommandoRmusr(cmd)
+	err = CommandoRmusr(session, cmd)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return fmt.Sprintf("RMUSR: usuario %s eliminado exitosamente", cmd.user), nil
+	return RmusrResult{User: cmd.user}, nil
 }
 
-func CommandoRmusr(rmusr *RMUSR) error {
-	if stores.LogedIdPartition == "" {
-		return errors.New("no hay sesion activa")
+func CommandoRmusr(session *stores.Session, rmusr *RMUSR) error {
+	if session.IdPartition == "" {
+		return structures.NewError(structures.ErrPermission, "no hay sesion activa")
 	}
-	if stores.LogedUser != "root" {
-		return errors.New("este comando solo lo puede ejecutar el usuario root")
+	if !session.IsRoot() {
+		return structures.NewError(structures.ErrPermission, "este comando solo lo puede ejecutar el usuario root")
 	}
-	contentUsersTxt, err := getContetnUsersTxt(stores.LogedIdPartition)
+	contentUsersTxt, err := getContetnUsersTxt(session.IdPartition)
 	if err != nil {
 		return err
 	}
@@ -74,10 +67,14 @@ func CommandoRmusr(rmusr *RMUSR) error {
 
 	outcome := removeUser(rmusr.user, contentMatrix)
 	if !outcome {
-		return errors.New("el nombre de usuario no existe")
+		return structures.NewError(structures.ErrNotFound, "el nombre de usuario no existe")
 	}
 	contentUsersTxt = reformUserstxt(contentMatrix)
-	partitionSuperblock, mountedPartition, partitionPath, err := stores.GetMountedPartitionSuperblock(stores.LogedIdPartition)
+	partitionSuperblock, mountedPartition, partitionPath, err := stores.GetMountedPartitionSuperblock(session.IdPartition)
+	if err != nil {
+		return err
+	}
+	err = checkJournalSpace(partitionSuperblock, partitionPath, mountedPartition, 1)
 	if err != nil {
 		return err
 	}
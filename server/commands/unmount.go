@@ -1,60 +1,60 @@
 package commands
 
 import (
-	"errors"
 	"fmt"
-	"regexp"
+	"server/lexer"
 	"server/stores"
 	"server/structures"
-	"server/utils"
-	"strings"
 )
 
 type UNMOUNT struct {
 	id string
 }
 
+type UnmountResult struct {
+	ID string `json:"id"`
+}
+
+func (result UnmountResult) String() string {
+	return fmt.Sprintf("UNMOUNT: %s desmontado exitosamente", result.ID)
+}
+
 // Validar si esta montada
 // Cambiar el valor del estado a 0
 
-func ParseUnmount(tokens []string) (string, error) {
-	cmd := &UNMOUNT{}
-	args := strings.Join(tokens, " ")
-	re := regexp.MustCompile(`-id=[a-zA-Z0-9]+`)
-	matches := re.FindAllString(args, -1)
+var unmountParams = lexer.Schema{
+	{Name: "id", Required: true, Description: "Id de la particion montada"},
+}
 
-	for _, match := range matches {
-		kv := strings.SplitN(match, "=", 2)
-		if len(kv) != 2 {
-			return "", fmt.Errorf("formato de parametro invalido: %s", match)
-		}
-		key, value := strings.ToLower(kv[0]), kv[1]
-		switch key {
-		case "-id":
-			if value == "" {
-				return "", errors.New("el id no puede estar vacio")
-			}
-			cmd.id = value
-		default:
-			return "", fmt.Errorf("parametro desconocido: %s", key)
+func init() {
+	Register(Command{
+		Name:        "unmount",
+		Description: "Desmonta una particion",
+		Params:      unmountParams,
+		Run:         ParseUnmount,
+	})
+}
 
-		}
-	}
-	if cmd.id == "" {
-		return "", errors.New("faltan parametros requeridos: -id")
+func ParseUnmount(session *stores.Session, tokens []string) (Result, error) {
+	args, err := unmountParams.Parse(tokens)
+	if err != nil {
+		return nil, err
 	}
-	err := CommandUnmount(cmd)
+	cmd := &UNMOUNT{id: args.String("id")}
+
+	err = CommandUnmount(cmd)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	return fmt.Sprintf("UNMOUNT: %s desmontado exitosamente", cmd.id), nil
+	stores.SaveState()
+	return UnmountResult{ID: cmd.id}, nil
 
 }
 
 func CommandUnmount(unmount *UNMOUNT) error {
-	diskPath := stores.MountedPartitions[unmount.id]
+	diskPath := stores.MountedDiskPath(unmount.id)
 	if diskPath == "" {
-		return errors.New("id de particion no montada")
+		return structures.NewError(structures.ErrNotMounted, "id de particion no montada")
 	}
 	mbr := &structures.MBR{}
 	err := mbr.DeserializeMBR(diskPath)
@@ -63,18 +63,30 @@ func CommandUnmount(unmount *UNMOUNT) error {
 	}
 	partition, index, err := mbr.GetPartitionByID(unmount.id)
 	if err != nil {
-		return err
-	}
-	if partition.Part_status[0] == '0' {
-		return errors.New("no se puede desmontar una particion no montada")
+		err = unmountLogicalPartition(mbr, diskPath, unmount.id)
+		if err != nil {
+			return err
+		}
+	} else {
+		if partition.Part_status[0] == '0' {
+			return structures.NewError(structures.ErrNotMounted, "no se puede desmontar una particion no montada")
+		}
+		partition.Part_status[0] = '0'
+		mbr.Mbr_partitions[index] = *partition
+		err = mbr.SerializeMBR(diskPath)
+		if err != nil {
+			return err
+		}
 	}
-	partition.Part_status[0] = '0'
-	mbr.Mbr_partitions[index] = *partition
-	err = mbr.SerializeMBR(diskPath)
+	stores.RemoveMountedPartition(unmount.id)
+	return nil
+}
+
+func unmountLogicalPartition(mbr *structures.MBR, diskPath, id string) error {
+	ebr, offset, err := mbr.GetLogicalPartitionByID(diskPath, id)
 	if err != nil {
 		return err
 	}
-	utils.PathToPartitionCount[diskPath] -= 1
-	delete(stores.MountedPartitions, unmount.id)
-	return nil
+	ebr.Part_mount[0] = '0'
+	return ebr.Serialize(diskPath, int64(offset))
 }
@@ -0,0 +1,125 @@
+package commands
+
+import (
+	"errors"
+	"server/stores"
+	"server/structures"
+	"server/utils"
+	"strings"
+	"testing"
+)
+
+// newUsersDisk formatea una particion EXT3 con root y el usuario ana, la sesion queda como root
+func newUsersDisk(t *testing.T) *stores.Session {
+	t.Helper()
+	newTestState(t)
+	session := &stores.Session{}
+	run(t, session,
+		"mkdisk -size=1 -unit=M",
+		"fdisk -size=800 -unit=K -driveletter=A -name=P1",
+		"mount -driveletter=A -name=P1",
+		"mkfs -id=A105 -fs=3fs",
+		"login -user=root -pass=123 -id=A105",
+		"mkgrp -name=devs",
+		"mkusr -user=ana -pass=clave -grp=devs",
+	)
+	return session
+}
+
+// Los permisos de root van por el UID, un usuario que se llame root sin serlo no los tiene
+func TestRootByUID(t *testing.T) {
+	session := newUsersDisk(t)
+	impostor := *session
+	impostor.UserID, impostor.GroupID = 2, 2
+	for _, line := range []string{"mkgrp -name=otro", "mkusr -user=b -pass=b -grp=devs", "rmusr -user=ana", "chgrp -user=ana -grp=root", "passwd -user=ana -pass=x"} {
+		if _, err := tryRun(&impostor, line); !errors.Is(err, structures.ErrPermission) {
+			t.Errorf("%s: se esperaba ErrPermission y llego %v", line, err)
+		}
+	}
+	result, err := tryRun(&impostor, "cat -file1=/users.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content := result.String(); !strings.Contains(content, utils.HiddenPassword) || strings.Contains(content, "$p$") {
+		t.Errorf("un usuario que no es root ve los passwords:\n%s", content)
+	}
+}
+
+// Un password con forma de hash se guarda hasheado como cualquier otro, solo entra con ese mismo texto
+func TestPasswordShapedLikeHash(t *testing.T) {
+	session := newUsersDisk(t)
+	run(t, session,
+		"mkusr -user=luis -pass=$p$AA$AA -grp=devs",
+		"passwd -user=ana -pass=$p$BB$BB",
+		"logout",
+	)
+	for user, password := range map[string]string{"luis": "$p$AA$AA", "ana": "$p$BB$BB"} {
+		for _, wrong := range []string{"x23", "x201", "x377", "AA", "BB"} {
+			if _, err := tryRun(session, "login -user="+user+" -pass="+wrong+" -id=A105"); !errors.Is(err, structures.ErrPermission) {
+				t.Fatalf("%s entro con %s: %v", user, wrong, err)
+			}
+		}
+		run(t, session, "login -user="+user+" -pass="+password+" -id=A105", "logout")
+	}
+	for _, line := range strings.Split(readFile(t, "A105", "/users.txt"), "\n") {
+		if fields := strings.Split(line, ","); len(fields) == 5 && !utils.IsPasswordHash(fields[4]) {
+			t.Errorf("el password de %s no quedo hasheado: %s", fields[3], fields[4])
+		}
+	}
+}
+
+// Sin users.txt nadie puede volver a iniciar sesion, ni root
+func TestRemoveUsersFile(t *testing.T) {
+	session := newUsersDisk(t)
+	run(t, session, "logout", "login -user=ana -pass=clave -id=A105", "mkfile -path=/ana.txt -size=4")
+	if _, err := tryRun(session, "remove -path=/users.txt"); !errors.Is(err, structures.ErrPermission) {
+		t.Fatalf("ana elimino users.txt: %v", err)
+	}
+	run(t, session, "remove -path=/ana.txt", "logout", "login -user=root -pass=123 -id=A105")
+	if _, err := tryRun(session, "remove -path=/users.txt"); !errors.Is(err, structures.ErrPermission) {
+		t.Fatalf("root elimino users.txt: %v", err)
+	}
+	run(t, session, "logout", "login -user=ana -pass=clave -id=A105")
+	checkFsck(t, "A105")
+}
+
+// Una copia de users.txt en otra ruta ya no se reconoceria como users.txt y cat mostraria los passwords
+func TestMoveRenameUsersFile(t *testing.T) {
+	session := newUsersDisk(t)
+	run(t, session, "mkdir -path=/tmp", "chmod -path=/tmp -ugo=777", "logout", "login -user=ana -pass=clave -id=A105")
+	for _, line := range []string{"move -path=/users.txt -destino=/tmp", "rename -path=/users.txt -name=u.txt", "copy -path=/users.txt -destino=/tmp"} {
+		if _, err := tryRun(session, line); !errors.Is(err, structures.ErrPermission) {
+			t.Fatalf("%s: se esperaba ErrPermission y llego %v", line, err)
+		}
+	}
+	sb, _, diskPath, err := stores.GetMountedPartitionSuperblock("A105")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := sb.Lookup(diskPath, "/tmp/users.txt", 1, 1); err == nil {
+		t.Fatal("quedo una copia de users.txt en /tmp")
+	}
+	run(t, session, "logout", "login -user=root -pass=123 -id=A105")
+	checkFsck(t, "A105")
+}
+
+// rename cambia una entrada de la carpeta padre, el permiso que cuenta es el de ella
+func TestRenameNeedsParentWrite(t *testing.T) {
+	session := newUsersDisk(t)
+	run(t, session,
+		"mkdir -path=/cerrada",
+		"mkfile -path=/cerrada/a.txt -size=3",
+		"chown -r -path=/cerrada -usuario=ana",
+		"chmod -path=/cerrada -ugo=555",
+		"chmod -path=/cerrada/a.txt -ugo=777",
+		"logout",
+		"login -user=ana -pass=clave -id=A105",
+	)
+	if _, err := tryRun(session, "rename -path=/cerrada/a.txt -name=b.txt"); !errors.Is(err, structures.ErrPermission) {
+		t.Fatalf("se renombro dentro de una carpeta sin escritura: %v", err)
+	}
+	run(t, session, "chmod -path=/cerrada -ugo=755", "chmod -path=/cerrada/a.txt -ugo=444", "rename -path=/cerrada/a.txt -name=b.txt")
+	if readFile(t, "A105", "/cerrada/b.txt") != "012" {
+		t.Fatal("no se renombro a.txt")
+	}
+}
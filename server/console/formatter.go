@@ -63,14 +63,23 @@ func PrintWelcome() {
 	PrintSeparator()
 }
 
-func PrintPrompt() {
-	fmt.Printf("%s%s[%s%sMIA%s%s]%s%s $ %s",
+func Prompt() string {
+	return fmt.Sprintf("%s%s[%s%sMIA%s%s]%s%s $ %s",
 		Bold, BrightBlue,
 		BrightMagenta, Bold,
 		Reset, Bold, BrightBlue,
 		BrightGreen, Reset)
 }
 
+func PrintPrompt() {
+	fmt.Print(Prompt())
+}
+
+// ClearScreen limpia la terminal con ANSI, sin depender de clear o cls
+func ClearScreen() {
+	fmt.Print("\033[H\033[2J")
+}
+
 func PrintSuccess(message string) {
 	fmt.Printf("%s%s✅ %s%s\n", Bold, BrightGreen, message, Reset)
 }
@@ -0,0 +1,275 @@
+package console
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+const maxHistory = 500
+
+// ErrInterrupted es Ctrl+C, la linea se descarta y se vuelve a pedir
+var ErrInterrupted = errors.New("linea cancelada")
+
+// Completer recibe la linea hasta el cursor y devuelve donde empieza la palabra a completar y las opciones
+type Completer func(line string) (int, []string)
+
+// LineEditor lee lineas de la terminal en modo raw con historial, edicion con el cursor y Tab para completar
+type LineEditor struct {
+	historyPath string
+	history     []string
+	complete    Completer
+	reader      *bufio.Reader
+
+	line  []rune
+	pos   int
+	saved []rune //linea que se estaba escribiendo antes de recorrer el historial
+}
+
+// IsTerminal dice si la entrada es una terminal, con un archivo o una tuberia se leen lineas simples
+func IsTerminal() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// NewLineEditor carga el historial de historyPath, si no se puede leer empieza vacio
+func NewLineEditor(historyPath string, complete Completer) *LineEditor {
+	editor := &LineEditor{historyPath: historyPath, complete: complete, reader: bufio.NewReader(os.Stdin)}
+	content, err := os.ReadFile(historyPath)
+	if err == nil {
+		for _, line := range strings.Split(string(content), "\n") {
+			if line != "" {
+				editor.history = append(editor.history, line)
+			}
+		}
+		if len(editor.history) > maxHistory {
+			editor.history = editor.history[len(editor.history)-maxHistory:]
+		}
+	}
+	return editor
+}
+
+// ReadLine muestra el prompt y lee una linea. La terminal solo queda en modo raw mientras se escribe,
+// asi los comandos imprimen normal. Devuelve io.EOF con Ctrl+D en una linea vacia.
+func (e *LineEditor) ReadLine() (string, error) {
+	fd := int(os.Stdin.Fd())
+	oldState, err := term.MakeRaw(fd)
+	if err != nil {
+		return "", err
+	}
+	defer term.Restore(fd, oldState)
+
+	e.line, e.pos, e.saved = nil, 0, nil
+	historyIndex := len(e.history)
+	e.refresh()
+
+	for {
+		r, _, err := e.reader.ReadRune()
+		if err != nil {
+			fmt.Print("\r\n")
+			return "", err
+		}
+		switch r {
+		case '\r', '\n':
+			fmt.Print("\r\n")
+			return string(e.line), nil
+		case 3: //Ctrl+C
+			fmt.Print("^C\r\n")
+			return "", ErrInterrupted
+		case 4: //Ctrl+D
+			if len(e.line) == 0 {
+				fmt.Print("\r\n")
+				return "", io.EOF
+			}
+			e.deleteAt(e.pos)
+		case 127, 8: //Backspace
+			if e.pos > 0 {
+				e.pos--
+				e.deleteAt(e.pos)
+			}
+		case 9: //Tab
+			e.completeWord()
+		case 1: //Ctrl+A
+			e.pos = 0
+		case 5: //Ctrl+E
+			e.pos = len(e.line)
+		case 2: //Ctrl+B
+			e.moveCursor(-1)
+		case 6: //Ctrl+F
+			e.moveCursor(1)
+		case 11: //Ctrl+K
+			e.line = e.line[:e.pos]
+		case 21: //Ctrl+U
+			e.line = append([]rune{}, e.line[e.pos:]...)
+			e.pos = 0
+		case 23: //Ctrl+W
+			e.deleteWord()
+		case 12: //Ctrl+L
+			ClearScreen()
+		case 16: //Ctrl+P
+			historyIndex = e.showHistory(historyIndex - 1)
+		case 14: //Ctrl+N
+			historyIndex = e.showHistory(historyIndex + 1)
+		case 27: //ESC, secuencia de una tecla especial
+			switch e.readEscape() {
+			case "[A", "OA":
+				historyIndex = e.showHistory(historyIndex - 1)
+			case "[B", "OB":
+				historyIndex = e.showHistory(historyIndex + 1)
+			case "[C", "OC":
+				e.moveCursor(1)
+			case "[D", "OD":
+				e.moveCursor(-1)
+			case "[H", "OH", "[1~", "[7~":
+				e.pos = 0
+			case "[F", "OF", "[4~", "[8~":
+				e.pos = len(e.line)
+			case "[3~":
+				e.deleteAt(e.pos)
+			}
+		default:
+			if r >= 32 {
+				e.insert([]rune{r})
+			}
+		}
+		e.refresh()
+	}
+}
+
+// AddHistory agrega la linea al historial y al archivo, no repite la ultima
+func (e *LineEditor) AddHistory(line string) {
+	if line == "" || (len(e.history) > 0 && e.history[len(e.history)-1] == line) {
+		return
+	}
+	e.history = append(e.history, line)
+	if len(e.history) > maxHistory {
+		e.history = e.history[len(e.history)-maxHistory:]
+	}
+	os.MkdirAll(filepath.Dir(e.historyPath), 0755)
+	file, err := os.OpenFile(e.historyPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	fmt.Fprintln(file, line)
+}
+
+// refresh redibuja el prompt y la linea, y deja el cursor en su posicion
+func (e *LineEditor) refresh() {
+	fmt.Printf("\r%s%s\033[K", Prompt(), string(e.line))
+	if back := len(e.line) - e.pos; back > 0 {
+		fmt.Printf("\033[%dD", back)
+	}
+}
+
+// readEscape lee lo que sigue a ESC, como [A para la flecha arriba o [3~ para suprimir
+func (e *LineEditor) readEscape() string {
+	first, _, err := e.reader.ReadRune()
+	if err != nil || (first != '[' && first != 'O') {
+		return ""
+	}
+	sequence := []rune{first}
+	for {
+		r, _, err := e.reader.ReadRune()
+		if err != nil {
+			return ""
+		}
+		sequence = append(sequence, r)
+		if r >= 0x40 && r <= 0x7e {
+			return string(sequence)
+		}
+	}
+}
+
+func (e *LineEditor) insert(text []rune) {
+	line := append([]rune{}, e.line[:e.pos]...)
+	line = append(line, text...)
+	e.line = append(line, e.line[e.pos:]...)
+	e.pos += len(text)
+}
+
+func (e *LineEditor) deleteAt(pos int) {
+	if pos < len(e.line) {
+		e.line = append(e.line[:pos], e.line[pos+1:]...)
+	}
+}
+
+func (e *LineEditor) deleteWord() {
+	start := e.pos
+	for start > 0 && e.line[start-1] == ' ' {
+		start--
+	}
+	for start > 0 && e.line[start-1] != ' ' {
+		start--
+	}
+	e.line = append(e.line[:start], e.line[e.pos:]...)
+	e.pos = start
+}
+
+func (e *LineEditor) moveCursor(delta int) {
+	e.pos = max(0, min(len(e.line), e.pos+delta))
+}
+
+// showHistory pone la entrada index del historial, len(history) es la linea que se estaba escribiendo
+func (e *LineEditor) showHistory(index int) int {
+	if index < 0 || index > len(e.history) {
+		return max(0, min(len(e.history), index))
+	}
+	if index < len(e.history) && e.saved == nil {
+		e.saved = append([]rune{}, e.line...)
+	}
+	if index == len(e.history) {
+		e.line = e.saved
+		e.saved = nil
+	} else {
+		e.line = []rune(e.history[index])
+	}
+	e.pos = len(e.line)
+	return index
+}
+
+// completeWord completa la palabra del cursor: con una opcion la escribe entera,
+// con varias escribe lo que tienen en comun y si no avanza las lista
+func (e *LineEditor) completeWord() {
+	if e.complete == nil {
+		return
+	}
+	before := string(e.line[:e.pos])
+	start, options := e.complete(before)
+	if len(options) == 0 {
+		return
+	}
+	start = len([]rune(before[:start]))
+	word := string(e.line[start:e.pos])
+
+	completion := commonPrefix(options)
+	if len(options) == 1 && !strings.HasSuffix(completion, "/") && !strings.HasSuffix(completion, "=") {
+		completion += " "
+	}
+	if len([]rune(completion)) > len([]rune(word)) {
+		e.line = append(e.line[:start], e.line[e.pos:]...)
+		e.pos = start
+		e.insert([]rune(completion))
+		return
+	}
+	if len(options) > 1 {
+		fmt.Printf("\r\n%s\r\n", strings.Join(options, "  "))
+	}
+}
+
+func commonPrefix(options []string) string {
+	prefix := options[0]
+	for _, option := range options[1:] {
+		for !strings.HasPrefix(option, prefix) {
+			_, size := utf8.DecodeLastRuneInString(prefix)
+			prefix = prefix[:len(prefix)-size]
+		}
+	}
+	return prefix
+}
@@ -2,6 +2,8 @@ module server
 
 go 1.23.6
 
+require golang.org/x/term v0.27.0
+
 require (
 	github.com/andybalholm/brotli v1.1.0 // indirect
 	github.com/gofiber/fiber/v2 v2.52.6 // indirect
@@ -0,0 +1,84 @@
+package lexer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrSyntax es el tipo de los errores de Tokenize y Schema.Parse, se compara con errors.Is
+var ErrSyntax = errors.New("error de sintaxis")
+
+type syntaxError struct {
+	message string
+}
+
+func (e *syntaxError) Error() string {
+	return e.message
+}
+
+func (e *syntaxError) Unwrap() error {
+	return ErrSyntax
+}
+
+func syntaxErrorf(format string, args ...any) error {
+	return &syntaxError{message: fmt.Sprintf(format, args...)}
+}
+
+/*
+Tokenize separa una linea de comando en tokens:
+
+	los espacios separan tokens, salvo dentro de comillas dobles o simples
+	las comillas se quitan: -path="/mis documentos/a.txt" queda como -path=/mis documentos/a.txt
+	\ escapa el siguiente caracter fuera de comillas y dentro de comillas dobles
+	# al inicio de un token y fuera de comillas empieza un comentario hasta el final de la linea
+*/
+func Tokenize(line string) ([]string, error) {
+	var tokens []string
+	var current strings.Builder
+	inToken := false
+	var quote rune
+
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else if r == '\\' && quote == '"' && i+1 < len(runes) {
+				i++
+				current.WriteRune(runes[i])
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inToken = true
+		case r == '\\':
+			if i+1 < len(runes) {
+				i++
+				current.WriteRune(runes[i])
+			}
+			inToken = true
+		case r == ' ' || r == '\t' || r == '\r' || r == '\n':
+			if inToken {
+				tokens = append(tokens, current.String())
+				current.Reset()
+				inToken = false
+			}
+		case r == '#' && !inToken:
+			return tokens, nil
+		default:
+			current.WriteRune(r)
+			inToken = true
+		}
+	}
+	if quote != 0 {
+		return nil, syntaxErrorf("comillas sin cerrar en el comando")
+	}
+	if inToken {
+		tokens = append(tokens, current.String())
+	}
+	return tokens, nil
+}
@@ -0,0 +1,120 @@
+package lexer
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	for _, c := range []struct {
+		line   string
+		tokens []string
+	}{
+		{"mkdisk -size=5 -unit=M", []string{"mkdisk", "-size=5", "-unit=M"}},
+		{"  mkdir\t-r   -path=/a  ", []string{"mkdir", "-r", "-path=/a"}},
+		{`mkdir -path="/home/mis documentos"`, []string{"mkdir", "-path=/home/mis documentos"}},
+		{`mkdir -path='/home/mis documentos'`, []string{"mkdir", "-path=/home/mis documentos"}},
+		{`mkfile -path="/a b/c d.txt" -size=10`, []string{"mkfile", "-path=/a b/c d.txt", "-size=10"}},
+		{`mkdir -path="/dice \"hola\""`, []string{"mkdir", `-path=/dice "hola"`}},
+		{`mkdir -path='/sin\escape'`, []string{"mkdir", `-path=/sin\escape`}},
+		{`mkdir -path=/mis\ documentos`, []string{"mkdir", "-path=/mis documentos"}},
+		{`mkdir -path="/con 'simples' adentro"`, []string{"mkdir", "-path=/con 'simples' adentro"}},
+		{`mkdir -path=""`, []string{"mkdir", "-path="}},
+		{"mount -driveletter=A -name=P1 # montar la primera", []string{"mount", "-driveletter=A", "-name=P1"}},
+		{"# solo un comentario", nil},
+		{"mkdir -path=/a#b", []string{"mkdir", "-path=/a#b"}},
+		{`mkdir -path="/a #b"`, []string{"mkdir", "-path=/a #b"}},
+		{"", nil},
+	} {
+		tokens, err := Tokenize(c.line)
+		if err != nil {
+			t.Errorf("%s: %v", c.line, err)
+			continue
+		}
+		if !reflect.DeepEqual(tokens, c.tokens) {
+			t.Errorf("%s: se esperaba %q y llego %q", c.line, c.tokens, tokens)
+		}
+	}
+}
+
+func TestTokenizeUnterminatedQuote(t *testing.T) {
+	for _, line := range []string{
+		`mkdir -path="/sin cerrar`,
+		`mkdir -path='/sin cerrar`,
+		`mkdir -path="/escapada\"`,
+	} {
+		_, err := Tokenize(line)
+		if !errors.Is(err, ErrSyntax) {
+			t.Errorf("%s: se esperaba ErrSyntax y llego %v", line, err)
+		}
+	}
+}
+
+var testSchema = Schema{
+	{Name: "size", Kind: Int, Required: true},
+	{Name: "unit", Default: "M", Values: []string{"B", "K", "M"}},
+	{Name: "driveletter", Kind: Letter},
+	{Name: "r", Kind: Flag},
+	{Name: "path"},
+	{Name: "file", Numbered: true},
+}
+
+func TestSchemaParse(t *testing.T) {
+	args, err := testSchema.Parse([]string{"-SIZE=10", "-unit=k", "-driveletter=b", "-r", "-path=/mis documentos", "-file2=/b.txt", "-file1=/a.txt", "-file10=/c.txt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if args.Int("size") != 10 || args.String("unit") != "K" || args.String("driveletter") != "B" || !args.Flag("r") || args.String("path") != "/mis documentos" {
+		t.Errorf("valores incorrectos: %+v", args)
+	}
+	if files := args.Numbered("file"); !reflect.DeepEqual(files, []string{"/a.txt", "/b.txt", "/c.txt"}) {
+		t.Errorf("los numerados deben ir por numero y llegaron %q", files)
+	}
+
+	args, err = testSchema.Parse([]string{"-size=3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if args.String("unit") != "M" || !args.Has("unit") || args.Flag("r") || args.Has("path") || len(args.Numbered("file")) != 0 {
+		t.Errorf("valores por defecto incorrectos: %+v", args)
+	}
+}
+
+func TestSchemaParseErrors(t *testing.T) {
+	for _, tokens := range [][]string{
+		{"-unit=K"},                         // falta -size
+		{"-size=abc"},                       // no es entero
+		{"-size=1.5"},                       // no es entero
+		{"-size="},                          // vacio
+		{"-size"},                           // sin valor
+		{"-size=1", "-unit=G"},              // no es un valor permitido
+		{"-size=1", "-driveletter=AB"},      // mas de una letra
+		{"-size=1", "-driveletter=1"},       // no es letra
+		{"-size=1", "-r=true"},              // un flag no recibe valor
+		{"-size=1", "-color=rojo"},          // desconocido
+		{"-size=1", "size=2"},               // sin guion
+		{"-size=1", "-"},                    // sin nombre
+		{"-size=1", "-size=2"},              // repetido
+		{"-size=1", "-SIZE=2"},              // repetido sin distinguir mayusculas
+		{"-size=1", "-r", "-r"},             // flag repetido
+		{"-size=1", "-file1=a", "-file1=b"}, // numerado repetido
+		{"-size=1", "-file0=a"},             // los numerados empiezan en 1
+		{"-size=1", "-file=a"},              // numerado sin numero
+	} {
+		_, err := testSchema.Parse(tokens)
+		if !errors.Is(err, ErrSyntax) {
+			t.Errorf("%q: se esperaba ErrSyntax y llego %v", tokens, err)
+		}
+	}
+}
+
+func TestSchemaRequiredNumbered(t *testing.T) {
+	schema := Schema{{Name: "file", Numbered: true, Required: true}}
+	if _, err := schema.Parse(nil); !errors.Is(err, ErrSyntax) {
+		t.Errorf("sin -fileN se esperaba ErrSyntax y llego %v", err)
+	}
+	if _, err := schema.Parse([]string{"-file3=/a.txt"}); err != nil {
+		t.Errorf("-file3 deberia bastar: %v", err)
+	}
+}
@@ -0,0 +1,240 @@
+package lexer
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type Kind int
+
+const (
+	String Kind = iota
+	Int
+	Flag   //sin valor, como -r
+	Letter //una letra de disco, se devuelve en mayuscula
+)
+
+var kindNames = [...]string{String: "string", Int: "int", Flag: "flag", Letter: "letter"}
+
+func (kind Kind) String() string {
+	return kindNames[kind]
+}
+
+// MarshalText hace que /api/commands devuelva el tipo por nombre
+func (kind Kind) MarshalText() ([]byte, error) {
+	return []byte(kind.String()), nil
+}
+
+// Param describe un parametro de un comando, Name va sin el guion
+type Param struct {
+	Name        string   `json:"name"`
+	Kind        Kind     `json:"type"`
+	Required    bool     `json:"required"`
+	Default     string   `json:"default,omitempty"`
+	Values      []string `json:"values,omitempty"` //valores permitidos sin distinguir mayusculas, se devuelve el de esta lista
+	Numbered    bool     `json:"numbered"`         //se escribe con un numero al final, como -file1 -file2
+	Path        bool     `json:"path,omitempty"`   //ruta dentro de la particion de la sesion, la consola la completa con Tab
+	Description string   `json:"description"`
+}
+
+// Schema son los parametros que acepta un comando
+type Schema []Param
+
+// Args son los valores ya validados de un comando
+type Args struct {
+	values   map[string]string
+	numbered map[string]map[int]string
+}
+
+// Parse valida los tokens contra el esquema y aplica los valores por defecto
+func (schema Schema) Parse(tokens []string) (Args, error) {
+	args := Args{values: make(map[string]string), numbered: make(map[string]map[int]string)}
+	for _, token := range tokens {
+		key, value, hasValue := strings.Cut(token, "=")
+		if !strings.HasPrefix(key, "-") || len(key) < 2 {
+			return args, syntaxErrorf("parametro invalido: %s", token)
+		}
+		name := strings.ToLower(key[1:])
+		param, number, found := schema.find(name)
+		if !found {
+			return args, syntaxErrorf("parametro desconocido: %s", strings.ToLower(key))
+		}
+
+		if args.has(param.Name, number) {
+			return args, syntaxErrorf("parametro repetido: %s", strings.ToLower(key))
+		}
+
+		if param.Kind == Flag {
+			if hasValue {
+				return args, syntaxErrorf("el parametro -%s no recibe valor", param.Name)
+			}
+			args.values[param.Name] = "true"
+			continue
+		}
+		if !hasValue {
+			return args, syntaxErrorf("el parametro -%s necesita un valor", name)
+		}
+		value, err := param.validate(value)
+		if err != nil {
+			return args, err
+		}
+		if param.Numbered {
+			if args.numbered[param.Name] == nil {
+				args.numbered[param.Name] = make(map[int]string)
+			}
+			args.numbered[param.Name][number] = value
+			continue
+		}
+		args.values[param.Name] = value
+	}
+
+	for _, param := range schema {
+		if param.Numbered {
+			if param.Required && len(args.numbered[param.Name]) == 0 {
+				return args, syntaxErrorf("faltan parametros requeridos: -%sN", param.Name)
+			}
+			continue
+		}
+		if _, exists := args.values[param.Name]; exists {
+			continue
+		}
+		if param.Required {
+			return args, syntaxErrorf("faltan parametros requeridos: -%s", param.Name)
+		}
+		if param.Default != "" {
+			args.values[param.Name] = param.Default
+		}
+	}
+	return args, nil
+}
+
+// Lookup busca el parametro por nombre sin guion, los numerados con su numero como file1
+func (schema Schema) Lookup(name string) (Param, bool) {
+	param, _, found := schema.find(strings.ToLower(name))
+	return param, found
+}
+
+// find busca el parametro por nombre, los numerados traen el numero al final
+func (schema Schema) find(name string) (Param, int, bool) {
+	for _, param := range schema {
+		if !param.Numbered {
+			if param.Name == name {
+				return param, 0, true
+			}
+			continue
+		}
+		suffix, found := strings.CutPrefix(name, param.Name)
+		if !found {
+			continue
+		}
+		number, err := strconv.Atoi(suffix)
+		if err == nil && number > 0 {
+			return param, number, true
+		}
+	}
+	return Param{}, 0, false
+}
+
+func (param Param) validate(value string) (string, error) {
+	if value == "" {
+		return "", syntaxErrorf("el parametro -%s no puede estar vacio", param.Name)
+	}
+	switch param.Kind {
+	case Int:
+		if _, err := strconv.Atoi(value); err != nil {
+			return "", syntaxErrorf("el parametro -%s debe ser un numero entero: %s", param.Name, value)
+		}
+	case Letter:
+		if len(value) != 1 || !strings.ContainsAny(strings.ToUpper(value), "ABCDEFGHIJKLMNOPQRSTUVWXYZ") {
+			return "", syntaxErrorf("el parametro -%s debe ser una letra: %s", param.Name, value)
+		}
+		value = strings.ToUpper(value)
+	}
+	if len(param.Values) == 0 {
+		return value, nil
+	}
+	for _, allowed := range param.Values {
+		if strings.EqualFold(allowed, value) {
+			return allowed, nil
+		}
+	}
+	return "", syntaxErrorf("el parametro -%s debe ser %s: %s", param.Name, strings.Join(param.Values, ", "), value)
+}
+
+// String devuelve el valor del parametro o su valor por defecto, vacio si no vino
+func (args Args) String(name string) string {
+	return args.values[name]
+}
+
+// Int devuelve el valor numerico, el esquema ya valido que lo sea
+func (args Args) Int(name string) int {
+	value, _ := strconv.Atoi(args.values[name])
+	return value
+}
+
+func (args Args) Flag(name string) bool {
+	return args.values[name] == "true"
+}
+
+// has indica si el parametro, o el numero number de un numerado, ya vino en la linea
+func (args Args) has(name string, number int) bool {
+	if values, numbered := args.numbered[name]; numbered {
+		_, exists := values[number]
+		return exists
+	}
+	_, exists := args.values[name]
+	return exists
+}
+
+// Has indica si el parametro tiene valor, propio o por defecto
+func (args Args) Has(name string) bool {
+	_, exists := args.values[name]
+	return exists
+}
+
+// Numbered devuelve los valores de un parametro numerado ordenados por su numero
+func (args Args) Numbered(name string) []string {
+	numbers := make([]int, 0, len(args.numbered[name]))
+	for number := range args.numbered[name] {
+		numbers = append(numbers, number)
+	}
+	sort.Ints(numbers)
+	values := make([]string, 0, len(numbers))
+	for _, number := range numbers {
+		values = append(values, args.numbered[name][number])
+	}
+	return values
+}
+
+// Usage arma la forma de uso de los parametros, los opcionales van entre corchetes
+func (schema Schema) Usage() string {
+	parts := make([]string, 0, len(schema))
+	for _, param := range schema {
+		part := "-" + param.Name
+		if param.Numbered {
+			part += "N"
+		}
+		if param.Kind != Flag {
+			part += "=" + param.placeholder()
+		}
+		if !param.Required {
+			part = "[" + part + "]"
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, " ")
+}
+
+func (param Param) placeholder() string {
+	if len(param.Values) > 0 {
+		return strings.Join(param.Values, "|")
+	}
+	switch param.Kind {
+	case Int:
+		return "<numero>"
+	case Letter:
+		return "<letra>"
+	}
+	return "<texto>"
+}
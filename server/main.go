@@ -2,19 +2,30 @@ package main
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
-	"runtime"
 	"server/analyzer"
 	"server/api"
+	"server/commands"
 	"server/console"
+	"server/stores"
 	"strings"
 )
 
 var outcome string
 
 func main() {
+	if dir := os.Getenv("MIA_DISK_DIR"); dir != "" {
+		stores.SetPathDisk(dir)
+	}
+	// Particiones montadas, discos y sesion de la ejecucion anterior
+	err := stores.LoadState()
+	if err != nil {
+		console.PrintWarning(fmt.Sprintf("⚠️ No se pudo restaurar el estado: %v", err))
+	}
+
 	// Verificar si se debe ejecutar en modo servidor
 	if len(os.Args) > 1 && os.Args[1] == "server" {
 		port := "8080"
@@ -27,20 +38,28 @@ func main() {
 		return
 	}
 
-	// Modo consola tradicional
+	// Modo consola tradicional, en una terminal con historial, edicion y Tab para completar
 	scanner := bufio.NewScanner(os.Stdin)
+	var editor *console.LineEditor
+	if console.IsTerminal() {
+		editor = console.NewLineEditor(stores.HistoryFilePath(), commands.Complete)
+	}
 
 	// Limpiar consola y mostrar bienvenida estética
-	clearConsole()
+	console.ClearScreen()
 	console.PrintWelcome()
 
 	for {
-		console.PrintPrompt()
-
-		if !scanner.Scan() {
+		input, err := readInput(editor, scanner)
+		if errors.Is(err, console.ErrInterrupted) {
+			continue
+		} else if err != nil {
 			break
 		}
-		input := strings.TrimSpace(scanner.Text())
+		input = strings.TrimSpace(input)
+		if editor != nil {
+			editor.AddHistory(input)
+		}
 
 		if input == "exit" {
 			break
@@ -60,13 +79,15 @@ func main() {
 			outcome += fmt.Sprintf("❌ Error: %v\n", err)
 		} else {
 			console.PrintSuccess("Comando ejecutado correctamente")
+			// La salida de cat, find, mounted y los demas se muestra aqui, los comandos no imprimen por su cuenta
+			fmt.Println(msg)
 			outcome += fmt.Sprintf("✅ %v\n", msg)
 		}
 		console.PrintSeparator()
 	}
 
 	// Mostrar resumen final con estilo
-	clearConsole()
+	console.ClearScreen()
 	console.PrintFinalSeparator()
 
 	if outcome != "" {
@@ -78,13 +99,14 @@ func main() {
 	console.PrintGoodbye()
 }
 
-func clearConsole() {
-	var cmd *exec.Cmd
-	if runtime.GOOS == "windows" {
-		cmd = exec.Command("cmd", "/c", "cls")
-	} else {
-		cmd = exec.Command("clear")
+// readInput lee con el editor de lineas, o con el scanner si la entrada no es una terminal, como un script redirigido
+func readInput(editor *console.LineEditor, scanner *bufio.Scanner) (string, error) {
+	if editor != nil {
+		return editor.ReadLine()
+	}
+	console.PrintPrompt()
+	if !scanner.Scan() {
+		return "", io.EOF
 	}
-	cmd.Stdout = os.Stdout
-	cmd.Run()
+	return scanner.Text(), nil
 }
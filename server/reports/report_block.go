@@ -64,133 +64,80 @@ func ReportBlock(superBlock *structures.SuperBlock, diskPath, path string) error
 }
 
 func getStringBlock(inode *structures.Inode, diskPath string, sb *structures.SuperBlock, isTheLast bool) (string, error) {
-	dotContent := ""
+	// Los bloques se listan en el orden del I_block, cada apuntador seguido de lo que cuelga de el
+	var labels []string
 	for i, blockIndex := range inode.I_block {
-
 		if blockIndex == -1 {
 			continue
 		}
+		temp, err := getBlockLabels(sb, diskPath, blockIndex, structures.IndirectionLevel(i), inode.I_type[0])
+		if err != nil {
+			return "", err
+		}
+		labels = append(labels, temp...)
+	}
+	dotContent := ""
+	for j, label := range labels {
 		fValue := getNumber()
-		// Aqui diferenciar si es de tipo 0 o 1 el Inodo
-		if i >= 14 {
-			pointerBlock := structures.PointerBlock{}
-			err := pointerBlock.Deserialize(diskPath, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
-			if err != nil {
-				return "", err
-			}
-			dotContent += fmt.Sprintf(`node%d[shape=record label="Bloque Apuntador%d\n`, fValue, blockIndex)
-			for index, value := range pointerBlock.P_pointers {
-				if index%6 == 0 {
-					dotContent += "\n"
-				}
-				dotContent += fmt.Sprintf(" %d,", value)
-			}
-			dotContent += `"];
-			`
-			for iTe, neoIndexBlock := range pointerBlock.P_pointers {
-				if neoIndexBlock == -1 {
-					continue
-				}
-				neoFValue := getNumber()
-				if inode.I_type[0] == '0' {
-					block := &structures.FolderBlock{}
-					err := block.Deserialize(diskPath, int64(sb.S_block_start+(neoIndexBlock*sb.S_block_size)))
-					if err != nil {
-						return "", err
-					}
-					dotContent += fmt.Sprintf(`node%d[shape=record label="Bloque Carpeta%d\nb_name : b_inodo\n`, neoFValue, neoIndexBlock)
-					for _, value := range block.B_content {
-						tempString := string(value.B_name[:])
-						nameTemp := strings.TrimRight(tempString, "\x00")
-						dotContent += fmt.Sprintf(" %s : %d\\n", nameTemp, int32(value.B_inodo))
-					}
-					dotContent += `"];
-					`
-					for neoInodeIndex := 2; i < len(block.B_content); i++ {
+		dotContent += fmt.Sprintf(`node%d[shape=record label="%s"];
+			`, fValue, label)
+		if !isTheLast || j < len(labels)-1 {
+			dotContent += fmt.Sprintf("node%d -> node%d;\n", fValue, fValue+1)
+		}
+	}
+	return dotContent, nil
+}
 
-						content := block.B_content[neoInodeIndex]
-						if content.B_inodo == -1 {
-							continue
-						}
-						inode := &structures.Inode{}
-						err = inode.Deserialize(diskPath, int64(sb.S_inode_start+(sb.S_inode_size*content.B_inodo)))
-						if err != nil {
-							return "", err
-						}
-						temp, err := getStringBlock(inode, diskPath, sb, false)
-						if err != nil {
-							return "", err
-						}
-						dotContent += temp
-					}
-					if !isTheLast {
-						if i < len(inode.I_block)-1 {
-							dotContent += fmt.Sprintf("node%d -> node%d;\n", neoFValue, neoFValue+1)
-						}
-					} else {
-						if i < len(inode.I_block)-1 && inode.I_block[i+1] != -1 {
-							dotContent += fmt.Sprintf("node%d -> node%d;\n", neoFValue, neoFValue+1)
-						}
-					}
-				} else if inode.I_type[0] == '1' {
-					if iTe == 0 {
-						dotContent += fmt.Sprintf("node%d -> node%d;\n", fValue, fValue+1)
-					}
-					block := &structures.FileBlock{}
-					err := block.Deserialize(diskPath, int64(sb.S_block_start+(neoIndexBlock*sb.S_block_size)))
-					if err != nil {
-						return "", err
-					}
-					contentBlock := string(block.B_content[:])
-					contentBlock = strings.TrimRight(contentBlock, "\x00")
-					splitContent := splitEqualParts(contentBlock)
-					dotContent += fmt.Sprintf(`node%d[shape=record label="Bloque archivo %d\n%s\n%s\n%s\n%s"];
-					`, neoFValue, neoIndexBlock, splitContent[0], splitContent[1], splitContent[2], splitContent[3])
-					dotContent += fmt.Sprintf("node%d -> node%d;\n", neoFValue, neoFValue+1)
-				}
-			}
-		} else if inode.I_type[0] == '0' {
-			block := &structures.FolderBlock{}
-			err := block.Deserialize(diskPath, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
-			if err != nil {
-				return "", err
+// getBlockLabels devuelve la etiqueta del bloque y, si es de apuntadores, las de los bloques que cuelgan de el
+func getBlockLabels(sb *structures.SuperBlock, diskPath string, blockIndex int32, level int, tipoInodo byte) ([]string, error) {
+	offset := int64(sb.S_block_start + (blockIndex * sb.S_block_size))
+	if level > 0 {
+		pointerBlock := structures.PointerBlock{}
+		err := pointerBlock.Deserialize(diskPath, offset)
+		if err != nil {
+			return nil, err
+		}
+		label := fmt.Sprintf(`Bloque Apuntador%d\n`, blockIndex)
+		for index, value := range pointerBlock.P_pointers {
+			if index%6 == 0 {
+				label += "\n"
 			}
-			dotContent += fmt.Sprintf(`node%d[shape=record label="Bloque Carpeta%d\nb_name : b_inodo\n`, fValue, blockIndex)
-			for _, value := range block.B_content {
-				tempString := string(value.B_name[:])
-				nameTemp := strings.TrimRight(tempString, "\x00")
-				dotContent += fmt.Sprintf(" %s : %d\\n", nameTemp, int32(value.B_inodo))
+			label += fmt.Sprintf(" %d,", value)
+		}
+		labels := []string{label}
+		for _, value := range pointerBlock.P_pointers {
+			if value == -1 {
+				continue
 			}
-			dotContent += `"];
-				`
-		} else if inode.I_type[0] == '1' {
-
-			block := &structures.FileBlock{}
-			err := block.Deserialize(diskPath, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
+			temp, err := getBlockLabels(sb, diskPath, value, level-1, tipoInodo)
 			if err != nil {
-
-				return "", err
+				return nil, err
 			}
-
-			contentBlock := string(block.B_content[:])
-			contentBlock = strings.TrimRight(contentBlock, "\x00")
-			splitContent := splitEqualParts(contentBlock)
-			dotContent += fmt.Sprintf(`node%d[shape=record label="Bloque archivo %d\n%s\n%s\n%s\n%s"];
-					`, fValue, blockIndex, splitContent[0], splitContent[1], splitContent[2], splitContent[3])
-
+			labels = append(labels, temp...)
 		}
-		if !isTheLast {
-			if i < len(inode.I_block)-1 {
-				dotContent += fmt.Sprintf("node%d -> node%d;\n", fValue, fValue+1)
-			}
-		} else {
-			if i < len(inode.I_block)-1 && inode.I_block[i+1] != -1 {
-				dotContent += fmt.Sprintf("node%d -> node%d;\n", fValue, fValue+1)
-			}
+		return labels, nil
+	}
+	if tipoInodo == '0' {
+		block := &structures.FolderBlock{}
+		err := block.Deserialize(diskPath, offset)
+		if err != nil {
+			return nil, err
 		}
-
+		label := fmt.Sprintf(`Bloque Carpeta%d\nb_name : b_inodo\n`, blockIndex)
+		for _, value := range block.B_content {
+			nameTemp := strings.TrimRight(string(value.B_name[:]), "\x00")
+			label += fmt.Sprintf(" %s : %d\\n", nameTemp, int32(value.B_inodo))
+		}
+		return []string{label}, nil
 	}
-	return dotContent, nil
+	block := &structures.FileBlock{}
+	err := block.Deserialize(diskPath, offset)
+	if err != nil {
+		return nil, err
+	}
+	contentBlock := strings.TrimRight(string(block.B_content[:]), "\x00")
+	splitContent := splitEqualParts(contentBlock)
+	return []string{fmt.Sprintf(`Bloque archivo %d\n%s\n%s\n%s\n%s`, blockIndex, splitContent[0], splitContent[1], splitContent[2], splitContent[3])}, nil
 }
 
 func splitEqualParts(s string) []string {
@@ -19,7 +19,7 @@ func ReportBMBlock(superBlock *structures.SuperBlock, diskPath string, path stri
 	}
 	defer file.Close()
 
-	totalBlock := superBlock.S_blocks_count + superBlock.S_free_blocks_count
+	totalBlock := superBlock.TotalBlocks()
 
 	var bitmapContent strings.Builder
 
@@ -35,6 +35,10 @@ func ReportBMBlock(superBlock *structures.SuperBlock, diskPath string, path stri
 			return fmt.Errorf("error al leer el byte del archivo: %v", err)
 		}
 
+		// Despues de un loss el bitmap queda en ceros, se muestra como libre
+		if char[0] != 'X' {
+			char[0] = 'O'
+		}
 		bitmapContent.WriteByte(char[0])
 
 		if (i+1)%20 == 0 {
@@ -19,7 +19,7 @@ func ReportBMInode(superblock *structures.SuperBlock, diskPath string, path stri
 	}
 	defer file.Close()
 
-	totalInodes := superblock.S_inodes_count + superblock.S_free_inodes_count
+	totalInodes := superblock.TotalInodes()
 
 	var bitmapContent strings.Builder
 
@@ -35,6 +35,10 @@ func ReportBMInode(superblock *structures.SuperBlock, diskPath string, path stri
 			return fmt.Errorf("error al leer el byte del archivo: %v", err)
 		}
 
+		// Despues de un loss el bitmap queda en ceros, se muestra como libre
+		if char[0] != '1' {
+			char[0] = '0'
+		}
 		bitmapContent.WriteByte(char[0])
 
 		if (i+1)%20 == 0 {
@@ -1,12 +1,14 @@
 package reports
 
 import (
+	"encoding/binary"
 	"fmt"
 	"os"
 	"os/exec"
 	"server/stores"
 	"server/structures"
 	"server/utils"
+	"sort"
 )
 
 var contador int32 = 0
@@ -26,14 +28,14 @@ func ReportDisk(mbr *structures.MBR, idDisk string, path string, pathDisk string
 
 	tamanoTotalDisco := mbr.Mbr_size
 
-	temp := float64(153) / float64(tamanoTotalDisco)
-	percentageMBR := temp * 100
-
 	dotContent += fmt.Sprintf(`node%d[shape=record, label="%s"];
 	`, getNumberNode(), "MBR")
-	percentageUsed := percentageMBR
 
-	for _, partition := range mbr.Mbr_partitions {
+	// Las particiones se dibujan en el orden del disco, con los huecos que dejan las eliminadas
+	partitions := append([]structures.PARTITION{}, mbr.Mbr_partitions[:]...)
+	sort.Slice(partitions, func(i, j int) bool { return partitions[i].Part_start < partitions[j].Part_start })
+	diskCursor := int32(binary.Size(mbr))
+	for _, partition := range partitions {
 		var tipoParticion string
 		if partition.Part_type[0] == 'P' {
 			tipoParticion = "Primaria"
@@ -41,18 +43,43 @@ func ReportDisk(mbr *structures.MBR, idDisk string, path string, pathDisk string
 			tipoParticion = "Extendida"
 		} else {
 			//Significa que no esta siendo utilizada esta particion
-			break
+			continue
+		}
+		if partition.Part_start > diskCursor {
+			dotContent += fmt.Sprintf(`node%d[shape=record, label="%s\n%.1f%%"];
+		`, getNumberNode(), "Libre", float64(partition.Part_start-diskCursor)/float64(tamanoTotalDisco)*100)
 		}
+		diskCursor = partition.Part_start + partition.Part_size
 		percentagePartition := (float64(partition.Part_size) / float64(tamanoTotalDisco)) * 100
-		percentageUsed += percentagePartition
 		if partition.Part_type[0] == 'E' {
 			dotContent += fmt.Sprintf(`subgraph cluster_2 {
 			label="%s";
 			rankdir=LR
 			`, tipoParticion)
 
-			dotContent += fmt.Sprintf(`node%d[shape=record, label="%s\n%d%%"];
-			}`, getNumberNode(), "Libre", 100)
+			logicals, offsets, err := mbr.GetLogicalPartitions(pathDisk)
+			if err != nil {
+				return err
+			}
+			// Cada logica se muestra con su EBR y los huecos libres que quedan entre ellas
+			cursor := partition.Part_start
+			for j, ebr := range logicals {
+				if offsets[j] > cursor {
+					dotContent += fmt.Sprintf(`node%d[shape=record, label="%s\n%.1f%%"];
+			`, getNumberNode(), "Libre", float64(offsets[j]-cursor)/float64(tamanoTotalDisco)*100)
+				}
+				dotContent += fmt.Sprintf(`node%d[shape=record, label="%s"];
+			node%d[shape=record, label="%s\n%.1f%%"];
+			`, getNumberNode(), "EBR", getNumberNode(), "Lógica", float64(ebr.Part_size)/float64(tamanoTotalDisco)*100)
+				cursor = ebr.Part_start + ebr.Part_size
+			}
+			extendedEnd := partition.Part_start + partition.Part_size
+			if extendedEnd > cursor {
+				dotContent += fmt.Sprintf(`node%d[shape=record, label="%s\n%.1f%%"];
+			`, getNumberNode(), "Libre", float64(extendedEnd-cursor)/float64(tamanoTotalDisco)*100)
+			}
+			dotContent += `}
+			`
 		} else {
 			dotContent += fmt.Sprintf(`node%d[shape=record, label="%s\n%.1f%%"];
 			`, getNumberNode(), tipoParticion, percentagePartition)
@@ -60,7 +87,7 @@ func ReportDisk(mbr *structures.MBR, idDisk string, path string, pathDisk string
 	}
 
 	dotContent += fmt.Sprintf(`node%d[shape=record, label="%s\n%.1f%%"];
-	}`, getNumberNode(), "Libre", 100-percentageUsed)
+	}`, getNumberNode(), "Libre", float64(tamanoTotalDisco-diskCursor)/float64(tamanoTotalDisco)*100)
 
 	//Creacion del dot y el merquetenge
 	file, err := os.Create(dotFileName)
@@ -2,18 +2,17 @@ package reports
 
 import (
 	"os"
+	"server/stores"
 	"server/structures"
 	"server/utils"
 )
 
-func ReportFile(sb *structures.SuperBlock, diskPath, path string, pathFileToGetInfo string) error {
+func ReportFile(session *stores.Session, sb *structures.SuperBlock, diskPath, path string, pathFileToGetInfo string) error {
 	err := utils.CreateParentDirs(path)
 	if err != nil {
 		return err
 	}
-	parentDirs, destDir := utils.GetParentDirectories(pathFileToGetInfo)
-
-	content, err := sb.ContentFromFileCat(diskPath, 0, parentDirs, destDir)
+	content, err := sb.ReadFileForUser(diskPath, pathFileToGetInfo, session.UserID, session.GroupID)
 	if err != nil {
 		return err
 	}
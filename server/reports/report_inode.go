@@ -47,18 +47,19 @@ func ReportInode(superBlock *structures.SuperBlock, diskPath, path string) error
                 <tr><td BGCOLOR="#bbccaa" colspan="2">BLOQUES DIRECTOS</td></tr>
             `, i, i, inode.I_uid, inode.I_gid, inode.I_size, atime, ctime, mtime, rune(inode.I_type[0]), string(inode.I_perm[:]))
 
-		for j, block := range inode.I_block {
-			if j > 13 {
-				break
-			}
+		for j, block := range inode.I_block[:structures.DirectBlocks] {
 			dotContent += fmt.Sprintf("<tr><td BGCOLOR=\"#bbccaa\">%d</td><td>%d</td></tr>", j+1, block)
 		}
 
 		dotContent += fmt.Sprintf(`
-                <tr><td BGCOLOR="#bbccaa" colspan="2">BLOQUE INDIRECTO</td></tr>
+                <tr><td BGCOLOR="#bbccaa" colspan="2">BLOQUE INDIRECTO SIMPLE</td></tr>
+                <tr><td BGCOLOR="#bbccaa">%d</td><td>%d</td></tr>
+                <tr><td BGCOLOR="#bbccaa" colspan="2">BLOQUE INDIRECTO DOBLE</td></tr>
+                <tr><td BGCOLOR="#bbccaa">%d</td><td>%d</td></tr>
+                <tr><td BGCOLOR="#bbccaa" colspan="2">BLOQUE INDIRECTO TRIPLE</td></tr>
                 <tr><td BGCOLOR="#bbccaa">%d</td><td>%d</td></tr>
             </table>>];
-        `, 15, inode.I_block[14])
+        `, 13, inode.I_block[12], 14, inode.I_block[13], 15, inode.I_block[14])
 
 		if i < superBlock.S_inodes_count-1 {
 			dotContent += fmt.Sprintf("inode%d -> inode%d;\n", i, i+1)
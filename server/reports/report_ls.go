@@ -1,7 +1,6 @@
 package reports
 
 import (
-	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -13,7 +12,7 @@ import (
 	"time"
 )
 
-func ReportLs(path string, pathToGetInfo string) error {
+func ReportLs(session *stores.Session, path string, pathToGetInfo string) error {
 	err := utils.CreateParentDirs(path)
 	if err != nil {
 		return err
@@ -29,67 +28,39 @@ func ReportLs(path string, pathToGetInfo string) error {
     `
 
 	// Ubicar el inodo desde donde todo se debe escribir
-	superBlock, _, diskPath, err := stores.GetMountedPartitionSuperblock(stores.LogedIdPartition)
+	superBlock, _, diskPath, err := stores.GetMountedPartitionSuperblock(session.IdPartition)
 	if err != nil {
 		return err
 	}
-	inodoBase, _, err := UbicarInodo(superBlock, pathToGetInfo, diskPath)
+	_, inodoBase, err := superBlock.Lookup(diskPath, pathToGetInfo, session.UserID, session.GroupID)
 	if err != nil {
 		return err
 	}
 	if inodoBase.I_type[0] == '1' {
-		return errors.New("no se puede aplicar este reporte sobre un archivo")
+		return structures.NewError(structures.ErrInvalid, "no se puede aplicar este reporte sobre un archivo")
 	}
 
 	// Contenido
-	for i, blockIndex := range inodoBase.I_block {
-		if blockIndex == -1 {
-			continue
+	indexes, err := superBlock.FolderBlockIndexes(diskPath, inodoBase)
+	if err != nil {
+		return err
+	}
+	for _, blockIndex := range indexes {
+		block := &structures.FolderBlock{}
+		err := block.Deserialize(diskPath, int64(superBlock.S_block_start+(blockIndex*superBlock.S_block_size)))
+		if err != nil {
+			return err
 		}
-		if i >= 14 {
-			pointerBlock := &structures.PointerBlock{}
-			err := pointerBlock.Deserialize(diskPath, int64(superBlock.S_block_start+(blockIndex*superBlock.S_block_size)))
-			if err != nil {
-				return err
+		for i := 2; i < len(block.B_content); i++ {
+			content := block.B_content[i]
+			if content.B_inodo == -1 {
+				continue
 			}
-			for neoIndex := 0; neoIndex < len(pointerBlock.P_pointers); neoIndex++ {
-				if pointerBlock.P_pointers[neoIndex] == -1 {
-					continue
-				}
-				block := &structures.FolderBlock{}
-				err := block.Deserialize(diskPath, int64(superBlock.S_block_start+(pointerBlock.P_pointers[neoIndex]*superBlock.S_block_size)))
-				if err != nil {
-					return err
-				}
-				for i := 2; i < len(block.B_content); i++ {
-					content := block.B_content[i]
-					if content.B_inodo == -1 {
-						continue
-					}
-					temp, err := getLsString(superBlock, content.B_inodo, strings.Trim(string(content.B_name[:]), "\x00"), diskPath)
-					if err != nil {
-						return err
-					}
-					dotContent += temp
-				}
-			}
-		} else {
-			block := &structures.FolderBlock{}
-			err := block.Deserialize(diskPath, int64(superBlock.S_block_start+(blockIndex*superBlock.S_block_size)))
+			temp, err := getLsString(superBlock, content.B_inodo, strings.Trim(string(content.B_name[:]), "\x00"), diskPath, session.IdPartition)
 			if err != nil {
 				return err
 			}
-			for i := 2; i < len(block.B_content); i++ {
-				content := block.B_content[i]
-				if content.B_inodo == -1 {
-					continue
-				}
-				temp, err := getLsString(superBlock, content.B_inodo, strings.Trim(string(content.B_name[:]), "\x00"), diskPath)
-				if err != nil {
-					return err
-				}
-				dotContent += temp
-			}
+			dotContent += temp
 		}
 	}
 
@@ -140,89 +111,7 @@ func getPermissions(dato string) string {
 	return result
 }
 
-func UbicarInodo(sb *structures.SuperBlock, dirPath string, diskPath string) (*structures.Inode, int32, error) {
-	parentDirs, destDir := utils.GetParentDirectories(dirPath)
-	if destDir != "" {
-		parentDirs = append(parentDirs, destDir)
-	}
-	return getInode(sb, 0, diskPath, parentDirs)
-}
-
-func getInode(sb *structures.SuperBlock, inodeIndex int32, diskPath string, parentsDir []string) (*structures.Inode, int32, error) {
-	inode := &structures.Inode{}
-	err := inode.Deserialize(diskPath, int64(sb.S_inode_start+(inodeIndex*sb.S_inode_size)))
-	if err != nil {
-		return nil, 0, err
-	}
-	if len(parentsDir) == 0 {
-		return inode, inodeIndex, nil
-	}
-	for i, blockIndex := range inode.I_block {
-		if blockIndex == -1 {
-			continue
-		}
-		if i >= 14 {
-			inderctNode := &structures.PointerBlock{}
-			err := inderctNode.Deserialize(diskPath, int64(sb.S_block_start+(sb.S_block_size*blockIndex)))
-			if err != nil {
-				return nil, 0, err
-			}
-			for _, value := range inderctNode.P_pointers {
-				if value == -1 {
-					continue
-				}
-				block := &structures.FolderBlock{}
-				err := block.Deserialize(diskPath, int64(sb.S_block_start+(value*sb.S_block_size)))
-				if err != nil {
-					return nil, 0, err
-				}
-				for indexContent := 2; indexContent < len(block.B_content); indexContent++ {
-					content := block.B_content[indexContent]
-					if content.B_inodo == -1 {
-						continue
-					}
-					parentDir, err := utils.First(parentsDir)
-					if err != nil {
-						return nil, 0, err
-					}
-
-					contentName := strings.Trim(string(content.B_name[:]), "\x00 ")
-					parentDirName := strings.Trim(parentDir, "\x00 ")
-					if strings.EqualFold(contentName, parentDirName) {
-						return getInode(sb, content.B_inodo, diskPath, utils.RemoveElement(parentsDir, 0))
-					}
-				}
-			}
-
-		} else {
-			block := &structures.FolderBlock{}
-			err := block.Deserialize(diskPath, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
-			if err != nil {
-				return nil, 0, err
-			}
-			for indexContent := 2; indexContent < len(block.B_content); indexContent++ {
-				content := block.B_content[indexContent]
-				if content.B_inodo == -1 {
-					continue
-				}
-				parentDir, err := utils.First(parentsDir)
-				if err != nil {
-					return nil, 0, err
-				}
-
-				contentName := strings.Trim(string(content.B_name[:]), "\x00 ")
-				parentDirName := strings.Trim(parentDir, "\x00 ")
-				if strings.EqualFold(contentName, parentDirName) {
-					return getInode(sb, content.B_inodo, diskPath, utils.RemoveElement(parentsDir, 0))
-				}
-			}
-		}
-
-	}
-	return nil, 0, errors.New("no existe la ruta especificada")
-}
-
-func getLsString(sb *structures.SuperBlock, inodeIndex int32, nombre string, diskPath string) (string, error) {
+func getLsString(sb *structures.SuperBlock, inodeIndex int32, nombre string, diskPath string, idPartition string) (string, error) {
 
 	inode := &structures.Inode{}
 	err := inode.Deserialize(diskPath, int64(sb.S_inode_start+(inodeIndex*sb.S_inode_size)))
@@ -234,11 +123,11 @@ func getLsString(sb *structures.SuperBlock, inodeIndex int32, nombre string, dis
 	for i := 0; i < 3; i++ {
 		permissions += getPermissions(string(tempPermisions[i])) + " "
 	}
-	owner, err := getOwnerByID(inode.I_uid)
+	owner, err := getOwnerByID(inode.I_uid, idPartition)
 	if err != nil {
 		return "", err
 	}
-	group, err := getGroupById(inode.I_gid)
+	group, err := getGroupById(inode.I_gid, idPartition)
 	if err != nil {
 		return "", err
 	}
@@ -253,8 +142,8 @@ func getLsString(sb *structures.SuperBlock, inodeIndex int32, nombre string, dis
 	return dotContent, nil
 }
 
-func getOwnerByID(id int32) (string, error) {
-	contentUsersTxt, err := GetContetnUsersTxt(stores.LogedIdPartition)
+func getOwnerByID(id int32, idPartition string) (string, error) {
+	contentUsersTxt, err := GetContetnUsersTxt(idPartition)
 	if err != nil {
 		return "", err
 	}
@@ -269,11 +158,11 @@ func getOwnerByID(id int32) (string, error) {
 		}
 		return row[3], nil
 	}
-	return "", errors.New("no se encontro el usuario")
+	return "", structures.NewError(structures.ErrNotFound, "no se encontro el usuario")
 }
 
-func getGroupById(id int32) (string, error) {
-	contentUsersTxt, err := GetContetnUsersTxt(stores.LogedIdPartition)
+func getGroupById(id int32, idPartition string) (string, error) {
+	contentUsersTxt, err := GetContetnUsersTxt(idPartition)
 	if err != nil {
 		return "", err
 	}
@@ -288,7 +177,7 @@ func getGroupById(id int32) (string, error) {
 		}
 		return row[2], nil
 	}
-	return "", errors.New("no se encontro el usuario")
+	return "", structures.NewError(structures.ErrNotFound, "no se encontro el usuario")
 }
 
 func GetContetnUsersTxt(idPartition string) (string, error) {
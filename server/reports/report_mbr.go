@@ -10,7 +10,7 @@ import (
 	"time"
 )
 
-func ReportMBR(mbr *structures.MBR, path string, idDisk string) error {
+func ReportMBR(mbr *structures.MBR, path string, idDisk string, diskPath string) error {
 	err := utils.CreateParentDirs(path)
 	if err != nil {
 		return err
@@ -50,7 +50,21 @@ func ReportMBR(mbr *structures.MBR, path string, idDisk string) error {
 			`, i+1, partStatus, partType, partFit, part.Part_start, part.Part_size, partName)
 
 		if part.Part_type[0] == 'E' {
-			dotContent += ""
+			logicals, offsets, err := mbr.GetLogicalPartitions(diskPath)
+			if err != nil {
+				return err
+			}
+			for j, ebr := range logicals {
+				dotContent += fmt.Sprintf(`
+				<tr><td colspan="2" BGCOLOR="#bbccaa"> EBR %d </td></tr>
+				<tr><td BGCOLOR="#bbccaa">part_mount</td><td>%c</td></tr>
+				<tr><td BGCOLOR="#bbccaa">part_fit</td><td>%c</td></tr>
+				<tr><td BGCOLOR="#bbccaa">part_start</td><td>%d</td></tr>
+				<tr><td BGCOLOR="#bbccaa">part_size</td><td>%d</td></tr>
+				<tr><td BGCOLOR="#bbccaa">part_next</td><td>%d</td></tr>
+				<tr><td BGCOLOR="#bbccaa">part_name</td><td>%s</td></tr>
+			`, j+1, rune(ebr.Part_mount[0]), rune(ebr.Part_fit[0]), offsets[j], ebr.Part_size, ebr.Part_next, strings.TrimRight(string(ebr.Part_name[:]), "\x00"))
+			}
 		}
 	}
 
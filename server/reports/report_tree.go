@@ -26,17 +26,24 @@ func ReportTree(sb *structures.SuperBlock, diskPath, path string) error {
         node [shape=plaintext]
 		rankdir=LR;
 	`
-	// Contenido returbio
-	inode := &structures.Inode{}
-	err = inode.Deserialize(diskPath, int64(sb.S_inode_start+(sb.S_inode_size*0)))
+	// Si la raiz no esta en el bitmap (por ejemplo despues de un loss) el arbol queda vacio
+	rootUsed, err := sb.IsInodeUsed(diskPath, 0)
 	if err != nil {
 		return err
 	}
-	temp, err := getInodeDOT(sb, inode, diskPath, 0, true, 0)
-	if err != nil {
-		return err
+	if rootUsed {
+		// Contenido returbio
+		inode := &structures.Inode{}
+		err = inode.Deserialize(diskPath, int64(sb.S_inode_start+(sb.S_inode_size*0)))
+		if err != nil {
+			return err
+		}
+		temp, err := getInodeDOT(sb, inode, diskPath, 0, true, 0)
+		if err != nil {
+			return err
+		}
+		dotContent += temp
 	}
-	dotContent += temp
 	dotContent += "}"
 	dotFile, err := os.Create(dotFileName)
 	if err != nil {
@@ -82,18 +89,19 @@ func getInodeDOT(sb *structures.SuperBlock, inode *structures.Inode, diskPath st
 		<tr><td >i_perm</td><td>%s</td></tr>
 		<tr><td  colspan="2">BLOQUES DIRECTOS</td></tr>
 	`, nodoActual, numberInode, inode.I_uid, inode.I_gid, inode.I_size, atime, ctime, mtime, rune(inode.I_type[0]), string(inode.I_perm[:]))
-	for j, block := range inode.I_block {
-		if j > 13 {
-			break
-		}
+	for j, block := range inode.I_block[:structures.DirectBlocks] {
 		dotContent += fmt.Sprintf("<tr><td >%d</td><td>%d</td></tr>", j+1, block)
 	}
 
 	dotContent += fmt.Sprintf(`
-			<tr><td  colspan="2">BLOQUE INDIRECTO</td></tr>
+			<tr><td  colspan="2">BLOQUE INDIRECTO SIMPLE</td></tr>
+			<tr><td >%d</td><td>%d</td></tr>
+			<tr><td  colspan="2">BLOQUE INDIRECTO DOBLE</td></tr>
+			<tr><td >%d</td><td>%d</td></tr>
+			<tr><td  colspan="2">BLOQUE INDIRECTO TRIPLE</td></tr>
 			<tr><td >%d</td><td>%d</td></tr>
 		</table>>];
-	`, 15, inode.I_block[14])
+	`, 13, inode.I_block[12], 14, inode.I_block[13], 15, inode.I_block[14])
 
 	// Hacer las direcciones de todos los nodos q este kabron saca del Iblock
 	if !isTheRoot {
@@ -102,42 +110,20 @@ func getInodeDOT(sb *structures.SuperBlock, inode *structures.Inode, diskPath st
 	}
 
 	for i, value := range inode.I_block {
-		if value != -1 {
-			if i >= 14 {
-				block := &structures.PointerBlock{}
-				err := block.Deserialize(diskPath, int64(sb.S_block_start+(value*sb.S_block_size)))
-				if err != nil {
-					return "", err
-				}
-				temp, err := getPointerBlockDOT(sb, block, nodoActual, int(value), diskPath, inode.I_type[0])
-				if err != nil {
-					return "", err
-				}
-				dotContent += temp
-			} else if inode.I_type[0] == '0' { //Carpetas
-				block := &structures.FolderBlock{}
-				err := block.Deserialize(diskPath, int64(sb.S_block_start+(value*sb.S_block_size)))
-				if err != nil {
-					return "", err
-				}
-				temp, err := getFolderBlockDOT(sb, block, diskPath, nodoActual, int(value))
-				if err != nil {
-					return "", err
-				}
-				dotContent += temp
-			} else { //File
-				block := &structures.FileBlock{}
-				err := block.Deserialize(diskPath, int64(sb.S_block_start+(value*sb.S_block_size)))
-				if err != nil {
-					return "", err
-				}
-				temp, err := getFileBlockDOT(block, nodoActual, int(value))
-				if err != nil {
-					return "", err
-				}
-				dotContent += temp
-			}
+		if value == -1 {
+			continue
+		}
+		var temp string
+		var err error
+		if level := structures.IndirectionLevel(i); level > 0 {
+			temp, err = getPointerBlockDOT(sb, nodoActual, int(value), level, diskPath, inode.I_type[0])
+		} else {
+			temp, err = getDataBlockDOT(sb, nodoActual, value, diskPath, inode.I_type[0])
+		}
+		if err != nil {
+			return "", err
 		}
+		dotContent += temp
 	}
 
 	return dotContent, nil
@@ -187,7 +173,31 @@ func getFileBlockDOT(block *structures.FileBlock, nodoPadre int, blockIndex int)
 	return dotContent, nil
 }
 
-func getPointerBlockDOT(sb *structures.SuperBlock, block *structures.PointerBlock, nodoPadre int, blockIndex int, diskPath string, tipoInodo byte) (string, error) {
+// getDataBlockDOT dibuja un bloque carpeta o archivo segun el tipo del inodo
+func getDataBlockDOT(sb *structures.SuperBlock, nodoPadre int, blockIndex int32, diskPath string, tipoInodo byte) (string, error) {
+	if tipoInodo == '0' { //Carpetas
+		block := &structures.FolderBlock{}
+		err := block.Deserialize(diskPath, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
+		if err != nil {
+			return "", err
+		}
+		return getFolderBlockDOT(sb, block, diskPath, nodoPadre, int(blockIndex))
+	}
+	block := &structures.FileBlock{}
+	err := block.Deserialize(diskPath, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
+	if err != nil {
+		return "", err
+	}
+	return getFileBlockDOT(block, nodoPadre, int(blockIndex))
+}
+
+// getPointerBlockDOT dibuja un bloque de apuntadores y lo que cuelga de el, level indica cuantos niveles faltan para llegar a los datos
+func getPointerBlockDOT(sb *structures.SuperBlock, nodoPadre int, blockIndex int, level int, diskPath string, tipoInodo byte) (string, error) {
+	block := &structures.PointerBlock{}
+	err := block.Deserialize(diskPath, int64(sb.S_block_start+(int32(blockIndex)*sb.S_block_size)))
+	if err != nil {
+		return "", err
+	}
 	nodoActual := getNode()
 	dotContent := fmt.Sprintf(`node%d[fillcolor="#f7dc6f" style=filled shape=record label="Bloque Apuntador%d\n`, nodoActual, blockIndex)
 	for index, value := range block.P_pointers {
@@ -200,33 +210,20 @@ func getPointerBlockDOT(sb *structures.SuperBlock, block *structures.PointerBloc
 	`
 	dotContent += fmt.Sprintf(`node%d -> node%d
 	`, nodoPadre, nodoActual)
-	for _, indexInode := range block.P_pointers {
-		if indexInode == -1 {
+	for _, value := range block.P_pointers {
+		if value == -1 {
 			continue
 		}
-		if tipoInodo == '0' {
-			block := &structures.FolderBlock{}
-			err := block.Deserialize(diskPath, int64(sb.S_block_start+(indexInode*sb.S_block_size)))
-			if err != nil {
-				return "", err
-			}
-			temp, err := getFolderBlockDOT(sb, block, diskPath, nodoActual, int(indexInode))
-			if err != nil {
-				return "", err
-			}
-			dotContent += temp
+		var temp string
+		if level > 1 {
+			temp, err = getPointerBlockDOT(sb, nodoActual, int(value), level-1, diskPath, tipoInodo)
 		} else {
-			block := &structures.FileBlock{}
-			err := block.Deserialize(diskPath, int64(sb.S_block_start+(indexInode*sb.S_block_size)))
-			if err != nil {
-				return "", err
-			}
-			temp, err := getFileBlockDOT(block, nodoActual, int(indexInode))
-			if err != nil {
-				return "", err
-			}
-			dotContent += temp
+			temp, err = getDataBlockDOT(sb, nodoActual, value, diskPath, tipoInodo)
 		}
+		if err != nil {
+			return "", err
+		}
+		dotContent += temp
 	}
 	return dotContent, nil
 }
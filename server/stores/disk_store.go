@@ -7,13 +7,13 @@ import (
 )
 
 func GetAllLoadedDisks() map[string]string {
-	return LoadedDiskPaths
+	return LoadedDisks()
 }
 
 func GetDiskInfo(diskLetter string) (*structures.MBR, string, error) {
-	diskPath, exists := LoadedDiskPaths[diskLetter]
+	diskPath, exists := LoadedDisks()[diskLetter]
 	if !exists {
-		return nil, "", fmt.Errorf("disco %s no encontrado", diskLetter)
+		return nil, "", structures.Errorf(structures.ErrNotFound, "disco %s no encontrado", diskLetter)
 	}
 
 	mbr := &structures.MBR{}
@@ -27,6 +27,8 @@ func GetDiskInfo(diskLetter string) (*structures.MBR, string, error) {
 
 // Función para debug - imprimir discos cargados
 func PrintLoadedDisks() {
+	stateMutex.RLock()
+	defer stateMutex.RUnlock()
 	console.PrintInfo("📀 Discos cargados actualmente:")
 	if len(LoadedDiskPaths) == 0 {
 		console.PrintWarning("  ⚠️ No hay discos cargados")
@@ -41,6 +43,8 @@ func PrintLoadedDisks() {
 
 // Función para debug - imprimir particiones montadas
 func PrintMountedPartitions() {
+	stateMutex.RLock()
+	defer stateMutex.RUnlock()
 	console.PrintInfo("🗂️ Particiones montadas actualmente:")
 	if len(MountedPartitions) == 0 {
 		console.PrintWarning("  ⚠️ No hay particiones montadas")
@@ -55,12 +59,16 @@ func PrintMountedPartitions() {
 
 // Nueva función para agregar disco con debug
 func AddLoadedDisk(letter, path string) {
+	stateMutex.Lock()
+	defer stateMutex.Unlock()
 	LoadedDiskPaths[letter] = path
 	console.PrintInfo(fmt.Sprintf("➕ Disco agregado: %s -> %s (Total: %d)", letter, path, len(LoadedDiskPaths)))
 }
 
 // Nueva función para remover disco con debug
 func RemoveLoadedDiskByLetter(letter string) {
+	stateMutex.Lock()
+	defer stateMutex.Unlock()
 	if path, exists := LoadedDiskPaths[letter]; exists {
 		delete(LoadedDiskPaths, letter)
 		console.PrintInfo(fmt.Sprintf("➖ Disco removido: %s -> %s (Total: %d)", letter, path, len(LoadedDiskPaths)))
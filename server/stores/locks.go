@@ -0,0 +1,134 @@
+package stores
+
+import (
+	"path/filepath"
+	"server/utils"
+	"strings"
+	"sync"
+)
+
+/*
+Candados para el servidor:
+
+	stateMutex: los mapas de discos y particiones montadas
+	DiskLock: el MBR y los EBR de un disco, fdisk, mount y unmount lo toman para escribir
+	PartitionLock: el sistema de archivos de una particion, se toma despues del de su disco en modo lectura
+
+Siempre se toma primero el del disco y luego el de la particion.
+*/
+var (
+	stateMutex     sync.RWMutex
+	locksMutex     sync.Mutex
+	diskLocks      = make(map[string]*sync.RWMutex) //path:candado
+	partitionLocks = make(map[string]*sync.RWMutex) //ID:candado
+)
+
+func DiskLock(path string) *sync.RWMutex {
+	locksMutex.Lock()
+	defer locksMutex.Unlock()
+	key := filepath.Clean(path)
+	if diskLocks[key] == nil {
+		diskLocks[key] = &sync.RWMutex{}
+	}
+	return diskLocks[key]
+}
+
+func PartitionLock(id string) *sync.RWMutex {
+	locksMutex.Lock()
+	defer locksMutex.Unlock()
+	key := strings.ToUpper(id)
+	if partitionLocks[key] == nil {
+		partitionLocks[key] = &sync.RWMutex{}
+	}
+	return partitionLocks[key]
+}
+
+// LockPartition toma el disco de la particion en lectura y la particion en lectura o escritura.
+// Devuelve la funcion que los libera, si la particion no esta montada no toma nada.
+func LockPartition(id string, write bool) func() {
+	path := MountedDiskPath(id)
+	if path == "" {
+		return func() {}
+	}
+	disk := DiskLock(path)
+	partition := PartitionLock(id)
+	disk.RLock()
+	if write {
+		partition.Lock()
+	} else {
+		partition.RLock()
+	}
+	return func() {
+		if write {
+			partition.Unlock()
+		} else {
+			partition.RUnlock()
+		}
+		disk.RUnlock()
+	}
+}
+
+// LockDisk toma el disco completo en lectura o escritura, escribir excluye a todas sus particiones
+func LockDisk(path string, write bool) func() {
+	disk := DiskLock(path)
+	if write {
+		disk.Lock()
+		return disk.Unlock
+	}
+	disk.RLock()
+	return disk.RUnlock
+}
+
+// MountedDiskPath devuelve el disco de la particion montada, vacio si no esta montada
+func MountedDiskPath(id string) string {
+	stateMutex.RLock()
+	defer stateMutex.RUnlock()
+	return MountedPartitions[id]
+}
+
+// LoadedDisks devuelve una copia de los discos cargados para recorrerlos sin el candado
+func LoadedDisks() map[string]string {
+	stateMutex.RLock()
+	defer stateMutex.RUnlock()
+	disks := make(map[string]string, len(LoadedDiskPaths))
+	for name, path := range LoadedDiskPaths {
+		disks[name] = path
+	}
+	return disks
+}
+
+// MountedIDs devuelve los IDs de las particiones montadas sin ordenar
+func MountedIDs() []string {
+	stateMutex.RLock()
+	defer stateMutex.RUnlock()
+	ids := make([]string, 0, len(MountedPartitions))
+	for id := range MountedPartitions {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// NextDiskLetter y NextPartitionLetter avanzan los contadores de utils, se guardan con el estado y usan el mismo candado
+func NextDiskLetter() string {
+	stateMutex.Lock()
+	defer stateMutex.Unlock()
+	return utils.GetLetterToDisk()
+}
+
+func NextPartitionLetter(path string) (string, int, error) {
+	stateMutex.Lock()
+	defer stateMutex.Unlock()
+	return utils.GetLetter(path)
+}
+
+func AddMountedPartition(id, path string) {
+	stateMutex.Lock()
+	defer stateMutex.Unlock()
+	MountedPartitions[id] = path
+}
+
+func RemoveMountedPartition(id string) {
+	stateMutex.Lock()
+	defer stateMutex.Unlock()
+	delete(MountedPartitions, id)
+}
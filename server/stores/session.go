@@ -0,0 +1,112 @@
+package stores
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"server/structures"
+	"sync"
+	"time"
+)
+
+// SessionTTL es cuanto dura una sesion sin usarse, cada uso la renueva
+const SessionTTL = 30 * time.Minute
+
+// Session es el login de un cliente de la API, lo mismo que guardan las variables globales para la consola
+type Session struct {
+	IdPartition string
+	User        string
+	UserID      int32
+	GroupID     int32
+	WorkingDir  string
+	Expires     time.Time
+}
+
+// IsRoot decide por el UID y no por el nombre, igual que los permisos de los inodos
+func (s Session) IsRoot() bool {
+	return s.UserID == structures.RootUserID
+}
+
+// ErrSession es el tipo del error de un token invalido o vencido, la API responde 401
+var ErrSession = errors.New("sesion invalida")
+
+var (
+	sessions      = make(map[string]*Session) //token:sesion
+	sessionsMutex sync.Mutex
+)
+
+// CurrentSession copia la sesion de las variables globales
+func CurrentSession() Session {
+	return Session{
+		IdPartition: LogedIdPartition,
+		User:        LogedUser,
+		UserID:      LogedUserID,
+		GroupID:     LogedUserGroupID,
+		WorkingDir:  LogedWorkingDir,
+	}
+}
+
+// Apply pone la sesion en las variables globales de la consola
+func (s Session) Apply() {
+	LogedIdPartition, LogedUser, LogedWorkingDir = s.IdPartition, s.User, s.WorkingDir
+	LogedUserID, LogedUserGroupID = s.UserID, s.GroupID
+}
+
+// NewSession registra la sesion y devuelve su token
+func NewSession(session Session) (string, error) {
+	buffer := make([]byte, 16)
+	_, err := rand.Read(buffer)
+	if err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(buffer)
+	session.Expires = time.Now().Add(SessionTTL)
+
+	sessionsMutex.Lock()
+	defer sessionsMutex.Unlock()
+	removeExpiredSessions()
+	sessions[token] = &session
+	return token, nil
+}
+
+// GetSession devuelve una copia de la sesion del token y renueva su vencimiento
+func GetSession(token string) (Session, error) {
+	sessionsMutex.Lock()
+	defer sessionsMutex.Unlock()
+	session, exists := sessions[token]
+	if !exists {
+		return Session{}, structures.NewError(ErrSession, "sesion invalida, inicie sesion de nuevo")
+	}
+	if time.Now().After(session.Expires) {
+		delete(sessions, token)
+		return Session{}, structures.NewError(ErrSession, "la sesion expiro, inicie sesion de nuevo")
+	}
+	session.Expires = time.Now().Add(SessionTTL)
+	return *session, nil
+}
+
+// UpdateSession guarda los cambios de la sesion, por ejemplo si un comando cambio el grupo del usuario
+func UpdateSession(token string, session Session) {
+	sessionsMutex.Lock()
+	defer sessionsMutex.Unlock()
+	if current, exists := sessions[token]; exists {
+		session.Expires = current.Expires
+		sessions[token] = &session
+	}
+}
+
+func RevokeSession(token string) {
+	sessionsMutex.Lock()
+	defer sessionsMutex.Unlock()
+	delete(sessions, token)
+}
+
+// removeExpiredSessions se llama al crear sesiones para que el mapa no crezca con las abandonadas
+func removeExpiredSessions() {
+	now := time.Now()
+	for token, session := range sessions {
+		if now.After(session.Expires) {
+			delete(sessions, token)
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package stores
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"server/console"
+	"server/structures"
+	"server/utils"
+	"strconv"
+	"strings"
+)
+
+const (
+	stateFileName   = "mia_state.json"
+	historyFileName = "mia_history" //lineas que se escribieron en la consola
+)
+
+// state es lo que vive en memoria y se guarda en el directorio de discos para sobrevivir a un reinicio
+type state struct {
+	MountedPartitions    map[string]string `json:"mounted_partitions"`
+	LoadedDiskPaths      map[string]string `json:"loaded_disks"`
+	PathToLetter         map[string]string `json:"path_to_letter"`
+	PathToPartitionCount map[string]int    `json:"path_to_partition_count"`
+	NextLetterIndex      int               `json:"next_letter_index"`
+	LetterCounterDisks   int32             `json:"letter_counter_disks"`
+	LogedIdPartition     string            `json:"loged_id_partition"`
+	LogedUser            string            `json:"loged_user"`
+	LogedUserID          int32             `json:"loged_user_id"`
+	LogedUserGroupID     int32             `json:"loged_user_group_id"`
+	LogedWorkingDir      string            `json:"loged_working_dir"`
+}
+
+func stateFilePath() string {
+	return filepath.Join(pathDisk, stateFileName)
+}
+
+// HistoryFilePath es el historial de la consola, vive junto al archivo de estado
+func HistoryFilePath() string {
+	return filepath.Join(pathDisk, historyFileName)
+}
+
+// SaveState guarda las particiones montadas, los discos, los contadores de IDs y la sesion.
+// Si falla solo se avisa, el comando que lo llama ya se ejecuto.
+func SaveState() {
+	stateMutex.RLock()
+	defer stateMutex.RUnlock()
+	nextLetterIndex, letterCounterDisks := utils.LetterCounters()
+	current := state{
+		MountedPartitions:    MountedPartitions,
+		LoadedDiskPaths:      LoadedDiskPaths,
+		PathToLetter:         utils.PathToLetter,
+		PathToPartitionCount: utils.PathToPartitionCount,
+		NextLetterIndex:      nextLetterIndex,
+		LetterCounterDisks:   letterCounterDisks,
+		LogedIdPartition:     LogedIdPartition,
+		LogedUser:            LogedUser,
+		LogedUserID:          LogedUserID,
+		LogedUserGroupID:     LogedUserGroupID,
+		LogedWorkingDir:      LogedWorkingDir,
+	}
+	content, err := json.MarshalIndent(current, "", "  ")
+	if err == nil {
+		// Se escribe a un temporal y se renombra para no dejar el archivo a medias
+		temp := stateFilePath() + ".tmp"
+		err = os.WriteFile(temp, content, 0644)
+		if err == nil {
+			err = os.Rename(temp, stateFilePath())
+		}
+	}
+	if err != nil {
+		console.PrintWarning(fmt.Sprintf("⚠️ No se pudo guardar el estado: %v", err))
+	}
+}
+
+// LoadState restaura el estado guardado y lo completa con los discos del directorio.
+// Se descartan los discos que ya no existen y las particiones que ya no estan montadas con ese ID.
+func LoadState() error {
+	content, err := os.ReadFile(stateFilePath())
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err == nil {
+		saved := state{}
+		err = json.Unmarshal(content, &saved)
+		if err != nil {
+			return structures.Errorf(structures.ErrCorrupt, "el archivo de estado %s esta dañado: %v", stateFilePath(), err)
+		}
+		stateMutex.Lock()
+		restoreState(&saved)
+		stateMutex.Unlock()
+	}
+
+	stateMutex.Lock()
+	removeStaleEntries()
+	err = loadDisksFromDirectory()
+	stateMutex.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if LogedIdPartition != "" && MountedDiskPath(LogedIdPartition) == "" {
+		LogedIdPartition, LogedUser, LogedWorkingDir = "", "", ""
+		LogedUserID, LogedUserGroupID = 0, 0
+	}
+	SaveState()
+	return nil
+}
+
+func restoreState(saved *state) {
+	for id, path := range saved.MountedPartitions {
+		MountedPartitions[id] = path
+	}
+	for name, path := range saved.LoadedDiskPaths {
+		LoadedDiskPaths[name] = path
+	}
+	for path, letter := range saved.PathToLetter {
+		utils.PathToLetter[path] = letter
+	}
+	for path, count := range saved.PathToPartitionCount {
+		utils.PathToPartitionCount[path] = count
+	}
+	utils.SetLetterCounters(saved.NextLetterIndex, saved.LetterCounterDisks)
+	LogedIdPartition, LogedUser = saved.LogedIdPartition, saved.LogedUser
+	if saved.LogedIdPartition != "" {
+		LogedUserID, LogedUserGroupID = saved.LogedUserID, saved.LogedUserGroupID
+		LogedWorkingDir = saved.LogedWorkingDir
+		// Los estados de antes de cd no traen directorio
+		if LogedWorkingDir == "" {
+			LogedWorkingDir = "/"
+		}
+	}
+}
+
+func removeStaleEntries() {
+	for name, path := range LoadedDiskPaths {
+		if !diskExists(path) {
+			delete(LoadedDiskPaths, name)
+		}
+	}
+	for path := range utils.PathToPartitionCount {
+		if !diskExists(path) {
+			delete(utils.PathToPartitionCount, path)
+		}
+	}
+	for id, path := range MountedPartitions {
+		if !diskExists(path) {
+			delete(MountedPartitions, id)
+			continue
+		}
+		mbr := structures.MBR{}
+		if mbr.DeserializeMBR(path) != nil {
+			delete(MountedPartitions, id)
+			continue
+		}
+		if _, err := mbr.FindPartitionByID(path, id); err != nil {
+			delete(MountedPartitions, id)
+		}
+	}
+}
+
+// loadDisksFromDirectory agrega los .dsk del directorio y las particiones que en disco estan montadas,
+// asi se recupera el estado aunque el archivo se haya perdido
+func loadDisksFromDirectory() error {
+	entries, err := os.ReadDir(pathDisk)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".dsk") {
+			continue
+		}
+		letter := strings.TrimSuffix(entry.Name(), ".dsk")
+		path := GetPathDisk(letter)
+		if _, exists := LoadedDiskPaths[entry.Name()]; !exists {
+			LoadedDiskPaths[entry.Name()] = path
+		}
+		// Los proximos mkdisk no deben volver a usar la letra de un disco existente
+		if len(letter) == 1 && letter[0] >= 'A' && letter[0] <= 'Z' {
+			nextLetterIndex, letterCounterDisks := utils.LetterCounters()
+			if index := int32(letter[0]-'A') + 1; index > letterCounterDisks {
+				utils.SetLetterCounters(nextLetterIndex, index)
+			}
+		}
+
+		mbr := structures.MBR{}
+		if mbr.DeserializeMBR(path) != nil {
+			continue
+		}
+		partitions, err := mbr.GetAllPartitions(path)
+		if err != nil {
+			continue
+		}
+		for _, partition := range partitions {
+			if partition.Part_status[0] != '1' {
+				continue
+			}
+			id := strings.Trim(string(partition.Part_id[:]), "\x00 ")
+			if id == "" {
+				continue
+			}
+			if _, exists := MountedPartitions[id]; !exists {
+				MountedPartitions[id] = path
+			}
+			// El correlativo del ID no se puede repetir en los siguientes mount
+			correlative, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(id, letter), Carnet))
+			if err == nil && correlative > utils.PathToPartitionCount[path] {
+				utils.PathToPartitionCount[path] = correlative
+			}
+		}
+	}
+	return nil
+}
+
+func diskExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
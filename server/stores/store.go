@@ -1,7 +1,6 @@
 package stores
 
 import (
-	"errors"
 	"fmt"
 	"path/filepath"
 	"server/console"
@@ -11,23 +10,34 @@ import (
 )
 
 const Carnet string = "05"                                                           //2023007705
-const PathDisk string = "/home/ubuntu/MIA_P2_202307705_1VAC1S2025/test/" //FIXME cambiar el path
+const defaultPathDisk string = "/home/ubuntu/MIA_P2_202307705_1VAC1S2025/test/"
+
+var pathDisk = defaultPathDisk // directorio de los discos y del archivo de estado
 
 var (
 	MountedPartitions map[string]string = make(map[string]string) //ID:path
 	LogedIdPartition  string            = ""
 	LogedUser         string            = ""
+	LogedWorkingDir   string            = "" //directorio de trabajo de la sesion, para cd y las rutas relativas
+	LogedUserID       int32             = 0
+	LogedUserGroupID  int32             = 0
 	LoadedDiskPaths   map[string]string = make(map[string]string) //Nombre:path
 )
 
+// SetPathDisk cambia el directorio de los discos, main lo toma de MIA_DISK_DIR y las pruebas usan uno temporal.
+// Se llama antes de LoadState
+func SetPathDisk(dir string) {
+	pathDisk = dir
+}
+
 func GetPathDisk(name string) string {
-	return fmt.Sprintf(`%s/%s.dsk`, PathDisk, name)
+	return fmt.Sprintf(`%s/%s.dsk`, pathDisk, name)
 }
 
 func GetMountedPartition(id string) (*structures.PARTITION, string, error) {
-	path := MountedPartitions[id]
+	path := MountedDiskPath(id)
 	if path == "" {
-		return nil, "", errors.New("la particion no esta montada")
+		return nil, "", structures.NewError(structures.ErrNotMounted, "la particion no esta montada")
 	}
 	var mbr structures.MBR
 
@@ -36,7 +46,7 @@ func GetMountedPartition(id string) (*structures.PARTITION, string, error) {
 		return nil, "", err
 	}
 
-	partition, _, err := mbr.GetPartitionByID(id)
+	partition, err := mbr.FindPartitionByID(path, id)
 	if partition == nil {
 		return nil, "", err
 	}
@@ -45,6 +55,8 @@ func GetMountedPartition(id string) (*structures.PARTITION, string, error) {
 }
 
 func DeleteMountedPartitions(path string) {
+	stateMutex.Lock()
+	defer stateMutex.Unlock()
 	for key, value := range MountedPartitions {
 		if value == path {
 			delete(MountedPartitions, key)
@@ -61,6 +73,8 @@ func DeleteMountedPartitions(path string) {
 
 // Nueva función para limpiar solo los discos cargados
 func RemoveLoadedDisk(path string) {
+	stateMutex.Lock()
+	defer stateMutex.Unlock()
 	for key, value := range LoadedDiskPaths {
 		if value == path {
 			delete(LoadedDiskPaths, key)
@@ -69,9 +83,9 @@ func RemoveLoadedDisk(path string) {
 }
 
 func GetMountedPartitionRep(id string) (*structures.MBR, *structures.SuperBlock, string, error) {
-	path := MountedPartitions[id]
+	path := MountedDiskPath(id)
 	if path == "" {
-		return nil, nil, "", errors.New("la particion no esta montada")
+		return nil, nil, "", structures.NewError(structures.ErrNotMounted, "la particion no esta montada")
 	}
 
 	var mbr structures.MBR
@@ -79,7 +93,7 @@ func GetMountedPartitionRep(id string) (*structures.MBR, *structures.SuperBlock,
 	if err != nil {
 		return nil, nil, "", err
 	}
-	partition, _, err := mbr.GetPartitionByID(id)
+	partition, err := mbr.FindPartitionByID(path, id)
 	if partition == nil {
 		return nil, nil, "", err
 	}
@@ -96,15 +110,15 @@ func GetMountedPartitionRep(id string) (*structures.MBR, *structures.SuperBlock,
 }
 
 func GetNameDisk(idDisk string) string {
-	pathDisk := MountedPartitions[idDisk]
+	pathDisk := MountedDiskPath(idDisk)
 	baseName := strings.TrimSuffix(filepath.Base(pathDisk), filepath.Ext(pathDisk))
 	return baseName
 }
 
 func GetMountedPartitionSuperblock(id string) (*structures.SuperBlock, *structures.PARTITION, string, error) {
-	path := MountedPartitions[id]
+	path := MountedDiskPath(id)
 	if path == "" {
-		return nil, nil, "", errors.New("la particion no esta montada")
+		return nil, nil, "", structures.NewError(structures.ErrNotMounted, "la particion no esta montada")
 	}
 
 	var mbr structures.MBR
@@ -114,7 +128,7 @@ func GetMountedPartitionSuperblock(id string) (*structures.SuperBlock, *structur
 		return nil, nil, "", err
 	}
 
-	partition, _, err := mbr.GetPartitionByID(id)
+	partition, err := mbr.FindPartitionByID(path, id)
 	if err != nil {
 		return nil, nil, "", err
 	}
@@ -131,14 +145,20 @@ func GetMountedPartitionSuperblock(id string) (*structures.SuperBlock, *structur
 
 // Nueva función para limpiar completamente el estado
 func ClearAllDisks() {
+	stateMutex.Lock()
+	defer stateMutex.Unlock()
 	LoadedDiskPaths = make(map[string]string)
 	MountedPartitions = make(map[string]string)
 	LogedIdPartition = ""
 	LogedUser = ""
+	LogedWorkingDir = ""
+	LogedUserID, LogedUserGroupID = 0, 0
 }
 
 // Función mejorada para debug del estado actual
 func PrintCurrentState() {
+	stateMutex.RLock()
+	defer stateMutex.RUnlock()
 	console.PrintInfo("=== ESTADO ACTUAL DEL SISTEMA ===")
 	console.PrintInfo(fmt.Sprintf("📀 Discos cargados: %d", len(LoadedDiskPaths)))
 	for letter, path := range LoadedDiskPaths {
@@ -79,4 +79,68 @@ func (sb *SuperBlock) UpdateBitmapBlock(path string) error {
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+// Libera un inodo en el bitmap de inodos, si ya estaba libre no cambia el contador
+func (sb *SuperBlock) FreeBitmapInode(path string, inodeIndex int32) error {
+	if inodeIndex < 0 || inodeIndex >= sb.TotalInodes() {
+		return NewError(ErrCorrupt, "índice de inodo inválido")
+	}
+	previous, err := setBitmap(path, sb.S_bm_inode_start+inodeIndex, '0')
+	if err != nil {
+		return err
+	}
+	if previous == '1' {
+		sb.S_free_inodes_count++
+	}
+	return nil
+}
+
+// Indica si el inodo esta marcado como usado en el bitmap de inodos
+func (sb *SuperBlock) IsInodeUsed(path string, inodeIndex int32) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	char := make([]byte, 1)
+	_, err = file.ReadAt(char, int64(sb.S_bm_inode_start)+int64(inodeIndex))
+	if err != nil {
+		return false, err
+	}
+	return char[0] == '1', nil
+}
+
+// firstFree devuelve el primer indice del bitmap que no tiene la marca used, -1 si estan todos usados
+func firstFree(diskPath string, start, length int32, used byte) (int32, error) {
+	bitmap, err := readBitmap(diskPath, start, length)
+	if err != nil {
+		return -1, err
+	}
+	for i, mark := range bitmap {
+		if mark != used {
+			return int32(i), nil
+		}
+	}
+	return -1, nil
+}
+
+// setBitmap escribe la marca en la posicion del bitmap y devuelve la que tenia
+func setBitmap(diskPath string, position int32, mark byte) (byte, error) {
+	file, err := os.OpenFile(diskPath, os.O_RDWR, 0644)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	previous := make([]byte, 1)
+	_, err = file.ReadAt(previous, int64(position))
+	if err != nil {
+		return 0, err
+	}
+	_, err = file.WriteAt([]byte{mark}, int64(position))
+	if err != nil {
+		return 0, err
+	}
+	return previous[0], nil
+}
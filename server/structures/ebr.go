@@ -0,0 +1,289 @@
+package structures
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"strings"
+)
+
+type EBR struct {
+	Part_mount       [1]byte
+	Part_fit         [1]byte
+	Part_start       int32 // inicio de los datos de la logica, justo despues del EBR
+	Part_size        int32
+	Part_next        int32 // posicion del siguiente EBR, -1 si es el ultimo
+	Part_name        [16]byte
+	Part_correlative int32
+	Part_id          [4]byte
+}
+
+/*
+Part Mount:
+
+	N: Disponible
+	0: Creado
+	1: Montado
+*/
+func (ebr *EBR) Serialize(path string, offset int64) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Seek(offset, 0)
+	if err != nil {
+		return err
+	}
+
+	return binary.Write(file, binary.LittleEndian, ebr)
+}
+
+func (ebr *EBR) Deserialize(path string, offset int64) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Seek(offset, 0)
+	if err != nil {
+		return err
+	}
+
+	buffer := make([]byte, binary.Size(EBR{}))
+	_, err = file.Read(buffer)
+	if err != nil {
+		return err
+	}
+
+	reader := bytes.NewReader(buffer)
+	return binary.Read(reader, binary.LittleEndian, ebr)
+}
+
+// NewEmptyEBR es el EBR que queda al inicio de la extendida cuando no tiene logicas
+func NewEmptyEBR(next int32) *EBR {
+	return &EBR{
+		Part_mount: [1]byte{'N'}, Part_fit: [1]byte{'N'}, Part_start: -1, Part_size: -1, Part_next: next, Part_name: [16]byte{'N'}, Part_correlative: -1, Part_id: [4]byte{'N'},
+	}
+}
+
+func (ebr *EBR) CreatePartition(ebrStart, partSize int, partFit, partName string) {
+	ebr.Part_mount[0] = '0'
+	ebr.Part_start = int32(ebrStart + binary.Size(EBR{}))
+	ebr.Part_size = int32(partSize)
+	if len(partFit) > 0 {
+		ebr.Part_fit[0] = partFit[0]
+	}
+	ebr.Part_correlative = -1
+	copy(ebr.Part_name[:], partName)
+}
+
+func (ebr *EBR) MountPartition(correlative int, id string) error {
+	ebr.Part_mount[0] = '1'
+	ebr.Part_correlative = int32(correlative)
+	copy(ebr.Part_id[:], id)
+	return nil
+}
+
+func (ebr *EBR) IsUsed() bool {
+	return ebr.Part_size > 0
+}
+
+// ToPartition devuelve la logica con la forma de una particion del MBR para reutilizar mkfs, rep y los comandos
+func (ebr *EBR) ToPartition() *PARTITION {
+	return &PARTITION{
+		Part_status:      ebr.Part_mount,
+		Part_type:        [1]byte{'L'},
+		Part_fit:         ebr.Part_fit,
+		Part_start:       ebr.Part_start,
+		Part_size:        ebr.Part_size,
+		Part_name:        ebr.Part_name,
+		Part_correlative: ebr.Part_correlative,
+		Part_id:          ebr.Part_id,
+	}
+}
+
+// ReadEBRChain recorre los EBR de la extendida, incluido el primero aunque este vacio
+func ReadEBRChain(diskPath string, extendedStart int32) ([]EBR, []int32, error) {
+	var ebrs []EBR
+	var offsets []int32
+	offset := extendedStart
+	for offset != -1 {
+		ebr := EBR{}
+		err := ebr.Deserialize(diskPath, int64(offset))
+		if err != nil {
+			return nil, nil, err
+		}
+		// Los EBR van ordenados por posicion, un next hacia atras es un disco sin inicializar
+		if ebr.Part_next <= offset {
+			ebr.Part_next = -1
+		}
+		ebrs = append(ebrs, ebr)
+		offsets = append(offsets, offset)
+		offset = ebr.Part_next
+	}
+	return ebrs, offsets, nil
+}
+
+// GetLogicalPartitions devuelve las logicas creadas y la posicion de su EBR
+func (mbr *MBR) GetLogicalPartitions(diskPath string) ([]EBR, []int32, error) {
+	extended, err := mbr.GetExtendedPartition()
+	if err != nil {
+		return nil, nil, nil
+	}
+	ebrs, offsets, err := ReadEBRChain(diskPath, extended.Part_start)
+	if err != nil {
+		return nil, nil, err
+	}
+	var logicals []EBR
+	var positions []int32
+	for i := range ebrs {
+		if ebrs[i].IsUsed() {
+			logicals = append(logicals, ebrs[i])
+			positions = append(positions, offsets[i])
+		}
+	}
+	return logicals, positions, nil
+}
+
+func (mbr *MBR) GetLogicalPartitionByName(diskPath, name string) (*EBR, int32, int) {
+	logicals, offsets, err := mbr.GetLogicalPartitions(diskPath)
+	if err != nil {
+		return nil, -1, -1
+	}
+	for i := range logicals {
+		partitionName := strings.Trim(string(logicals[i].Part_name[:]), "\x00 ")
+		if strings.EqualFold(partitionName, strings.Trim(name, "\x00")) {
+			return &logicals[i], offsets[i], i
+		}
+	}
+	return nil, -1, -1
+}
+
+func (mbr *MBR) GetLogicalPartitionByID(diskPath, id string) (*EBR, int32, error) {
+	logicals, offsets, err := mbr.GetLogicalPartitions(diskPath)
+	if err != nil {
+		return nil, -1, err
+	}
+	for i := range logicals {
+		if logicals[i].Part_mount[0] != '1' {
+			continue
+		}
+		partitionID := strings.Trim(string(logicals[i].Part_id[:]), "\x00 ")
+		if strings.EqualFold(partitionID, strings.Trim(id, "\x00 ")) {
+			return &logicals[i], offsets[i], nil
+		}
+	}
+	return nil, -1, NewError(ErrNotFound, "partición no encontrada")
+}
+
+// GetAllPartitions devuelve las particiones del MBR seguidas de las logicas
+func (mbr *MBR) GetAllPartitions(diskPath string) ([]PARTITION, error) {
+	partitions := append([]PARTITION{}, mbr.Mbr_partitions[:]...)
+	logicals, _, err := mbr.GetLogicalPartitions(diskPath)
+	if err != nil {
+		return nil, err
+	}
+	for i := range logicals {
+		partitions = append(partitions, *logicals[i].ToPartition())
+	}
+	return partitions, nil
+}
+
+// FindPartitionByID busca entre las primarias y luego entre las logicas de la extendida
+func (mbr *MBR) FindPartitionByID(diskPath, id string) (*PARTITION, error) {
+	partition, _, err := mbr.GetPartitionByID(id)
+	if err == nil {
+		return partition, nil
+	}
+	ebr, _, err := mbr.GetLogicalPartitionByID(diskPath, id)
+	if err != nil {
+		return nil, err
+	}
+	return ebr.ToPartition(), nil
+}
+
+// CreateLogicalPartition ubica la logica dentro de la extendida segun el ajuste y la enlaza a la cadena
+func (mbr *MBR) CreateLogicalPartition(diskPath string, sizeBytes int, fit, name string) error {
+	extended, err := mbr.GetExtendedPartition()
+	if err != nil {
+		return NewError(ErrNotFound, "no existe una particion extendida para crear la logica")
+	}
+	ebrs, offsets, err := ReadEBRChain(diskPath, extended.Part_start)
+	if err != nil {
+		return err
+	}
+
+	// Si no se indica ajuste se usa el de la extendida
+	if fit == "" {
+		fit = string(extended.Part_fit[0]) + "F"
+	}
+
+	// El hueco tiene que alcanzar para el EBR y los datos
+	var used []Gap
+	for i := range ebrs {
+		if ebrs[i].IsUsed() {
+			used = append(used, Gap{Start: offsets[i], Size: ebrs[i].Part_start + ebrs[i].Part_size - offsets[i]})
+		}
+	}
+	gaps := FreeGaps(extended.Part_start, extended.Part_start+extended.Part_size, used)
+	gap, ok := SelectGap(gaps, int32(sizeBytes+binary.Size(EBR{})), fit[0])
+	if !ok {
+		return NewError(ErrNoSpace, "no hay espacio suficiente en la particion extendida")
+	}
+
+	neoEBR := &EBR{}
+	neoEBR.CreatePartition(int(gap.Start), sizeBytes, fit, name)
+
+	// Si la logica va al inicio se reutiliza el primer EBR, si no se enlaza despues del EBR anterior
+	if gap.Start == extended.Part_start {
+		neoEBR.Part_next = ebrs[0].Part_next
+		return neoEBR.Serialize(diskPath, int64(gap.Start))
+	}
+	previous := 0
+	for i := range offsets {
+		if offsets[i] < gap.Start {
+			previous = i
+		}
+	}
+	neoEBR.Part_next = ebrs[previous].Part_next
+	ebrs[previous].Part_next = gap.Start
+	err = neoEBR.Serialize(diskPath, int64(gap.Start))
+	if err != nil {
+		return err
+	}
+	return ebrs[previous].Serialize(diskPath, int64(offsets[previous]))
+}
+
+// DeleteLogicalPartition desenlaza el EBR de la logica y devuelve el rango que ocupaba
+func (mbr *MBR) DeleteLogicalPartition(diskPath, name string) (int32, int32, error) {
+	extended, err := mbr.GetExtendedPartition()
+	if err != nil {
+		return -1, -1, err
+	}
+	ebrs, offsets, err := ReadEBRChain(diskPath, extended.Part_start)
+	if err != nil {
+		return -1, -1, err
+	}
+	for i := range ebrs {
+		partitionName := strings.Trim(string(ebrs[i].Part_name[:]), "\x00 ")
+		if !ebrs[i].IsUsed() || !strings.EqualFold(partitionName, strings.Trim(name, "\x00")) {
+			continue
+		}
+		if ebrs[i].Part_mount[0] == '1' {
+			return -1, -1, NewError(ErrInvalid, "no se puede eliminar una particion montada")
+		}
+		end := ebrs[i].Part_start + ebrs[i].Part_size
+		// El primer EBR nunca se quita de la cadena, solo queda vacio
+		if i == 0 {
+			err = NewEmptyEBR(ebrs[0].Part_next).Serialize(diskPath, int64(offsets[0]))
+			return ebrs[0].Part_start, end, err
+		}
+		ebrs[i-1].Part_next = ebrs[i].Part_next
+		err = ebrs[i-1].Serialize(diskPath, int64(offsets[i-1]))
+		return offsets[i], end, err
+	}
+	return -1, -1, Errorf(ErrNotFound, "la particion %s no existe", name)
+}
@@ -0,0 +1,42 @@
+package structures
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Tipos de error que comparten structures, stores y commands, se comparan con errors.Is.
+// La API los traduce a codigos HTTP.
+var (
+	ErrNotFound   = errors.New("no encontrado")
+	ErrPermission = errors.New("permiso denegado")
+	ErrNoSpace    = errors.New("sin espacio")
+	ErrExists     = errors.New("ya existe")
+	ErrNotMounted = errors.New("particion no montada")
+	ErrInvalid    = errors.New("argumento invalido")
+	ErrCorrupt    = errors.New("sistema de archivos dañado")
+)
+
+// kindError le pone un tipo a un error sin cambiar su mensaje
+type kindError struct {
+	kind error
+	err  error
+}
+
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+func (e *kindError) Unwrap() []error {
+	return []error{e.kind, e.err}
+}
+
+// NewError crea un error del tipo kind con el mensaje text
+func NewError(kind error, text string) error {
+	return &kindError{kind: kind, err: errors.New(text)}
+}
+
+// Errorf es fmt.Errorf con tipo, %w sigue envolviendo el error original
+func Errorf(kind error, format string, args ...any) error {
+	return &kindError{kind: kind, err: fmt.Errorf(format, args...)}
+}
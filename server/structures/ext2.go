@@ -4,1157 +4,248 @@ import (
 	// structures "server/structures"
 
 	"errors"
-	"fmt"
-	"os"
-	utils "server/utils"
 	"strings"
 	"time"
 )
 
-func (sb *SuperBlock) createFolderInInode(path string, inodeIndex int32, parentsDir []string, destDir string, justSearchingAFile bool) error {
-	inode := &Inode{}
-	err := inode.Deserialize(path, int64(sb.S_inode_start+(inodeIndex*sb.S_inode_size)))
+// findParentFolder devuelve la carpeta parentsDir desde inodeIndex con los permisos del usuario
+func (sb *SuperBlock) findParentFolder(diskPath string, inodeIndex int32, parentsDir []string, userID, groupID int32) (int32, *Inode, error) {
+	folderIndex, folder, err := sb.lookupFrom(diskPath, inodeIndex, parentsDir, userID, groupID)
 	if err != nil {
-		return err
-	}
-	if inode.I_type[0] == '1' {
-		return nil
+		return -1, nil, err
 	}
-	var inodoPadre int32
-	for i, blockIndex := range inode.I_block {
-		numeroApuntadorIndirect := sb.S_blocks_count
-		if blockIndex == -1 {
-			if !justSearchingAFile && len(parentsDir) != 0 {
-				return errors.New("ruta invalida, asegurese que exita la ruta antes")
-			}
-			// Aqui se debe validar si es la iteracion 13 en adelante para hacer lo de los apuntadores indirectos
-			if i >= 14 {
-				inode.I_block[i] = sb.S_blocks_count
-
-				pointerBlock := &PointerBlock{
-					P_pointers: [16]int32{-1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
-				}
-				err = pointerBlock.Serialize(path, int64(sb.S_first_blo))
-				if err != nil {
-					return err
-				}
-
-				err = sb.UpdateBitmapBlock(path)
-				if err != nil {
-					return err
-				}
-				sb.S_blocks_count++
-				sb.S_free_blocks_count--
-				sb.S_first_blo += sb.S_block_size
-
-				//Bloque folder
-				folderBlock := &FolderBlock{
-					B_content: [4]FolderContent{
-						{B_name: [12]byte{'.'}, B_inodo: inodeIndex},
-						{B_name: [12]byte{'.', '.'}, B_inodo: inodoPadre},
-						{B_name: [12]byte{'-'}, B_inodo: -1},
-						{B_name: [12]byte{'-'}, B_inodo: -1},
-					},
-				}
-				err = folderBlock.Serialize(path, int64(sb.S_first_blo))
-				if err != nil {
-					return err
-				}
-
-				err = sb.UpdateBitmapBlock(path)
-				if err != nil {
-					return err
-				}
-				sb.S_blocks_count++
-				sb.S_free_blocks_count--
-				sb.S_first_blo += sb.S_block_size
-
-				inode.Serialize(path, int64(sb.S_inode_start+(inodeIndex*sb.S_inode_size)))
-				flag, err := sb.folderFromAuntadorIndirecto13(path, inodeIndex, parentsDir, destDir, justSearchingAFile, numeroApuntadorIndirect, inodoPadre)
-				if err != nil {
-					return err
-				}
-				if flag {
-					return nil
-				}
-			} else {
-				inode.I_block[i] = sb.S_blocks_count
-				folderBlock := &FolderBlock{
-					B_content: [4]FolderContent{
-						{B_name: [12]byte{'.'}, B_inodo: inodeIndex},
-						{B_name: [12]byte{'.', '.'}, B_inodo: inodoPadre},
-						{B_name: [12]byte{'-'}, B_inodo: -1},
-						{B_name: [12]byte{'-'}, B_inodo: -1},
-					},
-				}
-				err = folderBlock.Serialize(path, int64(sb.S_first_blo))
-				if err != nil {
-					return err
-				}
-
-				err = sb.UpdateBitmapBlock(path)
-				if err != nil {
-					return err
-				}
-				sb.S_blocks_count++
-				sb.S_free_blocks_count--
-				sb.S_first_blo += sb.S_block_size
-				inode.Serialize(path, int64(sb.S_inode_start+(inodeIndex*sb.S_inode_size)))
-				return sb.createFolderInInode(path, inodeIndex, parentsDir, destDir, justSearchingAFile)
-			}
-		}
-
-		if i >= 14 {
-			flag, err := sb.folderFromAuntadorIndirecto13(path, inodeIndex, parentsDir, destDir, justSearchingAFile, blockIndex, inodoPadre)
-			if err != nil {
-				return err
-			}
-			if flag {
-				return nil
-			}
-
-		}
-		block := &FolderBlock{}
-		err := block.Deserialize(path, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
-		if err != nil {
-			return err
-		}
-
-		for indexContent := 2; indexContent < len(block.B_content); indexContent++ {
-			content := block.B_content[indexContent]
-
-			if len(parentsDir) != 0 {
-
-				if content.B_inodo == -1 {
-					continue
-				}
-
-				parentDir, err := utils.First(parentsDir)
-				if err != nil {
-					return err
-				}
-
-				contentName := strings.Trim(string(content.B_name[:]), "\x00 ")
-				parentDirName := strings.Trim(parentDir, "\x00 ")
-				if strings.EqualFold(contentName, parentDirName) {
-					err := sb.createFolderInInode(path, content.B_inodo, utils.RemoveElement(parentsDir, 0), destDir, justSearchingAFile)
-					if err != nil {
-						return err
-					}
-					return nil
-				}
-			} else {
-				contentName := strings.Trim(string(content.B_name[:]), "\x00")
-				destinationName := strings.Trim(destDir, "\x00")
-				if strings.EqualFold(contentName, destinationName) {
-					return errors.New("ya existe un directorio con el mismo nombre")
-				}
-				if content.B_inodo != -1 {
-					tempContent := block.B_content[1]
-					inodoPadre = tempContent.B_inodo
-					continue
-				}
-				outcome, err := inode.HasPermissionsToWrite(utils.LogedUserID, utils.LogedUserGroupID)
-				if err != nil {
-					return err
-				}
-				if !outcome {
-					return errors.New("inaccesible por falta de permisos")
-				}
-				copy(content.B_name[:], destDir)
-				content.B_inodo = sb.S_inodes_count
-
-				block.B_content[indexContent] = content
-
-				err = block.Serialize(path, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
-				if err != nil {
-					return err
-				}
-
-				folderInode := &Inode{
-					I_uid:   utils.LogedUserID,
-					I_gid:   utils.LogedUserGroupID,
-					I_size:  0,
-					I_atime: float32(time.Now().Unix()),
-					I_ctime: float32(time.Now().Unix()),
-					I_mtime: float32(time.Now().Unix()),
-					I_block: [15]int32{sb.S_blocks_count, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
-					I_type:  [1]byte{'0'},
-					I_perm:  [3]byte{'6', '6', '4'},
-				}
-
-				err = folderInode.Serialize(path, int64(sb.S_first_ino))
-				if err != nil {
-					return err
-				}
-
-				err = sb.UpdateBitmapInode(path)
-				if err != nil {
-					return err
-				}
-
-				sb.S_inodes_count++
-				sb.S_free_inodes_count--
-				sb.S_first_ino += sb.S_inode_size
-
-				folderBlock := &FolderBlock{
-					B_content: [4]FolderContent{
-						{B_name: [12]byte{'.'}, B_inodo: content.B_inodo},
-						{B_name: [12]byte{'.', '.'}, B_inodo: inodeIndex},
-						{B_name: [12]byte{'-'}, B_inodo: -1},
-						{B_name: [12]byte{'-'}, B_inodo: -1},
-					},
-				}
-
-				err = folderBlock.Serialize(path, int64(sb.S_first_blo))
-				if err != nil {
-					return err
-				}
-
-				err = sb.UpdateBitmapBlock(path)
-				if err != nil {
-					return err
-				}
-				sb.S_blocks_count++
-				sb.S_free_blocks_count--
-				sb.S_first_blo += sb.S_block_size
-
-				return nil
-			}
-		}
-
+	if folder.I_type[0] != '0' {
+		return -1, nil, &NotFoundError{Path: "/" + strings.Join(parentsDir, "/")}
 	}
-	return nil
+	return folderIndex, folder, nil
 }
 
-func (sb *SuperBlock) createFolderInInodeWithP(path string, inodeIndex int32, parentsDir []string, destDir string) error {
-	inodo := &Inode{}
-	err := inodo.Deserialize(path, int64(sb.S_inode_start+inodeIndex*sb.S_inode_size))
+func (sb *SuperBlock) createFolderInInode(path string, inodeIndex int32, parentsDir []string, destDir string, justSearchingAFile bool, userID, groupID int32) error {
+	folderIndex, folder, err := sb.findParentFolder(path, inodeIndex, parentsDir, userID, groupID)
 	if err != nil {
 		return err
 	}
-	if len(parentsDir) == 0 {
-		sb.createFolderInInode(path, inodeIndex, make([]string, 0), destDir, true)
-		return nil
-	}
-	nameDir, err := utils.First(parentsDir)
+	destDir = strings.Trim(destDir, "\x00 ")
+	existing, err := sb.FindEntryInFolder(path, folder, destDir)
 	if err != nil {
 		return err
 	}
-
-	flag, neoInodoToVisit, err := existTheDirectory(inodo, nameDir, path, sb)
+	if existing != -1 {
+		return NewError(ErrExists, "ya existe un directorio con el mismo nombre")
+	}
+	outcome, err := folder.HasPermissionsToWrite(userID, groupID)
 	if err != nil {
 		return err
 	}
-	if flag { //si existe el primer dir
-		sb.createFolderInInodeWithP(path, neoInodoToVisit, utils.RemoveElement(parentsDir, 0), destDir)
-	} else { //No existe el primero dir
-		sb.createFolderInInode(path, inodeIndex, make([]string, 0), nameDir, true)
-		sb.createFolderInInodeWithP(path, inodeIndex, parentsDir, destDir)
+	if !outcome {
+		return NewError(ErrPermission, "inaccesible por falta de permisos")
 	}
-	return nil
-}
-
-// Asumiendo que hayan dirs q crear
-func existTheDirectory(inodo *Inode, nameDir string, path string, sb *SuperBlock) (bool, int32, error) {
-	for i, blockIndex := range inodo.I_block {
-		if blockIndex == -1 {
-			return false, 0, nil
-		}
-		if i >= 14 {
-			pointerBlock := &PointerBlock{}
-			err := pointerBlock.Deserialize(path, int64(sb.S_block_start+(sb.S_block_size*blockIndex)))
-			if err != nil {
-				return false, 0, err
-			}
-			for _, value := range pointerBlock.P_pointers {
-				if value == -1 {
-					continue
-				}
-				block := &FolderBlock{}
-				err := block.Deserialize(path, int64(sb.S_block_start+(sb.S_block_size*value)))
-				if err != nil {
-					return false, 0, err
-				}
-				for i := 2; i < len(block.B_content); i++ {
-					content := block.B_content[i]
-					contentName := strings.Trim(string(content.B_name[:]), "\x00 ")
-					parentDirName := strings.Trim(nameDir, "\x00 ")
-					if strings.EqualFold(contentName, parentDirName) {
-						return true, content.B_inodo, nil
-					}
-				}
-			}
-
-		} else {
-			block := &FolderBlock{}
-			err := block.Deserialize(path, int64(sb.S_block_start+(sb.S_block_size*blockIndex)))
-			if err != nil {
-				return false, 0, err
-			}
-			for i := 2; i < len(block.B_content); i++ {
-				content := block.B_content[i]
-				contentName := strings.Trim(string(content.B_name[:]), "\x00 ")
-				parentDirName := strings.Trim(nameDir, "\x00 ")
-				if strings.EqualFold(contentName, parentDirName) {
-					return true, content.B_inodo, nil
-				}
-			}
-		}
+	err = sb.CheckSpaceForEntry(path, folderIndex, destDir, 1, 1)
+	if err != nil {
+		return err
 	}
-	return false, 0, nil
-}
 
-func (sb *SuperBlock) CreateFile(diskPath string, inodeIndex int32, parentsDir []string, destDir string, fileContent string, size int32, justSearchingAFile bool) error {
-	inode := &Inode{}
-	err := inode.Deserialize(diskPath, int64(sb.S_inode_start+(inodeIndex*sb.S_inode_size)))
+	neoInodeIndex, neoInodeOffset, err := sb.reserveInode(path)
 	if err != nil {
 		return err
 	}
-	if inode.I_type[0] == '1' {
-		return nil
+	blockIndex, blockOffset, err := sb.reserveBlock(path)
+	if err != nil {
+		return err
 	}
-	var inodoPadre int32
-	for i, blockIndex := range inode.I_block {
-		numeroApuntadorIndirect := sb.S_blocks_count
-		if blockIndex == -1 {
-			if !justSearchingAFile && len(parentsDir) != 0 {
-				return errors.New("ruta invalida, asegurese que exita la ruta antes")
-			}
-			// Aqui se debe validar si es la iteracion 13 en adelante para hacer lo de los apuntadores indirectos
-			if i >= 14 {
-				inode.I_block[i] = sb.S_blocks_count
-
-				pointerBlock := &PointerBlock{
-					P_pointers: [16]int32{-1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
-				}
-				err = pointerBlock.Serialize(diskPath, int64(sb.S_first_blo))
-				if err != nil {
-					return err
-				}
-
-				err = sb.UpdateBitmapBlock(diskPath)
-				if err != nil {
-					return err
-				}
-				sb.S_blocks_count++
-				sb.S_free_blocks_count--
-				sb.S_first_blo += sb.S_block_size
-
-				//Bloque folder
-				folderBlock := &FolderBlock{
-					B_content: [4]FolderContent{
-						{B_name: [12]byte{'.'}, B_inodo: inodeIndex},
-						{B_name: [12]byte{'.', '.'}, B_inodo: inodoPadre},
-						{B_name: [12]byte{'-'}, B_inodo: -1},
-						{B_name: [12]byte{'-'}, B_inodo: -1},
-					},
-				}
-				err = folderBlock.Serialize(diskPath, int64(sb.S_first_blo))
-				if err != nil {
-					return err
-				}
-
-				err = sb.UpdateBitmapBlock(diskPath)
-				if err != nil {
-					return err
-				}
-				sb.S_blocks_count++
-				sb.S_free_blocks_count--
-				sb.S_first_blo += sb.S_block_size
-
-				inode.Serialize(diskPath, int64(sb.S_inode_start+(inodeIndex*sb.S_inode_size)))
-				flag, err := sb.folderFromAuntadorIndirecto13(diskPath, inodeIndex, parentsDir, destDir, justSearchingAFile, numeroApuntadorIndirect, inodoPadre)
-				if err != nil {
-					return err
-				}
-				if flag {
-					return nil
-				}
-			} else {
-				inode.I_block[i] = sb.S_blocks_count
-				folderBlock := &FolderBlock{
-					B_content: [4]FolderContent{
-						{B_name: [12]byte{'.'}, B_inodo: inodeIndex},
-						{B_name: [12]byte{'.', '.'}, B_inodo: inodoPadre},
-						{B_name: [12]byte{'-'}, B_inodo: -1},
-						{B_name: [12]byte{'-'}, B_inodo: -1},
-					},
-				}
-				err = folderBlock.Serialize(diskPath, int64(sb.S_first_blo))
-				if err != nil {
-					return err
-				}
+	folderInode := &Inode{
+		I_uid:   userID,
+		I_gid:   groupID,
+		I_size:  0,
+		I_atime: float32(time.Now().Unix()),
+		I_ctime: float32(time.Now().Unix()),
+		I_mtime: float32(time.Now().Unix()),
+		I_block: [15]int32{blockIndex, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
+		I_type:  [1]byte{'0'},
+		I_perm:  [3]byte{'7', '7', '5'},
+	}
+	err = folderInode.Serialize(path, neoInodeOffset)
+	if err != nil {
+		return err
+	}
+	folderBlock := &FolderBlock{
+		B_content: [4]FolderContent{
+			{B_name: [12]byte{'.'}, B_inodo: neoInodeIndex},
+			{B_name: [12]byte{'.', '.'}, B_inodo: folderIndex},
+			{B_name: [12]byte{'-'}, B_inodo: -1},
+			{B_name: [12]byte{'-'}, B_inodo: -1},
+		},
+	}
+	err = folderBlock.Serialize(path, blockOffset)
+	if err != nil {
+		return err
+	}
+	return sb.AddEntryToFolder(path, folderIndex, destDir, neoInodeIndex)
+}
 
-				err = sb.UpdateBitmapBlock(diskPath)
-				if err != nil {
-					return err
-				}
-				sb.S_blocks_count++
-				sb.S_free_blocks_count--
-				sb.S_first_blo += sb.S_block_size
-				inode.Serialize(diskPath, int64(sb.S_inode_start+(inodeIndex*sb.S_inode_size)))
-				return sb.CreateFile(diskPath, inodeIndex, parentsDir, destDir, fileContent, size, justSearchingAFile)
-			}
+// createFolderInInodeWithP crea las carpetas de parentsDir que falten y luego destDir, las que ya existen se recorren
+func (sb *SuperBlock) createFolderInInodeWithP(path string, inodeIndex int32, parentsDir []string, destDir string, userID, groupID int32) error {
+	names := append(append([]string{}, parentsDir...), destDir)
+	for _, nameDir := range names {
+		nameDir = strings.Trim(nameDir, "\x00 ")
+		if nameDir == "" {
+			continue
 		}
-
-		if i >= 14 {
-			pointerBlock := &PointerBlock{}
-			err := pointerBlock.Deserialize(diskPath, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
+		next, _, err := sb.lookupFrom(path, inodeIndex, []string{nameDir}, userID, groupID)
+		var notFound *NotFoundError
+		if errors.As(err, &notFound) {
+			err = sb.createFolderInInode(path, inodeIndex, nil, nameDir, false, userID, groupID)
 			if err != nil {
 				return err
 			}
-			for _, neoBlockIndex := range pointerBlock.P_pointers {
-				if neoBlockIndex == -1 {
-					continue
-				}
-				block := &FolderBlock{}
-				err := block.Deserialize(diskPath, int64(sb.S_block_start+(neoBlockIndex*sb.S_block_size)))
-				if err != nil {
-					return err
-				}
-				for indexContent := 2; indexContent < len(block.B_content); indexContent++ {
-					content := block.B_content[indexContent]
-					if len(parentsDir) != 0 {
-						if content.B_inodo == -1 {
-							continue
-						}
-						parentDir, err := utils.First(parentsDir)
-						if err != nil {
-							return err
-						}
-						contentName := strings.Trim(string(content.B_name[:]), "\x00")
-						parentDirName := strings.Trim(parentDir, "\x00")
-						if strings.EqualFold(contentName, parentDirName) {
-							err := sb.CreateFile(diskPath, content.B_inodo, utils.RemoveElement(parentsDir, 0), destDir, fileContent, size, justSearchingAFile)
-							if err != nil {
-								return err
-							}
-							return nil
-						}
-					} else {
-						contentName := strings.Trim(string(content.B_name[:]), "\x00")
-						destinationName := strings.Trim(destDir, "\x00")
-						if strings.EqualFold(contentName, destinationName) {
-							// En lugar de retornar error, sobrescribimos el archivo existente
-							err := sb.OverwriteFile(diskPath, content.B_inodo, fileContent)
-							if err != nil {
-								return err
-							}
-							return nil
-						}
-						if content.B_inodo != -1 {
-							tempContent := block.B_content[1]
-							inodoPadre = tempContent.B_inodo
-							continue
-						}
-						copy(content.B_name[:], destDir)
-						content.B_inodo = sb.S_inodes_count
-						block.B_content[indexContent] = content
-						err = block.Serialize(diskPath, int64(sb.S_block_start+(neoBlockIndex*sb.S_block_size)))
-						if err != nil {
-							return err
-						}
-						folderInode := &Inode{
-							I_uid:   utils.LogedUserID,
-							I_gid:   utils.LogedUserGroupID,
-							I_size:  int32(len(fileContent)),
-							I_atime: float32(time.Now().Unix()),
-							I_ctime: float32(time.Now().Unix()),
-							I_mtime: float32(time.Now().Unix()),
-							I_block: [15]int32{-1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
-							I_type:  [1]byte{'1'},
-							I_perm:  [3]byte{'6', '6', '4'},
-						}
-						contentChunks := utils.SplitStringIntoChunks(fileContent)
-						offsetInodo := sb.S_first_ino
-						err = folderInode.Serialize(diskPath, int64(offsetInodo))
-						if err != nil {
-							return err
-						}
-						err = sb.UpdateBitmapInode(diskPath)
-						if err != nil {
-							return err
-						}
-
-						sb.S_inodes_count++
-						sb.S_free_inodes_count--
-						sb.S_first_ino += sb.S_inode_size
-						// Flag de repetir el llenado
-						flagToCreateNeoBlockPointer := true
-						tempCont := 14
-						// Index del bloque pointer
-						var indexBlockPointer int32
-						tempBlockPointer := &PointerBlock{}
-						for i, content := range contentChunks {
-							if i >= 14 { //Aputnadores indirectos
-								if flagToCreateNeoBlockPointer {
-									for id, value := range folderInode.I_block {
-										if value == -1 {
-											folderInode.I_block[id] = sb.S_blocks_count
-											break
-										}
-									}
-									indexBlockPointer = folderInode.I_block[tempCont]
-									pointerBlock := &PointerBlock{
-										P_pointers: [16]int32{-1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
-									}
-									tempBlockPointer = pointerBlock
-									flagToCreateNeoBlockPointer = false
-									err = sb.UpdateBitmapBlock(diskPath)
-									if err != nil {
-										return err
-									}
-									sb.S_blocks_count++
-									sb.S_free_blocks_count--
-									sb.S_first_blo += sb.S_block_size
-								}
-								flag, err := stuffTheBlock(sb, tempBlockPointer, content, diskPath)
-								if err != nil {
-									return err
-								}
-								err = tempBlockPointer.Serialize(diskPath, int64(sb.S_block_start+(indexBlockPointer*sb.S_block_size)))
-								if err != nil {
-									return err
-								}
-								if !flag {
-									tempCont++
-									flagToCreateNeoBlockPointer = true
-									indexBlockPointer = folderInode.I_block[tempCont]
-								}
-							} else {
-								folderInode.I_block[i] = sb.S_blocks_count
-								contentBlock := &FileBlock{
-									B_content: [64]byte{},
-								}
-								copy(contentBlock.B_content[:], content)
-								err = contentBlock.Serialize(diskPath, int64(sb.S_first_blo))
-								if err != nil {
-									return err
-								}
-
-								err = sb.UpdateBitmapBlock(diskPath)
-								if err != nil {
-									return err
-								}
-								sb.S_blocks_count++
-								sb.S_free_blocks_count--
-								sb.S_first_blo += sb.S_block_size
-							}
-						}
-						err = folderInode.Serialize(diskPath, int64(offsetInodo))
-						if err != nil {
-							return err
-						}
-						return nil
-					}
-				}
-			}
-
+			next, _, err = sb.lookupFrom(path, inodeIndex, []string{nameDir}, userID, groupID)
 		}
-		block := &FolderBlock{}
-		err := block.Deserialize(diskPath, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
 		if err != nil {
 			return err
 		}
-		for indexContent := 2; indexContent < len(block.B_content); indexContent++ {
-			content := block.B_content[indexContent]
-			if len(parentsDir) != 0 {
-				if content.B_inodo == -1 {
-					continue
-				}
-				parentDir, err := utils.First(parentsDir)
-				if err != nil {
-					return err
-				}
-				contentName := strings.Trim(string(content.B_name[:]), "\x00")
-				parentDirName := strings.Trim(parentDir, "\x00")
-				if strings.EqualFold(contentName, parentDirName) {
-					err := sb.CreateFile(diskPath, content.B_inodo, utils.RemoveElement(parentsDir, 0), destDir, fileContent, size, justSearchingAFile)
-					if err != nil {
-						return err
-					}
-					return nil
-				}
-			} else {
-				contentName := strings.Trim(string(content.B_name[:]), "\x00")
-				destinationName := strings.Trim(destDir, "\x00")
-				if strings.EqualFold(contentName, destinationName) {
-					// En lugar de retornar error, sobrescribimos el archivo existente
-					err := sb.OverwriteFile(diskPath, content.B_inodo, fileContent)
-					if err != nil {
-						return err
-					}
-					return nil
-				}
-				outcome, err := inode.HasPermissionsToWrite(utils.LogedUserID, utils.LogedUserGroupID)
-				if err != nil {
-					return err
-				}
-				if !outcome {
-					return errors.New("inaccesible por falta de permisos")
-				}
-				if content.B_inodo != -1 {
-					tempContent := block.B_content[1]
-					inodoPadre = tempContent.B_inodo
-					continue
-				}
-				copy(content.B_name[:], destDir)
-				content.B_inodo = sb.S_inodes_count
-				block.B_content[indexContent] = content
-				err = block.Serialize(diskPath, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
-				if err != nil {
-					return err
-				}
-				folderInode := &Inode{
-					I_uid:   utils.LogedUserID,
-					I_gid:   utils.LogedUserGroupID,
-					I_size:  int32(len(fileContent)),
-					I_atime: float32(time.Now().Unix()),
-					I_ctime: float32(time.Now().Unix()),
-					I_mtime: float32(time.Now().Unix()),
-					I_block: [15]int32{-1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
-					I_type:  [1]byte{'1'},
-					I_perm:  [3]byte{'6', '6', '4'},
-				}
-				contentChunks := utils.SplitStringIntoChunks(fileContent)
-				offsetInodo := sb.S_first_ino
-				err = folderInode.Serialize(diskPath, int64(offsetInodo))
-				if err != nil {
-					return err
-				}
-				err = sb.UpdateBitmapInode(diskPath)
-				if err != nil {
-					return err
-				}
-
-				sb.S_inodes_count++
-				sb.S_free_inodes_count--
-				sb.S_first_ino += sb.S_inode_size
-				// Flag de repetir el llenado
-				flagToCreateNeoBlockPointer := true
-				tempCont := 14
-				// Index del bloque pointer
-				var indexBlockPointer int32
-				tempBlockPointer := &PointerBlock{}
-				for i, content := range contentChunks {
-					if i >= 14 { //Aputnadores indirectos
-						if flagToCreateNeoBlockPointer {
-							for id, value := range folderInode.I_block {
-								if value == -1 {
-									folderInode.I_block[id] = sb.S_blocks_count
-									break
-								}
-							}
-							indexBlockPointer = folderInode.I_block[tempCont]
-							pointerBlock := &PointerBlock{
-								P_pointers: [16]int32{-1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
-							}
-							tempBlockPointer = pointerBlock
-							flagToCreateNeoBlockPointer = false
-							err = sb.UpdateBitmapBlock(diskPath)
-							if err != nil {
-								return err
-							}
-							sb.S_blocks_count++
-							sb.S_free_blocks_count--
-							sb.S_first_blo += sb.S_block_size
-						}
-						flag, err := stuffTheBlock(sb, tempBlockPointer, content, diskPath)
-						if err != nil {
-							return err
-						}
-						err = tempBlockPointer.Serialize(diskPath, int64(sb.S_block_start+(indexBlockPointer*sb.S_block_size)))
-						if err != nil {
-							return err
-						}
-						if !flag {
-							tempCont++
-							flagToCreateNeoBlockPointer = true
-							indexBlockPointer = folderInode.I_block[tempCont]
-						}
-					} else {
-						folderInode.I_block[i] = sb.S_blocks_count
-						contentBlock := &FileBlock{
-							B_content: [64]byte{},
-						}
-						copy(contentBlock.B_content[:], content)
-						err = contentBlock.Serialize(diskPath, int64(sb.S_first_blo))
-						if err != nil {
-							return err
-						}
-
-						err = sb.UpdateBitmapBlock(diskPath)
-						if err != nil {
-							return err
-						}
-						sb.S_blocks_count++
-						sb.S_free_blocks_count--
-						sb.S_first_blo += sb.S_block_size
-					}
-				}
-				err = folderInode.Serialize(diskPath, int64(offsetInodo))
-				if err != nil {
-					return err
-				}
-				return nil
-			}
-		}
+		inodeIndex = next
+	}
+	_, folder, err := sb.lookupFrom(path, inodeIndex, nil, userID, groupID)
+	if err != nil {
+		return err
+	}
+	if folder.I_type[0] != '0' {
+		return &NotFoundError{Path: "/" + strings.Join(names, "/")}
 	}
 	return nil
 }
 
-func stuffTheBlock(sb *SuperBlock, pointerBlock *PointerBlock, content string, diskPath string) (bool, error) {
-	for i, indexInode := range pointerBlock.P_pointers {
-
-		if indexInode != -1 {
-			continue
-		}
-		pointerBlock.P_pointers[i] = sb.S_blocks_count
-		contentBlock := &FileBlock{
-			B_content: [64]byte{},
-		}
-		copy(contentBlock.B_content[:], content)
-		err := contentBlock.Serialize(diskPath, int64(sb.S_first_blo))
-		if err != nil {
-			return false, err
-		}
-
-		err = sb.UpdateBitmapBlock(diskPath)
-		if err != nil {
-			return false, err
-		}
-		sb.S_blocks_count++
-		sb.S_free_blocks_count--
-		sb.S_first_blo += sb.S_block_size
-		if i == len(pointerBlock.P_pointers)-1 {
-			return false, nil
-		} else {
-			return true, nil
-		}
+func (sb *SuperBlock) CreateFile(diskPath string, inodeIndex int32, parentsDir []string, destDir string, fileContent string, userID, groupID int32) error {
+	folderIndex, folder, err := sb.findParentFolder(diskPath, inodeIndex, parentsDir, userID, groupID)
+	if err != nil {
+		return err
 	}
-	return false, errors.New("no entro al forr de llenado por apuntadores indirectos")
-}
-
-func (sb *SuperBlock) ContentFromFile(diskPath string, inodeIndex int32, parentsDir []string, destDir string) (string, error) {
-	inode := &Inode{}
-	err := inode.Deserialize(diskPath, int64(sb.S_inode_start+(inodeIndex*sb.S_inode_size)))
+	destDir = strings.Trim(destDir, "\x00 ")
+	existing, err := sb.FindEntryInFolder(diskPath, folder, destDir)
 	if err != nil {
-		return "", err
+		return err
 	}
-	if inode.I_type[0] == '1' {
-		return "", errors.New("se entro a un Inodo tipo file en reportFile")
+	if existing != -1 {
+		// En lugar de retornar error, sobrescribimos el archivo existente
+		return sb.OverwriteFile(diskPath, existing, fileContent, userID, groupID)
 	}
-	for _, blockIndex := range inode.I_block {
-
-		if blockIndex == -1 {
-			return "", errors.New("error en el path solicitado para extraer informacion de un archivo")
-		}
-		block := &FolderBlock{}
-		err := block.Deserialize(diskPath, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
-		if err != nil {
-			return "", err
-		}
-		for indexContent := 2; indexContent < len(block.B_content); indexContent++ {
-			content := block.B_content[indexContent]
-			if len(parentsDir) != 0 {
-				if content.B_inodo == -1 {
-					continue
-				}
-				parentDir, err := utils.First(parentsDir)
-				if err != nil {
-					return "", err
-				}
-				contentName := strings.Trim(string(content.B_name[:]), "\x00")
-				parentDirName := strings.Trim(parentDir, "\x00")
-				if strings.EqualFold(contentName, parentDirName) {
-					content, err := sb.ContentFromFile(diskPath, content.B_inodo, utils.RemoveElement(parentsDir, 0), destDir)
-					if err != nil {
-						return "", err
-					}
-					return content, nil
-				}
-			} else {
-				contentName := strings.Trim(string(content.B_name[:]), "\x00")
-				destinationName := strings.Trim(destDir, "\x00")
-				if strings.EqualFold(contentName, destinationName) {
-					// Son iguales
-					inodoFile := &Inode{}
-					inodoFile.Deserialize(diskPath, int64(sb.S_inode_start+(content.B_inodo*sb.S_inode_size)))
-					var content string
-					for iTe, value := range inodoFile.I_block {
-						if value == -1 {
-							continue
-						}
-						if iTe >= 14 {
-							pointerBlock := &PointerBlock{}
-							err := pointerBlock.Deserialize(diskPath, int64(sb.S_block_start+(sb.S_block_size*value)))
-							if err != nil {
-								return "", err
-							}
-							for _, indexContentBlock := range pointerBlock.P_pointers {
-								if indexContentBlock == -1 {
-									continue
-								}
-								blockContentFile := &FileBlock{}
-								blockContentFile.Deserialize(diskPath, int64(sb.S_block_start+(sb.S_block_size*indexContentBlock)))
-								contentBlock := string(blockContentFile.B_content[:])
-								contentBlock = strings.TrimRight(contentBlock, "\x00")
-								content += contentBlock
-							}
-						} else {
-							blockContentFile := &FileBlock{}
-							blockContentFile.Deserialize(diskPath, int64(sb.S_block_start+(sb.S_block_size*value)))
-							contentBlock := string(blockContentFile.B_content[:])
-							contentBlock = strings.TrimRight(contentBlock, "\x00")
-							content += contentBlock
-						}
-					}
-					return content, nil
-				}
-				if content.B_inodo != -1 {
-					continue
-				}
-			}
-		}
+	outcome, err := folder.HasPermissionsToWrite(userID, groupID)
+	if err != nil {
+		return err
 	}
-	return "", errors.New("se ha producido un error en reportFile")
-}
-
-func (sb *SuperBlock) ContentFromFileCat(diskPath string, inodeIndex int32, parentsDir []string, destDir string) (string, error) {
-	inode := &Inode{}
-	err := inode.Deserialize(diskPath, int64(sb.S_inode_start+(inodeIndex*sb.S_inode_size)))
+	if !outcome {
+		return NewError(ErrPermission, "inaccesible por falta de permisos")
+	}
+	neededBlocks, err := FileBlocksNeeded(fileContent)
 	if err != nil {
-		return "", err
+		return err
 	}
-	if inode.I_type[0] == '1' {
-		return "", errors.New("se entro a un Inodo tipo file en reportFile")
+	err = sb.CheckSpaceForEntry(diskPath, folderIndex, destDir, 1, neededBlocks)
+	if err != nil {
+		return err
 	}
-	// outcome, err := inode.HasPermissionsToRead(utils.LogedUserID, utils.LogedUserGroupID)
-	// if err != nil {
-	// 	return "", err
-	// }
-	// if !outcome {
-	// 	return "", errors.New("inaccesible por falta de permisos")
-	// }
-	for _, blockIndex := range inode.I_block {
 
-		if blockIndex == -1 {
-			return "", errors.New("error en el path solicitado para extraer informacion de un archivo")
-		}
-		block := &FolderBlock{}
-		err := block.Deserialize(diskPath, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
-		if err != nil {
-			return "", err
-		}
-		for indexContent := 2; indexContent < len(block.B_content); indexContent++ {
-			content := block.B_content[indexContent]
-			if len(parentsDir) != 0 {
-				if content.B_inodo == -1 {
-					continue
-				}
-				parentDir, err := utils.First(parentsDir)
-				if err != nil {
-					return "", err
-				}
-				contentName := strings.Trim(string(content.B_name[:]), "\x00")
-				parentDirName := strings.Trim(parentDir, "\x00")
-				if strings.EqualFold(contentName, parentDirName) {
-					content, err := sb.ContentFromFileCat(diskPath, content.B_inodo, utils.RemoveElement(parentsDir, 0), destDir)
-					if err != nil {
-						return "", err
-					}
-					return content, nil
-				}
-			} else {
-				contentName := strings.Trim(string(content.B_name[:]), "\x00")
-				destinationName := strings.Trim(destDir, "\x00")
-				if strings.EqualFold(contentName, destinationName) {
-					// Son iguales
-					inodoFile := &Inode{}
-					inodoFile.Deserialize(diskPath, int64(sb.S_inode_start+(content.B_inodo*sb.S_inode_size)))
-					outcome, err := inodoFile.HasPermissionsToRead(utils.LogedUserID, utils.LogedUserGroupID)
-					if err != nil {
-						return "", err
-					}
-					if !outcome {
-						return "inaccesible por falta de permisos", nil
-					}
-					var content string
-					for iTe, value := range inodoFile.I_block {
-						if value == -1 {
-							continue
-						}
-						if iTe >= 14 {
-							pointerBlock := &PointerBlock{}
-							err := pointerBlock.Deserialize(diskPath, int64(sb.S_block_start+(sb.S_block_size*value)))
-							if err != nil {
-								return "", err
-							}
-							for _, indexContentBlock := range pointerBlock.P_pointers {
-								if indexContentBlock == -1 {
-									continue
-								}
-								blockContentFile := &FileBlock{}
-								blockContentFile.Deserialize(diskPath, int64(sb.S_block_start+(sb.S_block_size*indexContentBlock)))
-								contentBlock := string(blockContentFile.B_content[:])
-								contentBlock = strings.TrimRight(contentBlock, "\x00")
-								content += contentBlock
-							}
-						} else {
-							blockContentFile := &FileBlock{}
-							blockContentFile.Deserialize(diskPath, int64(sb.S_block_start+(sb.S_block_size*value)))
-							contentBlock := string(blockContentFile.B_content[:])
-							contentBlock = strings.TrimRight(contentBlock, "\x00")
-							content += contentBlock
-						}
-					}
-					return content, nil
-				}
-				if content.B_inodo != -1 {
-					continue
-				}
-			}
-		}
+	fileInodeIndex, fileInodeOffset, err := sb.reserveInode(diskPath)
+	if err != nil {
+		return err
+	}
+	fileInode := &Inode{
+		I_uid:   userID,
+		I_gid:   groupID,
+		I_size:  int32(len(fileContent)),
+		I_atime: float32(time.Now().Unix()),
+		I_ctime: float32(time.Now().Unix()),
+		I_mtime: float32(time.Now().Unix()),
+		I_block: [15]int32{-1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
+		I_type:  [1]byte{'1'},
+		I_perm:  [3]byte{'6', '6', '4'},
+	}
+	err = sb.writeFileContent(diskPath, fileInode, 0, fileContent)
+	if err != nil {
+		return err
 	}
-	return "", errors.New("se ha producido un error en reportFile")
+	err = fileInode.Serialize(diskPath, fileInodeOffset)
+	if err != nil {
+		return err
+	}
+	return sb.AddEntryToFolder(diskPath, folderIndex, destDir, fileInodeIndex)
 }
 
-func (sb *SuperBlock) folderFromAuntadorIndirecto13(diskPath string, inodeIndex int32, parentsDir []string, destDir string, justSearchingAFile bool, numApuntadorIndirecto int32, inodoPadre int32) (bool, error) {
-	pointerBlock := &PointerBlock{}
-	err := pointerBlock.Deserialize(diskPath, int64(sb.S_block_start+(sb.S_block_size*numApuntadorIndirecto)))
+// findFileInode devuelve el inodo archivo destDir dentro de la ruta parentsDir
+func (sb *SuperBlock) findFileInode(diskPath string, inodeIndex int32, parentsDir []string, destDir string, userID, groupID int32) (*Inode, error) {
+	_, fileInode, err := sb.lookupFrom(diskPath, inodeIndex, append(append([]string{}, parentsDir...), destDir), userID, groupID)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
+	if fileInode.I_type[0] != '1' {
+		return nil, NewError(ErrInvalid, "la ruta indicada no es un archivo")
+	}
+	return fileInode, nil
+}
 
-	for i, blockIndex := range pointerBlock.P_pointers {
-		if blockIndex == -1 {
-			// Aqui se debe validar si es la iteracion 13 en adelante para hacer lo de los apuntadores indirectos
-			if !justSearchingAFile && len(parentsDir) != 0 {
-				return false, errors.New("ruta invalida, asegurese que exita la ruta antes")
-			}
-
-			pointerBlock.P_pointers[i] = sb.S_blocks_count
-			folderBlock := &FolderBlock{
-				B_content: [4]FolderContent{
-					{B_name: [12]byte{'.'}, B_inodo: inodeIndex},
-					{B_name: [12]byte{'.', '.'}, B_inodo: inodoPadre},
-					{B_name: [12]byte{'-'}, B_inodo: -1},
-					{B_name: [12]byte{'-'}, B_inodo: -1},
-				},
-			}
-			err = folderBlock.Serialize(diskPath, int64(sb.S_first_blo))
-			if err != nil {
-				return false, err
-			}
-
-			err = sb.UpdateBitmapBlock(diskPath)
-			if err != nil {
-				return false, err
-			}
-			sb.S_blocks_count++
-			sb.S_free_blocks_count--
-			sb.S_first_blo += sb.S_block_size
-
-			err = pointerBlock.Serialize(diskPath, int64(sb.S_block_start+(sb.S_block_size*numApuntadorIndirecto)))
-			if err != nil {
-				return false, err
-			}
-
-			return sb.folderFromAuntadorIndirecto13(diskPath, inodeIndex, parentsDir, destDir, justSearchingAFile, numApuntadorIndirecto, inodoPadre)
-		}
-
-		// Si es la iteracion 13,
-		block := &FolderBlock{}
-		err := block.Deserialize(diskPath, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
-		if err != nil {
-			return false, err
-		}
-
-		for indexContent := 2; indexContent < len(block.B_content); indexContent++ {
-			content := block.B_content[indexContent]
-
-			if len(parentsDir) != 0 {
-
-				if content.B_inodo == -1 {
-					continue
-				}
-
-				parentDir, err := utils.First(parentsDir)
-				if err != nil {
-					return false, err
-				}
-
-				contentName := strings.Trim(string(content.B_name[:]), "\x00 ")
-				parentDirName := strings.Trim(parentDir, "\x00 ")
-				if strings.EqualFold(contentName, parentDirName) {
-					err := sb.createFolderInInode(diskPath, content.B_inodo, utils.RemoveElement(parentsDir, 0), destDir, justSearchingAFile)
-					if err != nil {
-						return false, err
-					}
-					return true, nil
-				}
-			} else {
-
-				contentName := strings.Trim(string(content.B_name[:]), "\x00")
-				destinationName := strings.Trim(destDir, "\x00")
-				if strings.EqualFold(contentName, destinationName) {
-					return false, errors.New("ya existe un directorio con el mismo nombre")
-				}
-
-				if content.B_inodo != -1 {
-					continue
-				}
-
-				copy(content.B_name[:], destDir)
-				content.B_inodo = sb.S_inodes_count
-
-				block.B_content[indexContent] = content
-
-				err = block.Serialize(diskPath, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
-				if err != nil {
-					return false, err
-				}
-
-				folderInode := &Inode{
-					I_uid:   utils.LogedUserID,
-					I_gid:   utils.LogedUserGroupID,
-					I_size:  0,
-					I_atime: float32(time.Now().Unix()),
-					I_ctime: float32(time.Now().Unix()),
-					I_mtime: float32(time.Now().Unix()),
-					I_block: [15]int32{sb.S_blocks_count, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
-					I_type:  [1]byte{'0'},
-					I_perm:  [3]byte{'6', '6', '4'},
-				}
-
-				err = folderInode.Serialize(diskPath, int64(sb.S_first_ino))
-				if err != nil {
-					return false, err
-				}
-
-				err = sb.UpdateBitmapInode(diskPath)
-				if err != nil {
-					return false, err
-				}
-
-				sb.S_inodes_count++
-				sb.S_free_inodes_count--
-				sb.S_first_ino += sb.S_inode_size
-
-				folderBlock := &FolderBlock{
-					B_content: [4]FolderContent{
-						{B_name: [12]byte{'.'}, B_inodo: content.B_inodo},
-						{B_name: [12]byte{'.', '.'}, B_inodo: inodeIndex},
-						{B_name: [12]byte{'-'}, B_inodo: -1},
-						{B_name: [12]byte{'-'}, B_inodo: -1},
-					},
-				}
-
-				err = folderBlock.Serialize(diskPath, int64(sb.S_first_blo))
-				if err != nil {
-					return false, err
-				}
-
-				err = sb.UpdateBitmapBlock(diskPath)
-				if err != nil {
-					return false, err
-				}
-				sb.S_blocks_count++
-				sb.S_free_blocks_count--
-				sb.S_first_blo += sb.S_block_size
-
-				err = pointerBlock.Serialize(diskPath, int64(sb.S_inode_start+(numApuntadorIndirecto*sb.S_inode_size)))
-				if err != nil {
-					return false, err
-				}
-				return true, nil
-			}
-		}
+// ContentFromFile lee el archivo sin validar permisos, para lo que el sistema lee por su cuenta como users.txt
+func (sb *SuperBlock) ContentFromFile(diskPath string, inodeIndex int32, parentsDir []string, destDir string) (string, error) {
+	fileInode, err := sb.findFileInode(diskPath, inodeIndex, parentsDir, destDir, 1, 1)
+	if err != nil {
+		return "", err
 	}
-	return false, nil
+	return sb.ReadFileContent(diskPath, fileInode)
 }
 
-// Función para liberar un bloque en el bitmap
-func (sb *SuperBlock) FreeBitmapBlock(diskPath string, blockIndex int32) error {
-	file, err := os.OpenFile(diskPath, os.O_WRONLY, 0644)
+// ContentFromFileCat lee el archivo validando el permiso de lectura del usuario, lo usan cat, rep -name=file y la API
+func (sb *SuperBlock) ContentFromFileCat(diskPath string, inodeIndex int32, parentsDir []string, destDir string, userID, groupID int32) (string, error) {
+	fileInode, err := sb.findFileInode(diskPath, inodeIndex, parentsDir, destDir, userID, groupID)
 	if err != nil {
-		return err
+		return "", err
+	}
+	outcome, err := fileInode.HasPermissionsToRead(userID, groupID)
+	if err != nil {
+		return "", err
+	}
+	if !outcome {
+		return "", Errorf(ErrPermission, "no tiene permisos de lectura sobre el archivo %s", destDir)
 	}
-	defer file.Close()
+	return sb.ReadFileContent(diskPath, fileInode)
+}
 
+// Función para liberar un bloque en el bitmap, si ya estaba libre no cambia el contador
+func (sb *SuperBlock) FreeBitmapBlock(diskPath string, blockIndex int32) error {
 	// Verificar que el índice del bloque sea válido
-	if blockIndex < 0 {
-		return errors.New("índice de bloque inválido")
+	if blockIndex < 0 || blockIndex >= sb.TotalBlocks() {
+		return NewError(ErrCorrupt, "índice de bloque inválido")
 	}
 
-	// Calcular la posición en el bitmap de bloques
-	bitmapPosition := sb.S_bm_block_start + blockIndex
-
-	_, err = file.Seek(int64(bitmapPosition), 0)
+	// Escribir 'O' para marcar como libre
+	previous, err := setBitmap(diskPath, sb.S_bm_block_start+blockIndex, 'O')
 	if err != nil {
 		return err
 	}
-
-	// Escribir '0' para marcar como libre
-	_, err = file.Write([]byte{'0'})
-	if err != nil {
-		return err
+	if previous == 'X' {
+		sb.S_free_blocks_count++
 	}
-
-	// Actualizar contadores solo si es necesario
-	sb.S_free_blocks_count++
-
 	return nil
 }
 
-// Función auxiliar para limpiar los bloques de un archivo
+// Función auxiliar para limpiar los bloques de un archivo, de datos y de apuntadores
 func (sb *SuperBlock) clearFileBlocks(diskPath string, fileInode *Inode) error {
-	for i, blockIndex := range fileInode.I_block {
-		if blockIndex == -1 {
-			continue
-		}
-
-		if i >= 14 {
-			// Manejar bloques indirectos
-			pointerBlock := &PointerBlock{}
-			err := pointerBlock.Deserialize(diskPath, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
-			if err != nil {
-				continue // Continuar en caso de error en lugar de fallar
-			}
-
-			for _, ptrIndex := range pointerBlock.P_pointers {
-				if ptrIndex == -1 {
-					continue
-				}
-				// Marcar el bloque como libre en el bitmap
-				err = sb.FreeBitmapBlock(diskPath, ptrIndex)
-				if err != nil {
-					continue // Continuar en caso de error
-				}
-			}
-
-			// Marcar el bloque pointer como libre
-			err = sb.FreeBitmapBlock(diskPath, blockIndex)
-			if err != nil {
-				continue // Continuar en caso de error
-			}
-		} else {
-			// Marcar el bloque directo como libre
-			err := sb.FreeBitmapBlock(diskPath, blockIndex)
-			if err != nil {
-				continue // Continuar en caso de error
-			}
+	data, pointers, err := sb.InodeBlocks(diskPath, fileInode)
+	if err != nil {
+		return err
+	}
+	for _, blockIndex := range append(data, pointers...) {
+		err := sb.FreeBitmapBlock(diskPath, blockIndex)
+		if err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
 // Nueva función para sobrescribir archivos existentes
-func (sb *SuperBlock) OverwriteFile(diskPath string, fileInodeIndex int32, newContent string) error {
+func (sb *SuperBlock) OverwriteFile(diskPath string, fileInodeIndex int32, newContent string, userID, groupID int32) error {
 	// Verificar que el índice del inodo sea válido
 	if fileInodeIndex < 0 || fileInodeIndex >= sb.S_inodes_count {
-		return errors.New("índice de inodo inválido para sobrescribir")
+		return NewError(ErrCorrupt, "índice de inodo inválido para sobrescribir")
 	}
 
 	fileInode := &Inode{}
@@ -1165,23 +256,31 @@ func (sb *SuperBlock) OverwriteFile(diskPath string, fileInodeIndex int32, newCo
 
 	// Verificar que sea un archivo (tipo '1')
 	if fileInode.I_type[0] != '1' {
-		return errors.New("el inodo no es un archivo")
+		return NewError(ErrInvalid, "el inodo no es un archivo")
 	}
 
 	// Verificar permisos de escritura
-	outcome, err := fileInode.HasPermissionsToWrite(utils.LogedUserID, utils.LogedUserGroupID)
+	outcome, err := fileInode.HasPermissionsToWrite(userID, groupID)
 	if err != nil {
 		return err
 	}
 	if !outcome {
-		return errors.New("inaccesible por falta de permisos")
+		return NewError(ErrPermission, "inaccesible por falta de permisos")
+	}
+
+	// Validar que el contenido quepa antes de liberar los bloques actuales
+	neededBlocks, err := FileBlocksNeeded(newContent)
+	if err != nil {
+		return err
+	}
+	if !sb.HasSpaceFor(0, neededBlocks) {
+		return NewError(ErrNoSpace, "no hay bloques disponibles en la particion")
 	}
 
 	// Limpiar bloques existentes del archivo
 	err = sb.clearFileBlocks(diskPath, fileInode)
 	if err != nil {
-		// No fallar por errores de limpieza, solo registrar
-		fmt.Printf("Advertencia: error al limpiar bloques del archivo: %v\n", err)
+		return err
 	}
 
 	// Actualizar el inodo con el nuevo contenido
@@ -1195,71 +294,9 @@ func (sb *SuperBlock) OverwriteFile(diskPath string, fileInodeIndex int32, newCo
 	}
 
 	// Escribir el nuevo contenido
-	if len(newContent) > 0 {
-		contentChunks := utils.SplitStringIntoChunks(newContent)
-
-		// Flag de repetir el llenado
-		flagToCreateNeoBlockPointer := true
-		tempCont := 14
-		// Index del bloque pointer
-		var indexBlockPointer int32
-		tempBlockPointer := &PointerBlock{}
-		for i, content := range contentChunks {
-			if i >= 14 { //Apuntadores indirectos
-				if flagToCreateNeoBlockPointer {
-					for id, value := range fileInode.I_block {
-						if value == -1 {
-							fileInode.I_block[id] = sb.S_blocks_count
-							break
-						}
-					}
-					indexBlockPointer = fileInode.I_block[tempCont]
-					pointerBlock := &PointerBlock{
-						P_pointers: [16]int32{-1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
-					}
-					tempBlockPointer = pointerBlock
-					flagToCreateNeoBlockPointer = false
-					err = sb.UpdateBitmapBlock(diskPath)
-					if err != nil {
-						return err
-					}
-					sb.S_blocks_count++
-					sb.S_free_blocks_count--
-					sb.S_first_blo += sb.S_block_size
-				}
-				flag, err := stuffTheBlock(sb, tempBlockPointer, content, diskPath)
-				if err != nil {
-					return err
-				}
-				err = tempBlockPointer.Serialize(diskPath, int64(sb.S_block_start+(indexBlockPointer*sb.S_block_size)))
-				if err != nil {
-					return err
-				}
-				if !flag {
-					tempCont++
-					flagToCreateNeoBlockPointer = true
-					indexBlockPointer = fileInode.I_block[tempCont]
-				}
-			} else {
-				fileInode.I_block[i] = sb.S_blocks_count
-				contentBlock := &FileBlock{
-					B_content: [64]byte{},
-				}
-				copy(contentBlock.B_content[:], content)
-				err = contentBlock.Serialize(diskPath, int64(sb.S_first_blo))
-				if err != nil {
-					return err
-				}
-
-				err = sb.UpdateBitmapBlock(diskPath)
-				if err != nil {
-					return err
-				}
-				sb.S_blocks_count++
-				sb.S_free_blocks_count--
-				sb.S_first_blo += sb.S_block_size
-			}
-		}
+	err = sb.writeFileContent(diskPath, fileInode, 0, newContent)
+	if err != nil {
+		return err
 	}
 
 	// Guardar el inodo actualizado
@@ -0,0 +1,341 @@
+package structures
+
+import (
+	"strings"
+)
+
+// TotalInodes devuelve la cantidad de inodos que caben en la particion (n)
+func (sb *SuperBlock) TotalInodes() int32 {
+	return sb.S_bm_block_start - sb.S_bm_inode_start
+}
+
+// TotalBlocks devuelve la cantidad de bloques que caben en la particion (3n)
+func (sb *SuperBlock) TotalBlocks() int32 {
+	return sb.S_inode_start - sb.S_bm_block_start
+}
+
+// HasSpaceFor indica si aun se pueden asignar la cantidad de inodos y bloques indicada
+func (sb *SuperBlock) HasSpaceFor(inodes, blocks int32) bool {
+	return inodes <= sb.S_free_inodes_count && blocks <= sb.S_free_blocks_count
+}
+
+// reserveBlock marca como usado el primer bloque libre del bitmap y devuelve su indice y su offset,
+// asi se reutilizan los bloques que libera remove. S_blocks_count queda como el mayor indice usado mas uno.
+func (sb *SuperBlock) reserveBlock(diskPath string) (int32, int64, error) {
+	index := int32(-1)
+	var err error
+	if sb.HasSpaceFor(0, 1) {
+		index, err = firstFree(diskPath, sb.S_bm_block_start, sb.TotalBlocks(), 'X')
+		if err != nil {
+			return -1, 0, err
+		}
+	}
+	if index == -1 {
+		return -1, 0, NewError(ErrNoSpace, "no hay bloques disponibles en la particion")
+	}
+	_, err = setBitmap(diskPath, sb.S_bm_block_start+index, 'X')
+	if err != nil {
+		return -1, 0, err
+	}
+	sb.S_free_blocks_count--
+	if index >= sb.S_blocks_count {
+		sb.S_blocks_count = index + 1
+		sb.S_first_blo = sb.S_block_start + sb.S_blocks_count*sb.S_block_size
+	}
+	return index, int64(sb.S_block_start + index*sb.S_block_size), nil
+}
+
+// reserveInode marca como usado el primer inodo libre del bitmap y devuelve su indice y su offset,
+// asi se reutilizan los inodos que libera remove. S_inodes_count queda como el mayor indice usado mas uno.
+func (sb *SuperBlock) reserveInode(diskPath string) (int32, int64, error) {
+	index := int32(-1)
+	var err error
+	if sb.HasSpaceFor(1, 0) {
+		index, err = firstFree(diskPath, sb.S_bm_inode_start, sb.TotalInodes(), '1')
+		if err != nil {
+			return -1, 0, err
+		}
+	}
+	if index == -1 {
+		return -1, 0, NewError(ErrNoSpace, "no hay inodos disponibles en la particion")
+	}
+	_, err = setBitmap(diskPath, sb.S_bm_inode_start+index, '1')
+	if err != nil {
+		return -1, 0, err
+	}
+	sb.S_free_inodes_count--
+	if index >= sb.S_inodes_count {
+		sb.S_inodes_count = index + 1
+		sb.S_first_ino = sb.S_inode_start + sb.S_inodes_count*sb.S_inode_size
+	}
+	return index, int64(sb.S_inode_start + index*sb.S_inode_size), nil
+}
+
+// FolderBlockIndexes devuelve los bloques carpeta de un inodo en orden, incluidos los de los apuntadores indirectos
+func (sb *SuperBlock) FolderBlockIndexes(diskPath string, inode *Inode) ([]int32, error) {
+	indexes, _, err := sb.InodeBlocks(diskPath, inode)
+	return indexes, err
+}
+
+// FindEntryInFolder devuelve el inodo enlazado con el nombre indicado dentro de la carpeta, -1 si no existe
+func (sb *SuperBlock) FindEntryInFolder(diskPath string, folder *Inode, name string) (int32, error) {
+	entries, err := sb.FolderEntries(diskPath, folder)
+	if err != nil {
+		return -1, err
+	}
+	for _, content := range entries {
+		if strings.EqualFold(strings.Trim(string(content.B_name[:]), "\x00 "), name) {
+			return content.B_inodo, nil
+		}
+	}
+	return -1, nil
+}
+
+// FolderEntries devuelve las entradas ocupadas de la carpeta, sin . y ..
+func (sb *SuperBlock) FolderEntries(diskPath string, folder *Inode) ([]FolderContent, error) {
+	indexes, err := sb.FolderBlockIndexes(diskPath, folder)
+	if err != nil {
+		return nil, err
+	}
+	var entries []FolderContent
+	for _, blockIndex := range indexes {
+		block := &FolderBlock{}
+		err := block.Deserialize(diskPath, int64(sb.S_block_start+sb.S_block_size*blockIndex))
+		if err != nil {
+			return nil, err
+		}
+		for indexContent := 2; indexContent < len(block.B_content); indexContent++ {
+			if block.B_content[indexContent].B_inodo != -1 {
+				entries = append(entries, block.B_content[indexContent])
+			}
+		}
+	}
+	return entries, nil
+}
+
+// blocksNeededForEntry devuelve cuantos bloques nuevos hacen falta para agregar una entrada a la carpeta.
+// Devuelve error si ya existe una entrada con ese nombre o si la carpeta no admite mas bloques.
+func (sb *SuperBlock) blocksNeededForEntry(diskPath string, folder *Inode, name string) (int32, error) {
+	indexes, err := sb.FolderBlockIndexes(diskPath, folder)
+	if err != nil {
+		return 0, err
+	}
+	var needed int32 = -1
+	for _, blockIndex := range indexes {
+		block := &FolderBlock{}
+		err := block.Deserialize(diskPath, int64(sb.S_block_start+sb.S_block_size*blockIndex))
+		if err != nil {
+			return 0, err
+		}
+		for indexContent := 2; indexContent < len(block.B_content); indexContent++ {
+			content := block.B_content[indexContent]
+			if content.B_inodo == -1 {
+				needed = 0
+				continue
+			}
+			if strings.EqualFold(strings.Trim(string(content.B_name[:]), "\x00 "), name) {
+				return 0, Errorf(ErrExists, "ya existe %s en el directorio destino", name)
+			}
+		}
+	}
+	if needed == 0 {
+		return 0, nil
+	}
+	if len(indexes)+1 > MaxInodeBlocks() {
+		return 0, NewError(ErrNoSpace, "el directorio destino esta lleno")
+	}
+	// Un bloque carpeta nuevo y los bloques de apuntadores que haga falta crear para enlazarlo
+	return 1 + PointerBlocksNeeded(len(indexes)+1) - PointerBlocksNeeded(len(indexes)), nil
+}
+
+// CheckSpaceForEntry valida que se pueda agregar la entrada name a la carpeta junto con los inodos y bloques extra indicados
+func (sb *SuperBlock) CheckSpaceForEntry(diskPath string, folderIndex int32, name string, inodes, blocks int32) error {
+	folder := &Inode{}
+	err := folder.Deserialize(diskPath, int64(sb.S_inode_start+sb.S_inode_size*folderIndex))
+	if err != nil {
+		return err
+	}
+	if folder.I_type[0] != '0' {
+		return NewError(ErrInvalid, "el destino no es un directorio")
+	}
+	needed, err := sb.blocksNeededForEntry(diskPath, folder, name)
+	if err != nil {
+		return err
+	}
+	if !sb.HasSpaceFor(inodes, blocks+needed) {
+		return NewError(ErrNoSpace, "no hay suficientes inodos o bloques libres en la particion")
+	}
+	return nil
+}
+
+// AddEntryToFolder enlaza el inodo childIndex dentro de la carpeta folderIndex con el nombre indicado
+func (sb *SuperBlock) AddEntryToFolder(diskPath string, folderIndex int32, name string, childIndex int32) error {
+	folder := &Inode{}
+	err := folder.Deserialize(diskPath, int64(sb.S_inode_start+sb.S_inode_size*folderIndex))
+	if err != nil {
+		return err
+	}
+	needed, err := sb.blocksNeededForEntry(diskPath, folder, name)
+	if err != nil {
+		return err
+	}
+	if !sb.HasSpaceFor(0, needed) {
+		return NewError(ErrNoSpace, "no hay bloques disponibles en la particion")
+	}
+
+	if needed == 0 {
+		indexes, err := sb.FolderBlockIndexes(diskPath, folder)
+		if err != nil {
+			return err
+		}
+		for _, blockIndex := range indexes {
+			block := &FolderBlock{}
+			err := block.Deserialize(diskPath, int64(sb.S_block_start+sb.S_block_size*blockIndex))
+			if err != nil {
+				return err
+			}
+			for indexContent := 2; indexContent < len(block.B_content); indexContent++ {
+				if block.B_content[indexContent].B_inodo != -1 {
+					continue
+				}
+				block.B_content[indexContent].B_name = [12]byte{}
+				copy(block.B_content[indexContent].B_name[:], name)
+				block.B_content[indexContent].B_inodo = childIndex
+				return block.Serialize(diskPath, int64(sb.S_block_start+sb.S_block_size*blockIndex))
+			}
+		}
+	}
+
+	// Hace falta un bloque carpeta nuevo
+	firstBlock := &FolderBlock{}
+	err = firstBlock.Deserialize(diskPath, int64(sb.S_block_start+sb.S_block_size*folder.I_block[0]))
+	if err != nil {
+		return err
+	}
+	folderBlock := &FolderBlock{
+		B_content: [4]FolderContent{
+			{B_name: [12]byte{'.'}, B_inodo: folderIndex},
+			{B_name: [12]byte{'.', '.'}, B_inodo: firstBlock.B_content[1].B_inodo},
+			{B_name: [12]byte{}, B_inodo: childIndex},
+			{B_name: [12]byte{'-'}, B_inodo: -1},
+		},
+	}
+	copy(folderBlock.B_content[2].B_name[:], name)
+
+	indexes, err := sb.FolderBlockIndexes(diskPath, folder)
+	if err != nil {
+		return err
+	}
+	blockIndex, blockOffset, err := sb.reserveBlock(diskPath)
+	if err != nil {
+		return err
+	}
+	err = folderBlock.Serialize(diskPath, blockOffset)
+	if err != nil {
+		return err
+	}
+	err = sb.setInodeBlock(diskPath, folder, len(indexes), blockIndex)
+	if err != nil {
+		return err
+	}
+	return folder.Serialize(diskPath, int64(sb.S_inode_start+sb.S_inode_size*folderIndex))
+}
+
+// RemoveEntryFromFolder desenlaza el inodo childIndex de la carpeta folderIndex
+func (sb *SuperBlock) RemoveEntryFromFolder(diskPath string, folderIndex int32, childIndex int32) error {
+	folder := &Inode{}
+	err := folder.Deserialize(diskPath, int64(sb.S_inode_start+sb.S_inode_size*folderIndex))
+	if err != nil {
+		return err
+	}
+	indexes, err := sb.FolderBlockIndexes(diskPath, folder)
+	if err != nil {
+		return err
+	}
+	for _, blockIndex := range indexes {
+		block := &FolderBlock{}
+		err := block.Deserialize(diskPath, int64(sb.S_block_start+sb.S_block_size*blockIndex))
+		if err != nil {
+			return err
+		}
+		for indexContent := 2; indexContent < len(block.B_content); indexContent++ {
+			if block.B_content[indexContent].B_inodo != childIndex {
+				continue
+			}
+			block.B_content[indexContent] = FolderContent{B_name: [12]byte{'-'}, B_inodo: -1}
+			return block.Serialize(diskPath, int64(sb.S_block_start+sb.S_block_size*blockIndex))
+		}
+	}
+	return NewError(ErrNotFound, "la entrada no existe en el directorio padre")
+}
+
+// RenameEntryInFolder cambia el nombre con el que la carpeta folderIndex enlaza al inodo childIndex
+func (sb *SuperBlock) RenameEntryInFolder(diskPath string, folderIndex int32, childIndex int32, name string) error {
+	folder := &Inode{}
+	err := folder.Deserialize(diskPath, int64(sb.S_inode_start+sb.S_inode_size*folderIndex))
+	if err != nil {
+		return err
+	}
+	indexes, err := sb.FolderBlockIndexes(diskPath, folder)
+	if err != nil {
+		return err
+	}
+	for _, blockIndex := range indexes {
+		block := &FolderBlock{}
+		err := block.Deserialize(diskPath, int64(sb.S_block_start+sb.S_block_size*blockIndex))
+		if err != nil {
+			return err
+		}
+		for indexContent := 2; indexContent < len(block.B_content); indexContent++ {
+			content := block.B_content[indexContent]
+			if content.B_inodo == -1 || content.B_inodo == childIndex {
+				continue
+			}
+			if strings.EqualFold(strings.Trim(string(content.B_name[:]), "\x00 "), name) {
+				return Errorf(ErrExists, "ya existe %s en el directorio", name)
+			}
+		}
+	}
+	for _, blockIndex := range indexes {
+		block := &FolderBlock{}
+		err := block.Deserialize(diskPath, int64(sb.S_block_start+sb.S_block_size*blockIndex))
+		if err != nil {
+			return err
+		}
+		for indexContent := 2; indexContent < len(block.B_content); indexContent++ {
+			if block.B_content[indexContent].B_inodo != childIndex {
+				continue
+			}
+			block.B_content[indexContent].B_name = [12]byte{}
+			copy(block.B_content[indexContent].B_name[:], name)
+			return block.Serialize(diskPath, int64(sb.S_block_start+sb.S_block_size*blockIndex))
+		}
+	}
+	return NewError(ErrNotFound, "la entrada no existe en el directorio padre")
+}
+
+// SetParentOfFolder actualiza la entrada ".." de todos los bloques de la carpeta folderIndex
+func (sb *SuperBlock) SetParentOfFolder(diskPath string, folderIndex int32, parentIndex int32) error {
+	folder := &Inode{}
+	err := folder.Deserialize(diskPath, int64(sb.S_inode_start+sb.S_inode_size*folderIndex))
+	if err != nil {
+		return err
+	}
+	indexes, err := sb.FolderBlockIndexes(diskPath, folder)
+	if err != nil {
+		return err
+	}
+	for _, blockIndex := range indexes {
+		block := &FolderBlock{}
+		err := block.Deserialize(diskPath, int64(sb.S_block_start+sb.S_block_size*blockIndex))
+		if err != nil {
+			return err
+		}
+		block.B_content[1].B_inodo = parentIndex
+		err = block.Serialize(diskPath, int64(sb.S_block_start+sb.S_block_size*blockIndex))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
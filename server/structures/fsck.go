@@ -0,0 +1,318 @@
+package structures
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// FsckReport guarda lo que encontro fsck, Pending son los problemas que no se pudieron reparar
+type FsckReport struct {
+	Problems []string
+	Repaired int
+	Pending  int
+}
+
+type fsckState struct {
+	diskPath    string
+	repair      bool
+	report      *FsckReport
+	reachable   []bool  // inodos alcanzables desde la raiz
+	blockOwner  []int32 // inodo que usa cada bloque, -1 si ninguno
+	inodeBitmap []byte
+	lastInode   int32
+	lastBlock   int32
+}
+
+func (st *fsckState) problem(repairable bool, format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	switch {
+	case !st.repair:
+	case repairable:
+		message += " (reparado)"
+		st.report.Repaired++
+	default:
+		message += " (no se puede reparar)"
+		st.report.Pending++
+	}
+	st.report.Problems = append(st.report.Problems, message)
+}
+
+// Fsck recorre el sistema de archivos desde el inodo raiz y lo compara con los bitmaps y los contadores del superbloque.
+// Con repair corrige lo que puede, el superbloque queda actualizado en memoria y quien llama lo serializa.
+func (sb *SuperBlock) Fsck(diskPath string, repair bool) (*FsckReport, error) {
+	root := &Inode{}
+	err := root.Deserialize(diskPath, int64(sb.S_inode_start))
+	if err != nil {
+		return nil, err
+	}
+	if root.I_type[0] != '0' {
+		return nil, NewError(ErrCorrupt, "el inodo raiz no es una carpeta, el sistema de archivos no se puede revisar")
+	}
+
+	st := &fsckState{
+		diskPath:   diskPath,
+		repair:     repair,
+		report:     &FsckReport{},
+		reachable:  make([]bool, sb.TotalInodes()),
+		blockOwner: make([]int32, sb.TotalBlocks()),
+		lastInode:  -1,
+		lastBlock:  -1,
+	}
+	for i := range st.blockOwner {
+		st.blockOwner[i] = -1
+	}
+	st.inodeBitmap, err = readBitmap(diskPath, sb.S_bm_inode_start, sb.TotalInodes())
+	if err != nil {
+		return nil, err
+	}
+	blockBitmap, err := readBitmap(diskPath, sb.S_bm_block_start, sb.TotalBlocks())
+	if err != nil {
+		return nil, err
+	}
+
+	err = sb.fsckInode(st, 0, root, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// Bitmaps contra lo alcanzable
+	var usedInodes, usedBlocks int32
+	for i, used := range st.reachable {
+		if used {
+			usedInodes++
+		}
+		switch {
+		case used && st.inodeBitmap[i] != '1':
+			st.problem(true, "el inodo %d esta en uso pero el bitmap lo marca libre", i)
+			st.inodeBitmap[i] = '1'
+		case !used && st.inodeBitmap[i] == '1':
+			st.problem(true, "el inodo %d es huerfano, esta marcado en el bitmap pero no es alcanzable desde la raiz", i)
+			st.inodeBitmap[i] = '0'
+		}
+	}
+	for i, owner := range st.blockOwner {
+		if owner != -1 {
+			usedBlocks++
+		}
+		switch {
+		case owner != -1 && blockBitmap[i] != 'X':
+			st.problem(true, "el bloque %d esta en uso por el inodo %d pero el bitmap lo marca libre", i, owner)
+			blockBitmap[i] = 'X'
+		case owner == -1 && blockBitmap[i] == 'X':
+			st.problem(true, "el bloque %d es huerfano, esta marcado en el bitmap pero ningun inodo lo usa", i)
+			blockBitmap[i] = 'O'
+		}
+	}
+
+	// Contadores del superbloque, sin repair solo se reportan
+	fixed := *sb
+	freeInodes := sb.TotalInodes() - usedInodes
+	if sb.S_free_inodes_count != freeInodes {
+		st.problem(true, "S_free_inodes_count es %d pero hay %d inodos libres", sb.S_free_inodes_count, freeInodes)
+		fixed.S_free_inodes_count = freeInodes
+	}
+	freeBlocks := sb.TotalBlocks() - usedBlocks
+	if sb.S_free_blocks_count != freeBlocks {
+		st.problem(true, "S_free_blocks_count es %d pero hay %d bloques libres", sb.S_free_blocks_count, freeBlocks)
+		fixed.S_free_blocks_count = freeBlocks
+	}
+	// S_inodes_count y S_blocks_count son el mayor indice usado mas uno, ninguno en uso puede estar despues
+	if st.lastInode >= sb.S_inodes_count {
+		st.problem(true, "S_inodes_count es %d pero el inodo %d esta en uso", sb.S_inodes_count, st.lastInode)
+		fixed.S_inodes_count = st.lastInode + 1
+		fixed.S_first_ino = sb.S_inode_start + fixed.S_inodes_count*sb.S_inode_size
+	}
+	if st.lastBlock >= sb.S_blocks_count {
+		st.problem(true, "S_blocks_count es %d pero el bloque %d esta en uso", sb.S_blocks_count, st.lastBlock)
+		fixed.S_blocks_count = st.lastBlock + 1
+		fixed.S_first_blo = sb.S_block_start + fixed.S_blocks_count*sb.S_block_size
+	}
+
+	if !repair {
+		return st.report, nil
+	}
+	*sb = fixed
+	err = writeBitmap(diskPath, sb.S_bm_inode_start, st.inodeBitmap)
+	if err != nil {
+		return nil, err
+	}
+	err = writeBitmap(diskPath, sb.S_bm_block_start, blockBitmap)
+	if err != nil {
+		return nil, err
+	}
+	return st.report, nil
+}
+
+// fsckInode revisa los bloques del inodo y, si es carpeta, sus entradas
+func (sb *SuperBlock) fsckInode(st *fsckState, index int32, inode *Inode, parent int32) error {
+	st.reachable[index] = true
+	if index > st.lastInode {
+		st.lastInode = index
+	}
+	data, err := sb.fsckInodeBlocks(st, index, inode)
+	if err != nil {
+		return err
+	}
+	if inode.I_type[0] != '0' {
+		return nil
+	}
+
+	for _, blockIndex := range data {
+		offset := int64(sb.S_block_start + sb.S_block_size*blockIndex)
+		block := &FolderBlock{}
+		err := block.Deserialize(st.diskPath, offset)
+		if err != nil {
+			return err
+		}
+		changed := false
+		if block.B_content[0].B_inodo != index || block.B_content[1].B_inodo != parent {
+			st.problem(true, "el bloque carpeta %d de la carpeta %d tiene mal las entradas . y ..", blockIndex, index)
+			block.B_content[0] = FolderContent{B_name: [12]byte{'.'}, B_inodo: index}
+			block.B_content[1] = FolderContent{B_name: [12]byte{'.', '.'}, B_inodo: parent}
+			changed = true
+		}
+		for indexContent := 2; indexContent < len(block.B_content); indexContent++ {
+			content := block.B_content[indexContent]
+			if content.B_inodo == -1 {
+				continue
+			}
+			name := strings.Trim(string(content.B_name[:]), "\x00 ")
+			child := &Inode{}
+			var reason string
+			switch {
+			case content.B_inodo < 0 || content.B_inodo >= sb.TotalInodes():
+				reason = fmt.Sprintf("a un inodo fuera de rango (%d)", content.B_inodo)
+			case st.reachable[content.B_inodo]:
+				reason = fmt.Sprintf("al inodo %d que ya esta enlazado en otra carpeta", content.B_inodo)
+			default:
+				err := child.Deserialize(st.diskPath, int64(sb.S_inode_start+sb.S_inode_size*content.B_inodo))
+				if err != nil {
+					return err
+				}
+				// Si el bitmap lo marca libre pero el inodo es valido se marca usado despues, como la raiz
+				if child.I_type[0] != '0' && child.I_type[0] != '1' {
+					reason = fmt.Sprintf("al inodo %d que no es carpeta ni archivo", content.B_inodo)
+				}
+			}
+			if reason == "" {
+				err := sb.fsckInode(st, content.B_inodo, child, index)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+			st.problem(true, "la entrada %s de la carpeta %d apunta %s", name, index, reason)
+			block.B_content[indexContent] = FolderContent{B_name: [12]byte{'-'}, B_inodo: -1}
+			changed = true
+		}
+		if changed && st.repair {
+			err := block.Serialize(st.diskPath, offset)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// fsckInodeBlocks marca los bloques del inodo como usados y devuelve sus bloques de datos en orden.
+// Los apuntadores fuera de rango se quitan al reparar.
+func (sb *SuperBlock) fsckInodeBlocks(st *fsckState, index int32, inode *Inode) ([]int32, error) {
+	var data []int32
+	changed := false
+	for i, blockIndex := range inode.I_block {
+		if blockIndex == -1 {
+			continue
+		}
+		valid, err := sb.fsckBlock(st, index, blockIndex, IndirectionLevel(i), &data)
+		if err != nil {
+			return nil, err
+		}
+		if !valid {
+			inode.I_block[i] = -1
+			changed = true
+		}
+	}
+	if changed && st.repair {
+		err := inode.Serialize(st.diskPath, int64(sb.S_inode_start+sb.S_inode_size*index))
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// fsckBlock devuelve false si el apuntador a blockIndex no es valido y hay que quitarlo
+func (sb *SuperBlock) fsckBlock(st *fsckState, owner, blockIndex int32, level int, data *[]int32) (bool, error) {
+	if blockIndex < 0 || blockIndex >= sb.TotalBlocks() {
+		st.problem(true, "el inodo %d apunta al bloque %d que esta fuera de rango", owner, blockIndex)
+		return false, nil
+	}
+	if st.blockOwner[blockIndex] != -1 {
+		st.problem(false, "el bloque %d esta usado por los inodos %d y %d", blockIndex, st.blockOwner[blockIndex], owner)
+		return true, nil
+	}
+	st.blockOwner[blockIndex] = owner
+	if blockIndex > st.lastBlock {
+		st.lastBlock = blockIndex
+	}
+	if level == 0 {
+		*data = append(*data, blockIndex)
+		return true, nil
+	}
+
+	offset := int64(sb.S_block_start + sb.S_block_size*blockIndex)
+	pointerBlock := &PointerBlock{}
+	err := pointerBlock.Deserialize(st.diskPath, offset)
+	if err != nil {
+		return false, err
+	}
+	changed := false
+	for i, value := range pointerBlock.P_pointers {
+		if value == -1 {
+			continue
+		}
+		valid, err := sb.fsckBlock(st, owner, value, level-1, data)
+		if err != nil {
+			return false, err
+		}
+		if !valid {
+			pointerBlock.P_pointers[i] = -1
+			changed = true
+		}
+	}
+	if changed && st.repair {
+		err = pointerBlock.Serialize(st.diskPath, offset)
+		if err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func readBitmap(diskPath string, start, length int32) ([]byte, error) {
+	file, err := os.Open(diskPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	buffer := make([]byte, length)
+	_, err = file.ReadAt(buffer, int64(start))
+	if err != nil {
+		return nil, err
+	}
+	return buffer, nil
+}
+
+func writeBitmap(diskPath string, start int32, bitmap []byte) error {
+	file, err := os.OpenFile(diskPath, os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.WriteAt(bitmap, int64(start))
+	return err
+}
@@ -0,0 +1,228 @@
+package structures
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// Se dañan los bitmaps, un apuntador y los contadores de un disco sano: sin -repair fsck solo los reporta,
+// con -repair el disco vuelve a quedar como estaba y una segunda revision no encuentra nada
+func TestFsckRepair(t *testing.T) {
+	sb, diskPath := newTestDisk(t, 50)
+	err := sb.CreateFolder(diskPath, nil, "docs", false, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := strings.Repeat("fsck", 20*16)
+	err = sb.CreateFile(diskPath, 0, []string{"docs"}, "a.txt", content, 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := sb.Fsck(diskPath, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Problems) != 0 {
+		t.Fatalf("el disco recien creado tiene problemas: %q", report.Problems)
+	}
+
+	healthyInodes, err := readBitmap(diskPath, sb.S_bm_inode_start, sb.TotalInodes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	healthyBlocks, err := readBitmap(diskPath, sb.S_bm_block_start, sb.TotalBlocks())
+	if err != nil {
+		t.Fatal(err)
+	}
+	freeInodes, freeBlocks := sb.S_free_inodes_count, sb.S_free_blocks_count
+	blocksCount, firstBlock := sb.S_blocks_count, sb.S_first_blo
+
+	fileIndex, file, err := sb.Lookup(diskPath, "/docs/a.txt", 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, pointers, err := sb.InodeBlocks(diskPath, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pointers) != 1 {
+		t.Fatalf("el archivo deberia usar el apuntador indirecto simple y usa %d bloques de apuntadores", len(pointers))
+	}
+	pointerOffset := int64(sb.S_block_start + sb.S_block_size*pointers[0])
+
+	// Ocho problemas que se pueden reparar
+	for _, mark := range []struct {
+		position int32
+		value    byte
+	}{
+		{sb.S_bm_inode_start + fileIndex, '0'}, // inodo en uso marcado libre, se vuelve a marcar y no se desenlaza
+		{sb.S_bm_block_start + data[3], 'O'},   // bloque en uso marcado libre
+		{sb.S_bm_block_start + 140, 'X'},       // bloque huerfano
+		{sb.S_bm_inode_start + 40, '1'},        // inodo huerfano
+	} {
+		if _, err := setBitmap(diskPath, mark.position, mark.value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pointerBlock := &PointerBlock{}
+	err = pointerBlock.Deserialize(diskPath, pointerOffset)
+	if err == nil {
+		pointerBlock.P_pointers[15] = 9999 // apuntador fuera de rango
+		err = pointerBlock.Serialize(diskPath, pointerOffset)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	sb.S_free_inodes_count = 0
+	sb.S_free_blocks_count += 7
+	sb.S_blocks_count = 1
+	const problems = 8
+
+	damagedBlocks, err := readBitmap(diskPath, sb.S_bm_block_start, sb.TotalBlocks())
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err = sb.Fsck(diskPath, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Problems) != problems || report.Repaired != 0 {
+		t.Fatalf("sin reparar se esperaban %d problemas y llegaron %d: %q", problems, len(report.Problems), report.Problems)
+	}
+	if sb.S_free_inodes_count != 0 || sb.S_blocks_count != 1 {
+		t.Fatal("fsck sin -repair modifico el superbloque")
+	}
+	if blocks, _ := readBitmap(diskPath, sb.S_bm_block_start, sb.TotalBlocks()); !bytes.Equal(blocks, damagedBlocks) {
+		t.Fatal("fsck sin -repair modifico el bitmap de bloques")
+	}
+
+	report, err = sb.Fsck(diskPath, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Problems) != problems || report.Repaired != problems || report.Pending != 0 {
+		t.Fatalf("se esperaban %d problemas reparados y llegaron %d de %d: %q", problems, report.Repaired, len(report.Problems), report.Problems)
+	}
+
+	if inodes, _ := readBitmap(diskPath, sb.S_bm_inode_start, sb.TotalInodes()); !bytes.Equal(inodes, healthyInodes) {
+		t.Errorf("el bitmap de inodos no quedo como antes del daño:\n%s\n%s", inodes, healthyInodes)
+	}
+	if blocks, _ := readBitmap(diskPath, sb.S_bm_block_start, sb.TotalBlocks()); !bytes.Equal(blocks, healthyBlocks) {
+		t.Errorf("el bitmap de bloques no quedo como antes del daño:\n%s\n%s", blocks, healthyBlocks)
+	}
+	if sb.S_free_inodes_count != freeInodes || sb.S_free_blocks_count != freeBlocks {
+		t.Errorf("contadores %d/%d, se esperaban %d/%d", sb.S_free_inodes_count, sb.S_free_blocks_count, freeInodes, freeBlocks)
+	}
+	if sb.S_blocks_count != blocksCount || sb.S_first_blo != firstBlock {
+		t.Errorf("S_blocks_count %d y S_first_blo %d, se esperaban %d y %d", sb.S_blocks_count, sb.S_first_blo, blocksCount, firstBlock)
+	}
+	err = pointerBlock.Deserialize(diskPath, pointerOffset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pointerBlock.P_pointers[15] != -1 {
+		t.Errorf("el apuntador fuera de rango sigue en el disco: %d", pointerBlock.P_pointers[15])
+	}
+
+	if index, _, err := sb.Lookup(diskPath, "/docs/a.txt", 1, 1); err != nil || index != fileIndex {
+		t.Fatalf("despues de reparar /docs/a.txt ya no esta enlazado (%v)", err)
+	}
+	err = file.Deserialize(diskPath, int64(sb.S_inode_start+sb.S_inode_size*fileIndex))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := sb.ReadFileContent(diskPath, file); err != nil || got != content {
+		t.Errorf("el contenido del archivo cambio despues de reparar (%v)", err)
+	}
+	report, err = sb.Fsck(diskPath, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Problems) != 0 {
+		t.Errorf("despues de reparar quedan problemas: %q", report.Problems)
+	}
+}
+
+// Un bloque compartido por dos inodos no se puede reparar, queda como pendiente
+func TestFsckSharedBlock(t *testing.T) {
+	sb, diskPath := newTestDisk(t, 50)
+	for _, name := range []string{"a.txt", "b.txt"} {
+		err := sb.CreateFile(diskPath, 0, nil, name, "contenido de "+name, 1, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, a, err := sb.Lookup(diskPath, "/a.txt", 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bIndex, b, err := sb.Lookup(diskPath, "/b.txt", 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.I_block[1] = a.I_block[0]
+	err = b.Serialize(diskPath, int64(sb.S_inode_start+sb.S_inode_size*bIndex))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := sb.Fsck(diskPath, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Pending != 1 || report.Repaired != 0 {
+		t.Fatalf("se esperaba un problema pendiente y llegaron %d reparados y %d pendientes: %q", report.Repaired, report.Pending, report.Problems)
+	}
+}
+
+// Una entrada que apunta a un inodo libre sin tipo valido si se quita al reparar
+func TestFsckEntryToInvalidInode(t *testing.T) {
+	sb, diskPath := newTestDisk(t, 50)
+	err := sb.CreateFile(diskPath, 0, nil, "a.txt", "contenido", 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := &Inode{}
+	err = root.Deserialize(diskPath, int64(sb.S_inode_start))
+	if err != nil {
+		t.Fatal(err)
+	}
+	offset := int64(sb.S_block_start + sb.S_block_size*root.I_block[0])
+	block := &FolderBlock{}
+	err = block.Deserialize(diskPath, offset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, err := sb.FindEntryInFolder(diskPath, root, "a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range block.B_content {
+		if block.B_content[i].B_inodo == entry {
+			block.B_content[i].B_inodo = 45 // inodo libre, en ceros
+		}
+	}
+	err = block.Serialize(diskPath, offset)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := sb.Fsck(diskPath, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Pending != 0 || !strings.Contains(strings.Join(report.Problems, "\n"), "al inodo 45 que no es carpeta ni archivo") {
+		t.Fatalf("no se reporto la entrada al inodo invalido: %q", report.Problems)
+	}
+	if _, _, err := sb.Lookup(diskPath, "/a.txt", 1, 1); err == nil {
+		t.Fatal("la entrada al inodo invalido sigue en la carpeta")
+	}
+	report, err = sb.Fsck(diskPath, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Problems) != 0 {
+		t.Errorf("despues de reparar quedan problemas: %q", report.Problems)
+	}
+}
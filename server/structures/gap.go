@@ -0,0 +1,59 @@
+package structures
+
+// Gap es un rango de bytes del disco o de la extendida
+type Gap struct {
+	Start int32
+	Size  int32
+}
+
+// FreeGaps devuelve los huecos entre start y end que no ocupan los rangos used, que vienen ordenados por inicio
+func FreeGaps(start, end int32, used []Gap) []Gap {
+	var gaps []Gap
+	cursor := start
+	for _, region := range used {
+		if region.Start > cursor {
+			gaps = append(gaps, Gap{Start: cursor, Size: region.Start - cursor})
+		}
+		if region.Start+region.Size > cursor {
+			cursor = region.Start + region.Size
+		}
+	}
+	if end > cursor {
+		gaps = append(gaps, Gap{Start: cursor, Size: end - cursor})
+	}
+	return gaps
+}
+
+/*
+Fit:
+
+	F: primer hueco donde quepa
+	B: hueco mas pequeño donde quepa
+	W: hueco mas grande
+*/
+func SelectGap(gaps []Gap, size int32, fit byte) (Gap, bool) {
+	selected := -1
+	for i, gap := range gaps {
+		if gap.Size < size {
+			continue
+		}
+		switch fit {
+		case 'B':
+			if selected == -1 || gap.Size < gaps[selected].Size {
+				selected = i
+			}
+		case 'W':
+			if selected == -1 || gap.Size > gaps[selected].Size {
+				selected = i
+			}
+		default:
+			if selected == -1 {
+				selected = i
+			}
+		}
+	}
+	if selected == -1 {
+		return Gap{}, false
+	}
+	return gaps[selected], true
+}
@@ -0,0 +1,311 @@
+package structures
+
+import (
+	utils "server/utils"
+	"strings"
+)
+
+/*
+I_block:
+
+	0 - 11: apuntadores directos
+	12: apuntador indirecto simple
+	13: apuntador indirecto doble
+	14: apuntador indirecto triple
+*/
+const DirectBlocks = 12
+
+const pointersPerBlock = len(PointerBlock{}.P_pointers)
+
+// IndirectionLevel devuelve cuantos bloques de apuntadores hay entre el I_block[i] y los datos
+func IndirectionLevel(i int) int {
+	if i < DirectBlocks {
+		return 0
+	}
+	return i - DirectBlocks + 1
+}
+
+func blocksPerLevel(level int) int {
+	capacity := 1
+	for i := 0; i < level; i++ {
+		capacity *= pointersPerBlock
+	}
+	return capacity
+}
+
+// MaxInodeBlocks es la cantidad de bloques de datos que puede direccionar un inodo
+func MaxInodeBlocks() int {
+	total := DirectBlocks
+	for level := 1; level <= 3; level++ {
+		total += blocksPerLevel(level)
+	}
+	return total
+}
+
+// PointerBlocksNeeded devuelve cuantos bloques de apuntadores usa un inodo con count bloques de datos
+func PointerBlocksNeeded(count int) int32 {
+	var needed int32
+	remaining := count - DirectBlocks
+	for level := 1; level <= 3 && remaining > 0; level++ {
+		used := remaining
+		if used > blocksPerLevel(level) {
+			used = blocksPerLevel(level)
+		}
+		remaining -= used
+		for i := 0; i < level; i++ {
+			used = (used + pointersPerBlock - 1) / pointersPerBlock
+			needed += int32(used)
+		}
+	}
+	return needed
+}
+
+// FileBlocksNeeded devuelve cuantos bloques, de datos y de apuntadores, ocupa un contenido
+func FileBlocksNeeded(content string) (int32, error) {
+	chunks := len(utils.SplitStringIntoChunks(content))
+	if chunks > MaxInodeBlocks() {
+		return 0, NewError(ErrNoSpace, "el contenido excede el tamaño maximo de un archivo")
+	}
+	return int32(chunks) + PointerBlocksNeeded(chunks), nil
+}
+
+// blockPath devuelve el I_block y las posiciones dentro de cada bloque de apuntadores que llevan al bloque de datos numero position
+func blockPath(position int) (int, []int, error) {
+	if position < DirectBlocks {
+		return position, nil, nil
+	}
+	position -= DirectBlocks
+	for slot := DirectBlocks; slot < len(Inode{}.I_block); slot++ {
+		level := IndirectionLevel(slot)
+		if position >= blocksPerLevel(level) {
+			position -= blocksPerLevel(level)
+			continue
+		}
+		indexes := make([]int, level)
+		for i := level - 1; i >= 0; i-- {
+			indexes[i] = position % pointersPerBlock
+			position /= pointersPerBlock
+		}
+		return slot, indexes, nil
+	}
+	return -1, nil, NewError(ErrNoSpace, "el inodo no admite mas bloques")
+}
+
+// InodeBlocks devuelve los bloques de datos del inodo en orden y los bloques de apuntadores que usa
+func (sb *SuperBlock) InodeBlocks(diskPath string, inode *Inode) ([]int32, []int32, error) {
+	var data, pointers []int32
+	for i, blockIndex := range inode.I_block {
+		if blockIndex == -1 {
+			continue
+		}
+		err := sb.collectBlocks(diskPath, blockIndex, IndirectionLevel(i), &data, &pointers)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return data, pointers, nil
+}
+
+func (sb *SuperBlock) collectBlocks(diskPath string, blockIndex int32, level int, data, pointers *[]int32) error {
+	if level == 0 {
+		*data = append(*data, blockIndex)
+		return nil
+	}
+	*pointers = append(*pointers, blockIndex)
+	pointerBlock := &PointerBlock{}
+	err := pointerBlock.Deserialize(diskPath, int64(sb.S_block_start+sb.S_block_size*blockIndex))
+	if err != nil {
+		return err
+	}
+	for _, value := range pointerBlock.P_pointers {
+		if value == -1 {
+			continue
+		}
+		err = sb.collectBlocks(diskPath, value, level-1, data, pointers)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// missingPointerBlocks devuelve cuantos bloques de apuntadores habria que crear para enlazar el bloque numero position
+func (sb *SuperBlock) missingPointerBlocks(diskPath string, inode *Inode, position int) (int32, error) {
+	slot, indexes, err := blockPath(position)
+	if err != nil {
+		return 0, err
+	}
+	current := inode.I_block[slot]
+	for level := 0; level < len(indexes); level++ {
+		if current == -1 {
+			return int32(len(indexes) - level), nil
+		}
+		pointerBlock := &PointerBlock{}
+		err := pointerBlock.Deserialize(diskPath, int64(sb.S_block_start+sb.S_block_size*current))
+		if err != nil {
+			return 0, err
+		}
+		current = pointerBlock.P_pointers[indexes[level]]
+	}
+	return 0, nil
+}
+
+// newPointerBlock reserva un bloque de apuntadores vacio
+func (sb *SuperBlock) newPointerBlock(diskPath string) (int32, error) {
+	blockIndex, offset, err := sb.reserveBlock(diskPath)
+	if err != nil {
+		return -1, err
+	}
+	pointerBlock := &PointerBlock{
+		P_pointers: [16]int32{-1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1, -1},
+	}
+	return blockIndex, pointerBlock.Serialize(diskPath, offset)
+}
+
+// setInodeBlock enlaza blockIndex como el bloque de datos numero position del inodo, creando los bloques de apuntadores que falten.
+// El inodo no se serializa, eso queda a cargo de quien llama.
+func (sb *SuperBlock) setInodeBlock(diskPath string, inode *Inode, position int, blockIndex int32) error {
+	slot, indexes, err := blockPath(position)
+	if err != nil {
+		return err
+	}
+	if len(indexes) == 0 {
+		inode.I_block[slot] = blockIndex
+		return nil
+	}
+	if inode.I_block[slot] == -1 {
+		inode.I_block[slot], err = sb.newPointerBlock(diskPath)
+		if err != nil {
+			return err
+		}
+	}
+	current := inode.I_block[slot]
+	for level, index := range indexes {
+		offset := int64(sb.S_block_start + sb.S_block_size*current)
+		pointerBlock := &PointerBlock{}
+		err := pointerBlock.Deserialize(diskPath, offset)
+		if err != nil {
+			return err
+		}
+		if level == len(indexes)-1 {
+			pointerBlock.P_pointers[index] = blockIndex
+			return pointerBlock.Serialize(diskPath, offset)
+		}
+		if pointerBlock.P_pointers[index] == -1 {
+			pointerBlock.P_pointers[index], err = sb.newPointerBlock(diskPath)
+			if err != nil {
+				return err
+			}
+			err = pointerBlock.Serialize(diskPath, offset)
+			if err != nil {
+				return err
+			}
+		}
+		current = pointerBlock.P_pointers[index]
+	}
+	return nil
+}
+
+// writeFileContent escribe el contenido en bloques nuevos enlazados a partir del bloque numero first del inodo
+func (sb *SuperBlock) writeFileContent(diskPath string, inode *Inode, first int, content string) error {
+	for i, chunk := range utils.SplitStringIntoChunks(content) {
+		blockIndex, offset, err := sb.reserveBlock(diskPath)
+		if err != nil {
+			return err
+		}
+		fileBlock := &FileBlock{}
+		copy(fileBlock.B_content[:], chunk)
+		err = fileBlock.Serialize(diskPath, offset)
+		if err != nil {
+			return err
+		}
+		err = sb.setInodeBlock(diskPath, inode, first+i, blockIndex)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadFileContent devuelve el contenido de un inodo archivo recorriendo todos sus bloques
+func (sb *SuperBlock) ReadFileContent(diskPath string, inode *Inode) (string, error) {
+	data, _, err := sb.InodeBlocks(diskPath, inode)
+	if err != nil {
+		return "", err
+	}
+	var content strings.Builder
+	for _, blockIndex := range data {
+		fileBlock := &FileBlock{}
+		err := fileBlock.Deserialize(diskPath, int64(sb.S_block_start+sb.S_block_size*blockIndex))
+		if err != nil {
+			return "", err
+		}
+		content.WriteString(strings.TrimRight(string(fileBlock.B_content[:]), "\x00"))
+	}
+	return content.String(), nil
+}
+
+// truncateFileBlocks deja enlazados solo los primeros keep bloques de datos y libera los demas junto con los bloques de apuntadores.
+// Los apuntadores de los bloques que quedan se vuelven a crear, el inodo no se serializa.
+func (sb *SuperBlock) truncateFileBlocks(diskPath string, inode *Inode, keep int, data, pointers []int32) error {
+	for _, blockIndex := range append(append([]int32{}, data[keep:]...), pointers...) {
+		err := sb.FreeBitmapBlock(diskPath, blockIndex)
+		if err != nil {
+			return err
+		}
+	}
+	for i := range inode.I_block {
+		inode.I_block[i] = -1
+	}
+	for i, blockIndex := range data[:keep] {
+		err := sb.setInodeBlock(diskPath, inode, i, blockIndex)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReplaceFileContent reescribe el archivo reutilizando sus bloques, agregando los que falten y liberando los que sobren, sin validar permisos
+func (sb *SuperBlock) ReplaceFileContent(diskPath string, inodeIndex int32, content string) error {
+	inode := &Inode{}
+	err := inode.Deserialize(diskPath, int64(sb.S_inode_start+sb.S_inode_size*inodeIndex))
+	if err != nil {
+		return err
+	}
+	data, pointers, err := sb.InodeBlocks(diskPath, inode)
+	if err != nil {
+		return err
+	}
+	chunks := utils.SplitStringIntoChunks(content)
+	if len(chunks) > MaxInodeBlocks() {
+		return NewError(ErrNoSpace, "el contenido excede el tamaño maximo de un archivo")
+	}
+	needed := int32(len(chunks)-len(data)) + PointerBlocksNeeded(len(chunks)) - PointerBlocksNeeded(len(data))
+	if needed > 0 && !sb.HasSpaceFor(0, needed) {
+		return NewError(ErrNoSpace, "no hay bloques disponibles en la particion")
+	}
+
+	for i := 0; i < len(data) && i < len(chunks); i++ {
+		fileBlock := &FileBlock{}
+		copy(fileBlock.B_content[:], chunks[i])
+		err := fileBlock.Serialize(diskPath, int64(sb.S_block_start+sb.S_block_size*data[i]))
+		if err != nil {
+			return err
+		}
+	}
+	if len(chunks) < len(data) {
+		err = sb.truncateFileBlocks(diskPath, inode, len(chunks), data, pointers)
+		if err != nil {
+			return err
+		}
+	}
+	if len(chunks) > len(data) {
+		err = sb.writeFileContent(diskPath, inode, len(data), strings.Join(chunks[len(data):], ""))
+		if err != nil {
+			return err
+		}
+	}
+	inode.I_size = int32(len(content))
+	return inode.Serialize(diskPath, int64(sb.S_inode_start+sb.S_inode_size*inodeIndex))
+}
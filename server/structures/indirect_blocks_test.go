@@ -0,0 +1,186 @@
+package structures
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestDisk formatea en ext2 un archivo temporal con n inodos y 3n bloques, la particion empieza en el byte 0
+func newTestDisk(t *testing.T, n int32) (*SuperBlock, string) {
+	t.Helper()
+	inodeSize := int32(binary.Size(Inode{}))
+	blockSize := int32(binary.Size(FileBlock{}))
+	bmInodeStart := int32(binary.Size(SuperBlock{}))
+	bmBlockStart := bmInodeStart + n
+	inodeStart := bmBlockStart + 3*n
+	blockStart := inodeStart + inodeSize*n
+
+	diskPath := filepath.Join(t.TempDir(), "test.dsk")
+	err := os.WriteFile(diskPath, make([]byte, blockStart+3*n*blockSize), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sb := &SuperBlock{
+		S_filesystem_type:   2,
+		S_free_inodes_count: n,
+		S_free_blocks_count: 3 * n,
+		S_mtime:             float32(time.Now().Unix()),
+		S_umtime:            float32(time.Now().Unix()),
+		S_mnt_count:         1,
+		S_magic:             0xEF53,
+		S_inode_size:        inodeSize,
+		S_block_size:        blockSize,
+		S_first_ino:         inodeStart,
+		S_first_blo:         blockStart,
+		S_bm_inode_start:    bmInodeStart,
+		S_bm_block_start:    bmBlockStart,
+		S_inode_start:       inodeStart,
+		S_block_start:       blockStart,
+	}
+	err = sb.CreateBitMaps(diskPath)
+	if err == nil {
+		err = sb.CreateUsersFile(diskPath, 0)
+	}
+	if err == nil {
+		err = sb.Serialize(diskPath, 0)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sb, diskPath
+}
+
+// usedMarks cuenta las posiciones marcadas como usadas en un bitmap del disco
+func usedMarks(t *testing.T, diskPath string, start, length int32, used byte) int32 {
+	t.Helper()
+	bitmap, err := readBitmap(diskPath, start, length)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return int32(strings.Count(string(bitmap), string(used)))
+}
+
+func TestBlockPath(t *testing.T) {
+	for _, c := range []struct {
+		position int
+		slot     int
+		indexes  []int
+	}{
+		{0, 0, nil},
+		{11, 11, nil},
+		{12, 12, []int{0}},
+		{27, 12, []int{15}},
+		{28, 13, []int{0, 0}},
+		{29, 13, []int{0, 1}},
+		{44, 13, []int{1, 0}},
+		{283, 13, []int{15, 15}},
+		{284, 14, []int{0, 0, 0}},
+		{284 + 256 + 17, 14, []int{1, 1, 1}},
+		{MaxInodeBlocks() - 1, 14, []int{15, 15, 15}},
+	} {
+		slot, indexes, err := blockPath(c.position)
+		if err != nil {
+			t.Fatalf("bloque %d: %v", c.position, err)
+		}
+		if slot != c.slot || !reflect.DeepEqual(indexes, c.indexes) {
+			t.Errorf("bloque %d: se esperaba I_block[%d] %v y llego I_block[%d] %v", c.position, c.slot, c.indexes, slot, indexes)
+		}
+	}
+
+	if _, _, err := blockPath(MaxInodeBlocks()); !errors.Is(err, ErrNoSpace) {
+		t.Errorf("el bloque %d no cabe en el inodo y llego %v", MaxInodeBlocks(), err)
+	}
+}
+
+func TestPointerBlocksNeeded(t *testing.T) {
+	if MaxInodeBlocks() != 12+16+16*16+16*16*16 {
+		t.Fatalf("MaxInodeBlocks devolvio %d", MaxInodeBlocks())
+	}
+	for _, c := range []struct {
+		blocks int
+		needed int32
+	}{
+		{0, 0},
+		{12, 0},
+		{13, 1},
+		{28, 1},
+		{29, 1 + 2},
+		{44, 1 + 2},
+		{45, 1 + 3},
+		{284, 1 + 17},
+		{285, 1 + 17 + 3},
+		{284 + 17, 1 + 17 + 4},
+		{MaxInodeBlocks(), 1 + 17 + 256 + 16 + 1},
+	} {
+		if got := PointerBlocksNeeded(c.blocks); got != c.needed {
+			t.Errorf("%d bloques de datos: se esperaban %d bloques de apuntadores y llegaron %d", c.blocks, c.needed, got)
+		}
+	}
+}
+
+// Un archivo que pasa por los apuntadores directos, el simple, el doble y el triple y vuelve a achicarse
+// debe dejar en el bitmap y en el superbloque solo los bloques que usa
+func TestReplaceFileContentBlocks(t *testing.T) {
+	sb, diskPath := newTestDisk(t, 150)
+	baseUsed := usedMarks(t, diskPath, sb.S_bm_block_start, sb.TotalBlocks(), 'X')
+	baseFree := sb.S_free_blocks_count
+
+	err := sb.CreateFile(diskPath, 0, nil, "big.txt", "", 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inodeIndex, _, err := sb.Lookup(diskPath, "/big.txt", 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, chunks := range []int{30, 2, 300, 13, 0} {
+		content := ""
+		if chunks > 0 {
+			content = strings.Repeat("x", chunks*64-10)
+		}
+		err := sb.ReplaceFileContent(diskPath, inodeIndex, content)
+		if err != nil {
+			t.Fatalf("%d bloques: %v", chunks, err)
+		}
+
+		inode := &Inode{}
+		err = inode.Deserialize(diskPath, int64(sb.S_inode_start+sb.S_inode_size*inodeIndex))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := sb.ReadFileContent(diskPath, inode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != content || inode.I_size != int32(len(content)) {
+			t.Fatalf("%d bloques: se leyeron %d bytes, I_size %d, se escribieron %d", chunks, len(got), inode.I_size, len(content))
+		}
+		data, pointers, err := sb.InodeBlocks(diskPath, inode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(data) != chunks || int32(len(pointers)) != PointerBlocksNeeded(chunks) {
+			t.Fatalf("%d bloques: el inodo enlaza %d de datos y %d de apuntadores", chunks, len(data), len(pointers))
+		}
+		for slot := DirectBlocks; slot < len(inode.I_block); slot++ {
+			if chunks <= DirectBlocks && inode.I_block[slot] != -1 {
+				t.Fatalf("%d bloques: I_block[%d] sigue enlazado", chunks, slot)
+			}
+		}
+
+		want := int32(chunks) + PointerBlocksNeeded(chunks)
+		if used := usedMarks(t, diskPath, sb.S_bm_block_start, sb.TotalBlocks(), 'X') - baseUsed; used != want {
+			t.Fatalf("%d bloques: el bitmap tiene %d bloques del archivo y se esperaban %d", chunks, used, want)
+		}
+		if free := baseFree - sb.S_free_blocks_count; free != want {
+			t.Fatalf("%d bloques: el superbloque descuenta %d bloques y se esperaban %d", chunks, free, want)
+		}
+	}
+}
@@ -9,6 +9,9 @@ import (
 	"time"
 )
 
+// RootUserID es el UID de root en users.txt, es el unico que se salta los permisos
+const RootUserID = 1
+
 type Inode struct {
 	I_uid   int32
 	I_gid   int32
@@ -91,7 +94,7 @@ func (inode *Inode) HasPermissionsToWrite(userID, groupID int32) (bool, error) {
 	ownerGroupId := inode.I_gid
 	permissions := string(inode.I_perm[:])
 
-	if ownerUserId == userID || userID == 1 {
+	if ownerUserId == userID || userID == RootUserID {
 		permUser, err := strconv.Atoi(string(permissions[0]))
 		if err != nil {
 			return false, err
@@ -100,7 +103,7 @@ func (inode *Inode) HasPermissionsToWrite(userID, groupID int32) (bool, error) {
 			return true, nil
 		}
 	}
-	if ownerGroupId == groupID || userID == 1 {
+	if ownerGroupId == groupID || userID == RootUserID {
 		permGroup, err := strconv.Atoi(string(permissions[1]))
 		if err != nil {
 			return false, err
@@ -119,9 +122,41 @@ func (inode *Inode) HasPermissionsToWrite(userID, groupID int32) (bool, error) {
 	return false, nil
 }
 
+// HasPermissionsToExecute es el permiso para entrar a una carpeta, root siempre puede
+func (inode *Inode) HasPermissionsToExecute(userID, groupID int32) (bool, error) {
+	if userID == RootUserID {
+		return true, nil
+	}
+	permissions := string(inode.I_perm[:])
+
+	if inode.I_uid == userID {
+		permUser, err := strconv.Atoi(string(permissions[0]))
+		if err != nil {
+			return false, err
+		}
+		if permUser%2 == 1 {
+			return true, nil
+		}
+	}
+	if inode.I_gid == groupID {
+		permGroup, err := strconv.Atoi(string(permissions[1]))
+		if err != nil {
+			return false, err
+		}
+		if permGroup%2 == 1 {
+			return true, nil
+		}
+	}
+	permOther, err := strconv.Atoi(string(permissions[2]))
+	if err != nil {
+		return false, err
+	}
+	return permOther%2 == 1, nil
+}
+
 func (inode *Inode) HasPermissionsChmod(userID, groupID int32) (bool, error) {
 	ownerUserId := inode.I_uid
-	if ownerUserId == userID || userID == 1 {
+	if ownerUserId == userID || userID == RootUserID {
 		return true, nil
 	}
 	return false, nil
@@ -131,7 +166,7 @@ func (inode *Inode) HasPermissionsToRead(userID, groupID int32) (bool, error) {
 	ownerGroupId := inode.I_gid
 	permissions := string(inode.I_perm[:])
 
-	if ownerUserId == userID || userID == 1 {
+	if ownerUserId == userID || userID == RootUserID {
 		permUser, err := strconv.Atoi(string(permissions[0]))
 		if err != nil {
 			return false, err
@@ -140,7 +175,7 @@ func (inode *Inode) HasPermissionsToRead(userID, groupID int32) (bool, error) {
 			return true, nil
 		}
 	}
-	if ownerGroupId == groupID || userID == 1 {
+	if ownerGroupId == groupID || userID == RootUserID {
 		permGroup, err := strconv.Atoi(string(permissions[1]))
 		if err != nil {
 			return false, err
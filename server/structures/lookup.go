@@ -0,0 +1,136 @@
+package structures
+
+import (
+	"fmt"
+	utils "server/utils"
+	"strings"
+)
+
+// NotFoundError indica que la ruta no existe, o que algo en el camino no es una carpeta
+type NotFoundError struct {
+	Path string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("no existe la ruta %s", e.Path)
+}
+
+func (e *NotFoundError) Unwrap() error {
+	return ErrNotFound
+}
+
+// PermissionError indica que el usuario no tiene el permiso Op sobre Path
+type PermissionError struct {
+	Path string
+	Op   string
+}
+
+func (e *PermissionError) Error() string {
+	return fmt.Sprintf("no tiene permiso de %s sobre %s", e.Op, e.Path)
+}
+
+func (e *PermissionError) Unwrap() error {
+	return ErrPermission
+}
+
+// Lookup devuelve el inodo de la ruta absoluta validando el permiso de ejecucion del usuario en cada carpeta que recorre
+func (sb *SuperBlock) Lookup(diskPath, path string, userID, groupID int32) (int32, *Inode, error) {
+	parentsDir, destDir := utils.GetParentDirectories(path)
+	if destDir != "" {
+		parentsDir = append(parentsDir, destDir)
+	}
+	return sb.lookupFrom(diskPath, 0, parentsDir, userID, groupID)
+}
+
+// lookupFrom recorre names desde inodeIndex, el unico recorrido de rutas del sistema de archivos
+func (sb *SuperBlock) lookupFrom(diskPath string, inodeIndex int32, names []string, userID, groupID int32) (int32, *Inode, error) {
+	inode := &Inode{}
+	err := inode.Deserialize(diskPath, int64(sb.S_inode_start+(inodeIndex*sb.S_inode_size)))
+	if err != nil {
+		return -1, nil, err
+	}
+	walked := ""
+	for _, name := range names {
+		name = strings.Trim(name, "\x00 ")
+		if inode.I_type[0] != '0' {
+			return -1, nil, &NotFoundError{Path: walked + "/" + name}
+		}
+		outcome, err := inode.HasPermissionsToExecute(userID, groupID)
+		if err != nil {
+			return -1, nil, err
+		}
+		if !outcome {
+			return -1, nil, &PermissionError{Path: pathOrRoot(walked), Op: "ejecucion"}
+		}
+		walked += "/" + name
+		next, err := sb.FindEntryInFolder(diskPath, inode, name)
+		if err != nil {
+			return -1, nil, err
+		}
+		if next == -1 {
+			return -1, nil, &NotFoundError{Path: walked}
+		}
+		inodeIndex = next
+		inode = &Inode{}
+		err = inode.Deserialize(diskPath, int64(sb.S_inode_start+(inodeIndex*sb.S_inode_size)))
+		if err != nil {
+			return -1, nil, err
+		}
+	}
+	return inodeIndex, inode, nil
+}
+
+func pathOrRoot(path string) string {
+	if path == "" {
+		return "/"
+	}
+	return path
+}
+
+// IsUsersFile indica si el inodo es el users.txt de la raiz, se compara el inodo y no la ruta para que una copia no cuente
+func (sb *SuperBlock) IsUsersFile(diskPath string, inodeIndex int32) (bool, error) {
+	root := &Inode{}
+	err := root.Deserialize(diskPath, int64(sb.S_inode_start))
+	if err != nil {
+		return false, err
+	}
+	usersIndex, err := sb.FindEntryInFolder(diskPath, root, "users.txt")
+	if err != nil {
+		return false, err
+	}
+	return usersIndex != -1 && usersIndex == inodeIndex, nil
+}
+
+// ProtectUsersFile es el error de remove, move, rename y copy sobre users.txt: sin el nadie puede volver a iniciar sesion
+// y una copia dejaria los passwords en un archivo que no se reconoce como users.txt
+func (sb *SuperBlock) ProtectUsersFile(diskPath string, inodeIndex int32, operation string) error {
+	isUsers, err := sb.IsUsersFile(diskPath, inodeIndex)
+	if err != nil {
+		return err
+	}
+	if isUsers {
+		return Errorf(ErrPermission, "no se puede %s /users.txt", operation)
+	}
+	return nil
+}
+
+// ReadFileForUser lee el archivo con los permisos del usuario, a los que no son root users.txt les llega sin passwords
+func (sb *SuperBlock) ReadFileForUser(diskPath, path string, userID, groupID int32) (string, error) {
+	parentDirs, destDir := utils.GetParentDirectories(path)
+	content, err := sb.ContentFromFileCat(diskPath, 0, parentDirs, destDir, userID, groupID)
+	if err != nil || userID == RootUserID {
+		return content, err
+	}
+	inodeIndex, _, err := sb.Lookup(diskPath, path, userID, groupID)
+	if err != nil {
+		return "", err
+	}
+	isUsers, err := sb.IsUsersFile(diskPath, inodeIndex)
+	if err != nil {
+		return "", err
+	}
+	if isUsers {
+		content = utils.HideUsersPasswords(content)
+	}
+	return content, nil
+}
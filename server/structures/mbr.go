@@ -3,9 +3,9 @@ package structures
 import (
 	"bytes"
 	"encoding/binary"
-	"errors"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 	"time"
 )
@@ -62,17 +62,31 @@ func (mbr *MBR) DeserializeMBR(path string) error {
 	return nil
 }
 
-func (mbr *MBR) GetFirstAvailablePartition() (*PARTITION, int, int) {
-	offset := binary.Size(mbr)
+// GetFreeGaps lista los espacios libres entre el MBR, las particiones y el final del disco
+func (mbr *MBR) GetFreeGaps() []Gap {
+	var used []Gap
+	for _, partition := range mbr.Mbr_partitions {
+		if partition.Part_start != -1 && partition.Part_size > 0 {
+			used = append(used, Gap{Start: partition.Part_start, Size: partition.Part_size})
+		}
+	}
+	sort.Slice(used, func(i, j int) bool { return used[i].Start < used[j].Start })
+	return FreeGaps(int32(binary.Size(mbr)), mbr.Mbr_size, used)
+}
 
+// GetAvailablePartition devuelve un espacio libre del MBR y el inicio del hueco elegido segun el ajuste
+func (mbr *MBR) GetAvailablePartition(sizeBytes int, fit byte) (*PARTITION, int, int, error) {
 	for i := 0; i < len(mbr.Mbr_partitions); i++ {
-		if mbr.Mbr_partitions[i].Part_start == -1 {
-			return &mbr.Mbr_partitions[i], offset, i
-		} else {
-			offset += int(mbr.Mbr_partitions[i].Part_size)
+		if mbr.Mbr_partitions[i].Part_start != -1 {
+			continue
 		}
+		gap, ok := SelectGap(mbr.GetFreeGaps(), int32(sizeBytes), fit)
+		if !ok {
+			return nil, -1, -1, NewError(ErrNoSpace, "no se puede crear una particion por falta de espacio")
+		}
+		return &mbr.Mbr_partitions[i], int(gap.Start), i, nil
 	}
-	return nil, -1, -1
+	return nil, -1, -1, NewError(ErrNoSpace, "no hay particiones disponibles")
 }
 
 func (mbr *MBR) GetPartitionByName(name string) (*PARTITION, int) {
@@ -102,7 +116,7 @@ func (mbr *MBR) GetOffsetFirstEBR() (int32, int32, error) {
 			return mbr.Mbr_partitions[i].Part_start, mbr.Mbr_partitions[i].Part_size, nil
 		}
 	}
-	return -1, -1, errors.New("no hay una particion extendida")
+	return -1, -1, NewError(ErrNotFound, "no hay una particion extendida")
 }
 
 func (mbr *MBR) PrintMBR() {
@@ -137,17 +151,6 @@ func (mbr *MBR) PrintPartitions() {
 	}
 }
 
-func (mbr *MBR) CanFitAnotherDisk(sizeBytes int) bool {
-	bytesUsed := binary.Size(mbr)
-	for i := 0; i < len(mbr.Mbr_partitions); i++ {
-		if mbr.Mbr_partitions[i].Part_size != -1 {
-			bytesUsed += int(mbr.Mbr_partitions[i].Part_size)
-		}
-	}
-	return bytesUsed+sizeBytes <= int(mbr.Mbr_size)
-
-}
-
 func (mbr *MBR) GetPartitionByID(id string) (*PARTITION, int, error) {
 	for i := 0; i < len(mbr.Mbr_partitions); i++ {
 		partitionID := strings.Trim(string(mbr.Mbr_partitions[i].Part_id[:]), "\x00 ")
@@ -156,7 +159,7 @@ func (mbr *MBR) GetPartitionByID(id string) (*PARTITION, int, error) {
 			return &mbr.Mbr_partitions[i], i, nil
 		}
 	}
-	return nil, 0, errors.New("partición no encontrada LOL")
+	return nil, 0, NewError(ErrNotFound, "partición no encontrada LOL")
 }
 
 func (mbr *MBR) GetExtendedPartition() (*PARTITION, error) {
@@ -165,6 +168,6 @@ func (mbr *MBR) GetExtendedPartition() (*PARTITION, error) {
 			return &mbr.Mbr_partitions[i], nil
 		}
 	}
-	return nil, errors.New("particion no encontada")
+	return nil, NewError(ErrNotFound, "particion no encontada")
 }
 
@@ -0,0 +1,179 @@
+package structures
+
+type blockSerializer interface {
+	Serialize(path string, offset int64) error
+}
+
+// resizeState guarda en memoria el arbol compactado, los indices nuevos son el orden en que se recorre desde la raiz
+type resizeState struct {
+	diskPath string
+	inodes   []*Inode
+	blocks   []blockSerializer
+	newIndex map[int32]int32 // inodo viejo -> inodo nuevo
+	used     map[int32]bool  // bloques viejos ya copiados
+}
+
+// Resize copia el sistema de archivos a la distribucion de neo, que viene de mkfs con el nuevo tamaño.
+// Los inodos y bloques alcanzables desde la raiz se renumeran en orden, asi los huecos que dejo rm no cuentan como espacio usado.
+// Todo se lee antes de escribir, si no cabe no se toca el disco. El superbloque neo queda actualizado y quien llama lo serializa.
+func (sb *SuperBlock) Resize(diskPath string, neo *SuperBlock) error {
+	st := &resizeState{
+		diskPath: diskPath,
+		newIndex: make(map[int32]int32),
+		used:     make(map[int32]bool),
+	}
+	_, err := sb.resizeInode(st, 0, 0)
+	if err != nil {
+		return err
+	}
+
+	usedInodes, usedBlocks := int32(len(st.inodes)), int32(len(st.blocks))
+	if usedInodes > neo.TotalInodes() || usedBlocks > neo.TotalBlocks() {
+		return Errorf(ErrNoSpace, "no se puede reducir por debajo del espacio usado: se usan %d inodos y %d bloques y con el nuevo tamaño caben %d inodos y %d bloques",
+			usedInodes, usedBlocks, neo.TotalInodes(), neo.TotalBlocks())
+	}
+
+	// Se limpian bitmaps, tabla de inodos y bloques en la nueva distribucion
+	err = writeBitmap(diskPath, neo.S_bm_inode_start, make([]byte, neo.S_block_start+neo.TotalBlocks()*neo.S_block_size-neo.S_bm_inode_start))
+	if err != nil {
+		return err
+	}
+	inodeBitmap := make([]byte, neo.TotalInodes())
+	for i := range inodeBitmap {
+		inodeBitmap[i] = '0'
+		if int32(i) < usedInodes {
+			inodeBitmap[i] = '1'
+		}
+	}
+	blockBitmap := make([]byte, neo.TotalBlocks())
+	for i := range blockBitmap {
+		blockBitmap[i] = 'O'
+		if int32(i) < usedBlocks {
+			blockBitmap[i] = 'X'
+		}
+	}
+	err = writeBitmap(diskPath, neo.S_bm_inode_start, inodeBitmap)
+	if err != nil {
+		return err
+	}
+	err = writeBitmap(diskPath, neo.S_bm_block_start, blockBitmap)
+	if err != nil {
+		return err
+	}
+	for i, inode := range st.inodes {
+		err := inode.Serialize(diskPath, int64(neo.S_inode_start+neo.S_inode_size*int32(i)))
+		if err != nil {
+			return err
+		}
+	}
+	for i, block := range st.blocks {
+		err := block.Serialize(diskPath, int64(neo.S_block_start+neo.S_block_size*int32(i)))
+		if err != nil {
+			return err
+		}
+	}
+
+	neo.S_inodes_count = usedInodes
+	neo.S_blocks_count = usedBlocks
+	neo.S_free_inodes_count = neo.TotalInodes() - usedInodes
+	neo.S_free_blocks_count = neo.TotalBlocks() - usedBlocks
+	neo.S_first_ino = neo.S_inode_start + usedInodes*neo.S_inode_size
+	neo.S_first_blo = neo.S_block_start + usedBlocks*neo.S_block_size
+	neo.S_mtime = sb.S_mtime
+	neo.S_umtime = sb.S_umtime
+	neo.S_mnt_count = sb.S_mnt_count
+	return nil
+}
+
+// resizeInode copia el inodo y sus bloques al estado y devuelve su indice nuevo
+func (sb *SuperBlock) resizeInode(st *resizeState, index, parent int32) (int32, error) {
+	if index < 0 || index >= sb.TotalInodes() {
+		return -1, Errorf(ErrCorrupt, "el inodo %d esta fuera de rango, ejecute fsck -repair antes de redimensionar", index)
+	}
+	if _, ok := st.newIndex[index]; ok {
+		return -1, Errorf(ErrCorrupt, "el inodo %d esta enlazado dos veces, ejecute fsck -repair antes de redimensionar", index)
+	}
+	inode := &Inode{}
+	err := inode.Deserialize(st.diskPath, int64(sb.S_inode_start+sb.S_inode_size*index))
+	if err != nil {
+		return -1, err
+	}
+	if inode.I_type[0] != '0' && inode.I_type[0] != '1' {
+		return -1, Errorf(ErrCorrupt, "el inodo %d no es carpeta ni archivo, ejecute fsck -repair antes de redimensionar", index)
+	}
+	newIndex := int32(len(st.inodes))
+	st.newIndex[index] = newIndex
+	st.inodes = append(st.inodes, inode)
+
+	for i, blockIndex := range inode.I_block {
+		if blockIndex == -1 {
+			continue
+		}
+		inode.I_block[i], err = sb.resizeBlock(st, blockIndex, IndirectionLevel(i), inode.I_type[0], newIndex, parent)
+		if err != nil {
+			return -1, err
+		}
+	}
+	return newIndex, nil
+}
+
+// resizeBlock copia el bloque, y lo que cuelga de el, con los apuntadores y entradas traducidos a los indices nuevos
+func (sb *SuperBlock) resizeBlock(st *resizeState, blockIndex int32, level int, tipo byte, self, parent int32) (int32, error) {
+	if blockIndex < 0 || blockIndex >= sb.TotalBlocks() || st.used[blockIndex] {
+		return -1, Errorf(ErrCorrupt, "el bloque %d esta fuera de rango o repetido, ejecute fsck -repair antes de redimensionar", blockIndex)
+	}
+	st.used[blockIndex] = true
+	offset := int64(sb.S_block_start + sb.S_block_size*blockIndex)
+	newIndex := int32(len(st.blocks))
+	// Se reserva el lugar antes de bajar para que los bloques queden en el mismo orden que los recorre el inodo
+	st.blocks = append(st.blocks, nil)
+
+	switch {
+	case level > 0:
+		pointerBlock := &PointerBlock{}
+		err := pointerBlock.Deserialize(st.diskPath, offset)
+		if err != nil {
+			return -1, err
+		}
+		for i, value := range pointerBlock.P_pointers {
+			if value == -1 {
+				continue
+			}
+			pointerBlock.P_pointers[i], err = sb.resizeBlock(st, value, level-1, tipo, self, parent)
+			if err != nil {
+				return -1, err
+			}
+		}
+		st.blocks[newIndex] = pointerBlock
+	case tipo == '0':
+		folderBlock := &FolderBlock{}
+		err := folderBlock.Deserialize(st.diskPath, offset)
+		if err != nil {
+			return -1, err
+		}
+		for i := range folderBlock.B_content {
+			content := &folderBlock.B_content[i]
+			switch {
+			case content.B_inodo == -1:
+			case i == 0:
+				content.B_inodo = self
+			case i == 1:
+				content.B_inodo = parent
+			default:
+				content.B_inodo, err = sb.resizeInode(st, content.B_inodo, self)
+				if err != nil {
+					return -1, err
+				}
+			}
+		}
+		st.blocks[newIndex] = folderBlock
+	default:
+		fileBlock := &FileBlock{}
+		err := fileBlock.Deserialize(st.diskPath, offset)
+		if err != nil {
+			return -1, err
+		}
+		st.blocks[newIndex] = fileBlock
+	}
+	return newIndex, nil
+}
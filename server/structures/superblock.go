@@ -3,11 +3,9 @@ package structures
 import (
 	"bytes"
 	"encoding/binary"
-	"errors"
 	"fmt"
 	"os"
 	"regexp"
-	utils "server/utils"
 	"strings"
 	"time"
 )
@@ -207,8 +205,8 @@ func (sb *SuperBlock) CreateUsersFile(path string, journauling_start int64) erro
 				I_date:      float32(time.Now().Unix()),
 			},
 		}
-		// Copiamos el texto de usuarios en el journal
-		copy(journalFile.J_content.I_content[:], usersText)
+		// El journal guarda users.txt sin el password de root, igual que login solo guarda id/usuario
+		copy(journalFile.J_content.I_content[:], "1,G,root\n1,U,root,root\n")
 
 		// err = journalFile.Serialize(path, journauling_start)
 		err = sb.AddJournal(journalFile, path, int32(journauling_start))
@@ -298,10 +296,11 @@ func (sb *SuperBlock) PrintBlocks(path string) error {
 		if err != nil {
 			return err
 		}
-		for _, blockIndex := range inode.I_block {
-			if blockIndex == -1 {
-				continue
-			}
+		data, _, err := sb.InodeBlocks(path, inode)
+		if err != nil {
+			return err
+		}
+		for _, blockIndex := range data {
 			if inode.I_type[0] == '0' {
 				block := &FolderBlock{}
 				err := block.Deserialize(path, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
@@ -329,33 +328,82 @@ func (sb *SuperBlock) PrintBlocks(path string) error {
 	return nil
 }
 
-func (sb *SuperBlock) CreateFolder(path string, parentsDir []string, destDir string, flag bool) error {
+func (sb *SuperBlock) CreateFolder(path string, parentsDir []string, destDir string, flag bool, userID, groupID int32) error {
 	if !flag {
-		return sb.createFolderInInode(path, 0, parentsDir, destDir, false)
+		return sb.createFolderInInode(path, 0, parentsDir, destDir, false, userID, groupID)
 	} else {
-		return sb.createFolderInInodeWithP(path, 0, parentsDir, destDir)
+		return sb.createFolderInInodeWithP(path, 0, parentsDir, destDir, userID, groupID)
 	}
 }
 
+// AddJournal agrega la entrada al final de la cadena que empieza en offset, el journal termina donde empieza el bitmap de inodos
 func (sb *SuperBlock) AddJournal(neoJournal *Journal, path string, offset int32) error {
-	journal := &Journal{}
-	err := journal.Deserialize(path, int64(offset))
+	journals, offsets, err := sb.JournalChain(path, offset)
 	if err != nil {
 		return err
 	}
-	if journal.J_next != -1 {
-		return sb.AddJournal(neoJournal, path, journal.J_next)
+	if int32(len(journals)) == sb.journalCapacity(offset) {
+		return NewError(ErrNoSpace, "el journal esta lleno")
 	}
-	position := offset + int32(binary.Size(Journal{}))
-	journal.J_next = position
-	err = journal.Serialize(path, int64(offset))
+	last := &journals[len(journals)-1]
+	position := offsets[len(offsets)-1] + int32(binary.Size(Journal{}))
+	last.J_next = position
+	err = last.Serialize(path, int64(offsets[len(offsets)-1]))
 	if err != nil {
 		return err
 	}
-	neoJournal.Serialize(path, int64(position))
+	return neoJournal.Serialize(path, int64(position))
+}
+
+// JournalChain lee la cadena del journal que empieza en offset. No pasa de las n entradas del area del journal,
+// una cadena que se sale de ella esta dañada y lo que sigue ya es el bitmap de inodos
+func (sb *SuperBlock) JournalChain(path string, offset int32) ([]Journal, []int32, error) {
+	journalSize := int32(binary.Size(Journal{}))
+	capacity := sb.journalCapacity(offset)
+	var journals []Journal
+	var offsets []int32
+	for position := offset; position != -1; {
+		if int32(len(journals)) == capacity || position < offset || position+journalSize > sb.S_bm_inode_start {
+			return nil, nil, Errorf(ErrCorrupt, "la cadena del journal se sale de su area despues de %d entradas", len(journals))
+		}
+		journal := Journal{}
+		err := journal.Deserialize(path, int64(position))
+		if err != nil {
+			return nil, nil, err
+		}
+		journals = append(journals, journal)
+		offsets = append(offsets, position)
+		position = journal.J_next
+	}
+	return journals, offsets, nil
+}
+
+// JournalFreeSlots devuelve cuantas entradas caben todavia en el journal que empieza en offset
+func (sb *SuperBlock) JournalFreeSlots(path string, offset int32) (int32, error) {
+	journals, _, err := sb.JournalChain(path, offset)
+	if err != nil {
+		return 0, err
+	}
+	return sb.journalCapacity(offset) - int32(len(journals)), nil
+}
+
+// CheckJournalSpace se llama antes de escribir en el disco, asi un comando que no cabe en el journal no deja nada a medias
+func (sb *SuperBlock) CheckJournalSpace(path string, offset int32, entries int) error {
+	free, err := sb.JournalFreeSlots(path, offset)
+	if err != nil {
+		return err
+	}
+	if int32(entries) > free {
+		return Errorf(ErrNoSpace, "el journal no tiene espacio: la operacion necesita %d entradas y quedan %d", entries, free)
+	}
 	return nil
 }
 
+// journalCapacity son las n entradas que mkfs reservo entre offset y el bitmap de inodos
+func (sb *SuperBlock) journalCapacity(offset int32) int32 {
+	return (sb.S_bm_inode_start - offset) / int32(binary.Size(Journal{}))
+}
+
 func (sb *SuperBlock) IsExt3() bool {
 	return sb.S_filesystem_type == 3
 }
@@ -377,53 +425,25 @@ func (sb *SuperBlock) ChmodRecursive(diskPath string, indexInode int32, permissi
 			return err
 		}
 		if inode.I_type[0] == '0' {
-			for i, blockIndex := range inode.I_block {
-				if blockIndex == -1 {
-					continue
+			indexes, err := sb.FolderBlockIndexes(diskPath, inode)
+			if err != nil {
+				return err
+			}
+			for _, blockIndex := range indexes {
+				block := &FolderBlock{}
+				err := block.Deserialize(diskPath, int64(sb.S_block_start+sb.S_block_size*blockIndex))
+				if err != nil {
+					return err
 				}
-				if i >= 14 {
-					pointerBlock := &PointerBlock{}
-					err := pointerBlock.Deserialize(diskPath, int64(sb.S_block_start+sb.S_block_size*blockIndex))
-					if err != nil {
-						return err
-					}
-					for _, value := range pointerBlock.P_pointers {
-						if value == -1 {
-							continue
-						}
-						block := &FolderBlock{}
-						err := block.Deserialize(diskPath, int64(sb.S_block_start+sb.S_block_size*value))
-						if err != nil {
-							return err
-						}
-						for indexContent := 2; indexContent < len(block.B_content); indexContent++ {
-							content := block.B_content[indexContent]
-							if content.B_inodo == -1 {
-								continue
-							}
-							err := sb.ChmodRecursive(diskPath, content.B_inodo, permissions, userLogedId, userGroupID)
-							if err != nil {
-								return err
-							}
-						}
+				for indexContent := 2; indexContent < len(block.B_content); indexContent++ {
+					content := block.B_content[indexContent]
+					if content.B_inodo == -1 {
+						continue
 					}
-				} else {
-					block := &FolderBlock{}
-					err := block.Deserialize(diskPath, int64(sb.S_block_start+sb.S_block_size*blockIndex))
+					err := sb.ChmodRecursive(diskPath, content.B_inodo, permissions, userLogedId, userGroupID)
 					if err != nil {
 						return err
 					}
-					for indexContent := 2; indexContent < len(block.B_content); indexContent++ {
-						content := block.B_content[indexContent]
-						if content.B_inodo == -1 {
-							continue
-						}
-						err := sb.ChmodRecursive(diskPath, content.B_inodo, permissions, userLogedId, userGroupID)
-						if err != nil {
-							return err
-						}
-					}
-
 				}
 			}
 		}
@@ -455,53 +475,25 @@ func (sb *SuperBlock) ChownRecursive(diskPath string, indexInode int32, userLoge
 			return err
 		}
 		if inode.I_type[0] == '0' {
-			for i, blockIndex := range inode.I_block {
-				if blockIndex == -1 {
-					continue
+			indexes, err := sb.FolderBlockIndexes(diskPath, inode)
+			if err != nil {
+				return err
+			}
+			for _, blockIndex := range indexes {
+				block := &FolderBlock{}
+				err := block.Deserialize(diskPath, int64(sb.S_block_start+sb.S_block_size*blockIndex))
+				if err != nil {
+					return err
 				}
-				if i >= 14 {
-					pointerBlock := &PointerBlock{}
-					err := pointerBlock.Deserialize(diskPath, int64(sb.S_block_start+sb.S_block_size*blockIndex))
-					if err != nil {
-						return err
-					}
-					for _, value := range pointerBlock.P_pointers {
-						if value == -1 {
-							continue
-						}
-						block := &FolderBlock{}
-						err := block.Deserialize(diskPath, int64(sb.S_block_start+sb.S_block_size*value))
-						if err != nil {
-							return err
-						}
-						for indexContent := 2; indexContent < len(block.B_content); indexContent++ {
-							content := block.B_content[indexContent]
-							if content.B_inodo == -1 {
-								continue
-							}
-							err := sb.ChownRecursive(diskPath, content.B_inodo, userLogedId, userGroupID, userIdNeoOwner, false)
-							if err != nil {
-								return err
-							}
-						}
+				for indexContent := 2; indexContent < len(block.B_content); indexContent++ {
+					content := block.B_content[indexContent]
+					if content.B_inodo == -1 {
+						continue
 					}
-				} else {
-					block := &FolderBlock{}
-					err := block.Deserialize(diskPath, int64(sb.S_block_start+sb.S_block_size*blockIndex))
+					err := sb.ChownRecursive(diskPath, content.B_inodo, userLogedId, userGroupID, userIdNeoOwner, false)
 					if err != nil {
 						return err
 					}
-					for indexContent := 2; indexContent < len(block.B_content); indexContent++ {
-						content := block.B_content[indexContent]
-						if content.B_inodo == -1 {
-							continue
-						}
-						err := sb.ChownRecursive(diskPath, content.B_inodo, userLogedId, userGroupID, userIdNeoOwner, false)
-						if err != nil {
-							return err
-						}
-					}
-
 				}
 			}
 		}
@@ -520,56 +512,28 @@ func (sb *SuperBlock) MoveTreePermissions(diskPath string, indexInode int32, use
 		return err
 	}
 	if !outcome {
-		return errors.New("hay un inodo que no tiene los permisos adecuados para tal accion")
+		return NewError(ErrPermission, "hay un inodo que no tiene los permisos adecuados para tal accion")
 	}
 	if inode.I_type[0] == '0' {
-		for i, blockIndex := range inode.I_block {
-			if blockIndex == -1 {
-				continue
+		indexes, err := sb.FolderBlockIndexes(diskPath, inode)
+		if err != nil {
+			return err
+		}
+		for _, blockIndex := range indexes {
+			block := &FolderBlock{}
+			err := block.Deserialize(diskPath, int64(sb.S_block_start+sb.S_block_size*blockIndex))
+			if err != nil {
+				return err
 			}
-			if i >= 14 {
-				pointerBlock := &PointerBlock{}
-				err := pointerBlock.Deserialize(diskPath, int64(sb.S_block_start+sb.S_block_size*blockIndex))
-				if err != nil {
-					return err
-				}
-				for _, value := range pointerBlock.P_pointers {
-					if value == -1 {
-						continue
-					}
-					block := &FolderBlock{}
-					err := block.Deserialize(diskPath, int64(sb.S_block_start+sb.S_block_size*value))
-					if err != nil {
-						return err
-					}
-					for indexContent := 2; indexContent < len(block.B_content); indexContent++ {
-						content := block.B_content[indexContent]
-						if content.B_inodo == -1 {
-							continue
-						}
-						err := sb.MoveTreePermissions(diskPath, content.B_inodo, userLogedId, userGroupID)
-						if err != nil {
-							return err
-						}
-					}
+			for indexContent := 2; indexContent < len(block.B_content); indexContent++ {
+				content := block.B_content[indexContent]
+				if content.B_inodo == -1 {
+					continue
 				}
-			} else {
-				block := &FolderBlock{}
-				err := block.Deserialize(diskPath, int64(sb.S_block_start+sb.S_block_size*blockIndex))
+				err := sb.MoveTreePermissions(diskPath, content.B_inodo, userLogedId, userGroupID)
 				if err != nil {
 					return err
 				}
-				for indexContent := 2; indexContent < len(block.B_content); indexContent++ {
-					content := block.B_content[indexContent]
-					if content.B_inodo == -1 {
-						continue
-					}
-					err := sb.MoveTreePermissions(diskPath, content.B_inodo, userLogedId, userGroupID)
-					if err != nil {
-						return err
-					}
-				}
-
 			}
 		}
 	}
@@ -579,7 +543,7 @@ func (sb *SuperBlock) MoveTreePermissions(diskPath string, indexInode int32, use
 func (sb *SuperBlock) TypeOfInode(diskPath string, indexInode int32) (int32, error) {
 	// Verificar que el índice del inodo sea válido
 	if indexInode < 0 || indexInode >= sb.S_inodes_count {
-		return -1, errors.New("índice de inodo fuera de rango")
+		return -1, NewError(ErrCorrupt, "índice de inodo fuera de rango")
 	}
 
 	inode := &Inode{}
@@ -597,16 +561,16 @@ func (sb *SuperBlock) TypeOfInode(diskPath string, indexInode int32) (int32, err
 	}
 
 	// Si llegamos aquí, el tipo no es válido
-	return -1, fmt.Errorf("tipo de inodo inválido: %c (valor: %d) en inodo %d", inodeType, int(inodeType), indexInode)
+	return -1, Errorf(ErrCorrupt, "tipo de inodo inválido: %c (valor: %d) en inodo %d", inodeType, int(inodeType), indexInode)
 }
 
-func (sb *SuperBlock) CopyInode0(diskPath string, indexInodeToCopy, indexInodoPadre int32) (int32, error) { // Inodo tipo folder
+func (sb *SuperBlock) CopyInode0(diskPath string, indexInodeToCopy, indexInodoPadre int32, userID, groupID int32) (int32, error) { // Inodo tipo folder
 	inode := &Inode{}
-	err := inode.Deserialize(diskPath, int64(sb.S_inode_size*indexInodeToCopy))
+	err := inode.Deserialize(diskPath, int64(sb.S_inode_start+sb.S_inode_size*indexInodeToCopy))
 	if err != nil {
 		return -1, err
 	}
-	outcome, err := inode.HasPermissionsToRead(utils.LogedUserID, utils.LogedUserGroupID)
+	outcome, err := inode.HasPermissionsToRead(userID, groupID)
 	if err != nil {
 		return -1, err
 	}
@@ -614,7 +578,10 @@ func (sb *SuperBlock) CopyInode0(diskPath string, indexInodeToCopy, indexInodoPa
 		return -1, nil
 	}
 	// Creamos el inodo
-	resultIndex := sb.S_inodes_count
+	resultIndex, offsetInodoCopia, err := sb.reserveInode(diskPath)
+	if err != nil {
+		return -1, err
+	}
 	inodoCopia := &Inode{
 		I_uid:   inode.I_uid,
 		I_gid:   inode.I_gid,
@@ -626,103 +593,98 @@ func (sb *SuperBlock) CopyInode0(diskPath string, indexInodeToCopy, indexInodoPa
 		I_type:  inode.I_type,
 		I_perm:  inode.I_perm,
 	}
-	offsetInodoCopia := int64(sb.S_first_ino)
 	err = inodoCopia.Serialize(diskPath, offsetInodoCopia)
 	if err != nil {
 		return -1, err
 	}
-	err = sb.UpdateBitmapInode(diskPath)
+	//
+
+	indexes, err := sb.FolderBlockIndexes(diskPath, inode)
 	if err != nil {
 		return -1, err
 	}
-	sb.S_inodes_count++
-	sb.S_free_inodes_count--
-	sb.S_first_ino += sb.S_inode_size
-	//
-
-	for i, blockIndex := range inode.I_block {
-		if blockIndex == -1 {
-			continue
-		}
-		// Creamos un folderblock
-		inodoCopia.I_block[i] = sb.S_blocks_count
-		folderBlock := &FolderBlock{
-			B_content: [4]FolderContent{
-				{B_name: [12]byte{'.'}, B_inodo: resultIndex},
-				{B_name: [12]byte{'.', '.'}, B_inodo: indexInodoPadre},
-				{B_name: [12]byte{'-'}, B_inodo: -1},
-				{B_name: [12]byte{'-'}, B_inodo: -1},
-			},
-		}
-		offsetFolderBlock := int64(sb.S_first_blo)
-		err = folderBlock.Serialize(diskPath, offsetFolderBlock)
+	for position, blockIndex := range indexes {
+		copyIndex, err := sb.copyFolderBlock(diskPath, blockIndex, resultIndex, indexInodoPadre, userID, groupID)
 		if err != nil {
 			return -1, err
 		}
-		err = sb.UpdateBitmapBlock(diskPath)
+		err = sb.setInodeBlock(diskPath, inodoCopia, position, copyIndex)
 		if err != nil {
 			return -1, err
 		}
-		sb.S_blocks_count++
-		sb.S_free_blocks_count--
-		sb.S_first_blo += sb.S_block_size
-		//
+	}
+	err = inodoCopia.Serialize(diskPath, offsetInodoCopia)
+	if err != nil {
+		return -1, err
+	}
 
-		block := &FolderBlock{}
-		err := block.Deserialize(diskPath, int64(sb.S_block_start+sb.S_block_size*blockIndex))
+	return resultIndex, nil
+}
+
+// copyFolderBlock crea un bloque carpeta nuevo para la copia y copia recursivamente su contenido
+func (sb *SuperBlock) copyFolderBlock(diskPath string, blockIndex, resultIndex, indexInodoPadre int32, userID, groupID int32) (int32, error) {
+	// Creamos un folderblock
+	folderIndex, offsetFolderBlock, err := sb.reserveBlock(diskPath)
+	if err != nil {
+		return -1, err
+	}
+	folderBlock := &FolderBlock{
+		B_content: [4]FolderContent{
+			{B_name: [12]byte{'.'}, B_inodo: resultIndex},
+			{B_name: [12]byte{'.', '.'}, B_inodo: indexInodoPadre},
+			{B_name: [12]byte{'-'}, B_inodo: -1},
+			{B_name: [12]byte{'-'}, B_inodo: -1},
+		},
+	}
+	err = folderBlock.Serialize(diskPath, offsetFolderBlock)
+	if err != nil {
+		return -1, err
+	}
+	//
+
+	block := &FolderBlock{}
+	err = block.Deserialize(diskPath, int64(sb.S_block_start+sb.S_block_size*blockIndex))
+	if err != nil {
+		return -1, err
+	}
+	for indexContent := 2; indexContent < len(block.B_content); indexContent++ {
+		content := block.B_content[indexContent]
+		if content.B_inodo == -1 {
+			continue
+		}
+		tipoInodo, err := sb.TypeOfInode(diskPath, content.B_inodo)
 		if err != nil {
 			return -1, err
 		}
-		for indexContent := 2; indexContent < len(block.B_content); indexContent++ {
-			content := block.B_content[indexContent]
-			if content.B_inodo == -1 {
-				continue
-			}
-			tipoInodo, err := sb.TypeOfInode(diskPath, content.B_inodo)
-			if err != nil {
-				return -1, err
-			}
-			var inodoAIndexar int32
-			if tipoInodo == 0 {
-				inodoAIndexar, err = sb.CopyInode0(diskPath, content.B_inodo, resultIndex)
-				if err != nil {
-					return -1, err
-				}
-				if inodoAIndexar == -1 {
-					continue
-				}
-			} else {
-				inodoAIndexar, err = sb.CopyInode1(diskPath, content.B_inodo)
-				if err != nil {
-					return -1, err
-				}
-				if inodoAIndexar == -1 {
-					continue
-				}
-			}
-			copy(folderBlock.B_content[indexContent].B_name[:], content.B_name[:])
-			folderBlock.B_content[indexContent].B_inodo = inodoAIndexar
+		var inodoAIndexar int32
+		if tipoInodo == 0 {
+			inodoAIndexar, err = sb.CopyInode0(diskPath, content.B_inodo, resultIndex, userID, groupID)
+		} else {
+			inodoAIndexar, err = sb.CopyInode1(diskPath, content.B_inodo, userID, groupID)
 		}
-		err = folderBlock.Serialize(diskPath, offsetFolderBlock)
 		if err != nil {
 			return -1, err
 		}
+		if inodoAIndexar == -1 {
+			continue
+		}
+		folderBlock.B_content[indexContent].B_name = content.B_name
+		folderBlock.B_content[indexContent].B_inodo = inodoAIndexar
 	}
-	err = inodoCopia.Serialize(diskPath, offsetInodoCopia)
+	err = folderBlock.Serialize(diskPath, offsetFolderBlock)
 	if err != nil {
 		return -1, err
 	}
-
-	return resultIndex, nil
+	return folderIndex, nil
 }
 
-func (sb *SuperBlock) CopyInode1(diskPath string, indexInodeToCopy int32) (int32, error) {
+func (sb *SuperBlock) CopyInode1(diskPath string, indexInodeToCopy int32, userID, groupID int32) (int32, error) {
 	inode := &Inode{}
-	err := inode.Deserialize(diskPath, int64(sb.S_inode_size*indexInodeToCopy))
+	err := inode.Deserialize(diskPath, int64(sb.S_inode_start+sb.S_inode_size*indexInodeToCopy))
 	if err != nil {
 		return -1, err
 	}
-	outcome, err := inode.HasPermissionsToRead(utils.LogedUserID, utils.LogedUserGroupID)
+	outcome, err := inode.HasPermissionsToRead(userID, groupID)
 	if err != nil {
 		return -1, err
 	}
@@ -730,7 +692,10 @@ func (sb *SuperBlock) CopyInode1(diskPath string, indexInodeToCopy int32) (int32
 		return -1, nil
 	}
 	// Creamos el inodo
-	resultIndex := sb.S_inodes_count
+	resultIndex, offsetInodoCopia, err := sb.reserveInode(diskPath)
+	if err != nil {
+		return -1, err
+	}
 	inodoCopia := &Inode{
 		I_uid:   inode.I_uid,
 		I_gid:   inode.I_gid,
@@ -742,48 +707,21 @@ func (sb *SuperBlock) CopyInode1(diskPath string, indexInodeToCopy int32) (int32
 		I_type:  inode.I_type,
 		I_perm:  inode.I_perm,
 	}
-	offsetInodoCopia := int64(sb.S_first_ino)
 	err = inodoCopia.Serialize(diskPath, offsetInodoCopia)
 	if err != nil {
 		return -1, err
 	}
-	err = sb.UpdateBitmapInode(diskPath)
+	//
+	data, _, err := sb.InodeBlocks(diskPath, inode)
 	if err != nil {
 		return -1, err
 	}
-	sb.S_inodes_count++
-	sb.S_free_inodes_count--
-	sb.S_first_ino += sb.S_inode_size
-	//
-	for i, blockIndex := range inode.I_block {
-		if blockIndex == -1 {
-			continue
-		}
-		inodoCopia.I_block[i] = sb.S_blocks_count
-		contentBlock := &FileBlock{
-			B_content: [64]byte{},
-		}
-		offsetFolderBlock := int64(sb.S_first_blo)
-		err = contentBlock.Serialize(diskPath, offsetFolderBlock)
+	for position, blockIndex := range data {
+		copyIndex, err := sb.copyFileBlock(diskPath, blockIndex)
 		if err != nil {
 			return -1, err
 		}
-		err = sb.UpdateBitmapBlock(diskPath)
-		if err != nil {
-			return -1, err
-		}
-		sb.S_blocks_count++
-		sb.S_free_blocks_count--
-		sb.S_first_blo += sb.S_block_size
-		//
-
-		blockToGetInfo := &FileBlock{}
-		err := blockToGetInfo.Deserialize(diskPath, int64(sb.S_block_start+sb.S_block_size*blockIndex))
-		if err != nil {
-			return -1, err
-		}
-		copy(contentBlock.B_content[:], blockToGetInfo.B_content[:])
-		err = contentBlock.Serialize(diskPath, offsetFolderBlock)
+		err = sb.setInodeBlock(diskPath, inodoCopia, position, copyIndex)
 		if err != nil {
 			return -1, err
 		}
@@ -796,166 +734,259 @@ func (sb *SuperBlock) CopyInode1(diskPath string, indexInodeToCopy int32) (int32
 	return resultIndex, nil
 }
 
-func (sb *SuperBlock) RemoveInodo1(diskPath string, indexInode int32) (bool, error) { //devuelve true si se puede eliminar. Devuelve false si hay que preservar el tata
-	inode := &Inode{}
-	err := inode.Deserialize(diskPath, int64(sb.S_inode_start+sb.S_inode_size*indexInode))
+// copyFileBlock crea un bloque de archivo nuevo con el mismo contenido que blockIndex
+func (sb *SuperBlock) copyFileBlock(diskPath string, blockIndex int32) (int32, error) {
+	blockToGetInfo := &FileBlock{}
+	err := blockToGetInfo.Deserialize(diskPath, int64(sb.S_block_start+sb.S_block_size*blockIndex))
 	if err != nil {
-		return false, err
+		return -1, err
 	}
-	outcome, err := inode.HasPermissionsToWrite(utils.LogedUserID, utils.LogedUserGroupID)
+	contentIndex, offsetContentBlock, err := sb.reserveBlock(diskPath)
 	if err != nil {
-		return false, err
+		return -1, err
 	}
-	return outcome, nil
+	contentBlock := &FileBlock{
+		B_content: blockToGetInfo.B_content,
+	}
+	err = contentBlock.Serialize(diskPath, offsetContentBlock)
+	if err != nil {
+		return -1, err
+	}
+	return contentIndex, nil
 }
 
-func (sb *SuperBlock) RemoveInodo0(diskPath string, indexInode int32) (bool, error) {
-
-	resultRemoval := true
+// CopyUsage calcula cuantos inodos y bloques ocupara la copia del subarbol, omitiendo lo que el usuario no puede leer
+func (sb *SuperBlock) CopyUsage(diskPath string, indexInode int32, userID, groupID int32) (int32, int32, error) {
 	inode := &Inode{}
 	err := inode.Deserialize(diskPath, int64(sb.S_inode_start+sb.S_inode_size*indexInode))
 	if err != nil {
-		return false, err
+		return 0, 0, err
 	}
-	outcome, err := inode.HasPermissionsToWrite(utils.LogedUserID, utils.LogedUserGroupID)
+	outcome, err := inode.HasPermissionsToRead(userID, groupID)
 	if err != nil {
-		return false, err
+		return 0, 0, err
 	}
 	if !outcome {
-		return false, nil
+		return 0, 0, nil
 	}
-	for i, blockIndex := range inode.I_block {
-		if blockIndex == -1 {
-			continue
-		}
+	var inodes, blocks int32 = 1, 0
+	data, _, err := sb.InodeBlocks(diskPath, inode)
+	if err != nil {
+		return 0, 0, err
+	}
+	blocks += int32(len(data)) + PointerBlocksNeeded(len(data))
+	if inode.I_type[0] != '0' {
+		return inodes, blocks, nil
+	}
+	for _, blockIndex := range data {
 		block := &FolderBlock{}
 		err := block.Deserialize(diskPath, int64(sb.S_block_start+sb.S_block_size*blockIndex))
 		if err != nil {
-			return false, err
+			return 0, 0, err
 		}
-		row := []bool{true, true}
 		for indexContent := 2; indexContent < len(block.B_content); indexContent++ {
 			content := block.B_content[indexContent]
 			if content.B_inodo == -1 {
 				continue
 			}
-			tipoInodo, err := sb.TypeOfInode(diskPath, content.B_inodo)
+			childInodes, childBlocks, err := sb.CopyUsage(diskPath, content.B_inodo, userID, groupID)
 			if err != nil {
-				return false, err
-			}
-			if tipoInodo == 0 {
-				row[indexContent-2], err = sb.RemoveInodo0(diskPath, content.B_inodo)
-
-				if err != nil {
-					return false, err
-				}
-			} else {
-				row[indexContent-2], err = sb.RemoveInodo1(diskPath, content.B_inodo)
-				if err != nil {
-					return false, err
-				}
-			}
-			if row[indexContent-2] {
-				for j := range content.B_name {
-					content.B_name[j] = 0
-				}
-				copy(content.B_name[:], []byte("-"))
-				content.B_inodo = -1
-				block.B_content[indexContent] = content
+				return 0, 0, err
 			}
+			inodes += childInodes
+			blocks += childBlocks
 		}
-		err = block.Serialize(diskPath, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
+	}
+	return inodes, blocks, nil
+}
+
+func (sb *SuperBlock) RemoveInodo1(diskPath string, indexInode int32, userID, groupID int32) (bool, error) { //devuelve true si se puede eliminar. Devuelve false si hay que preservar el tata
+	inode := &Inode{}
+	err := inode.Deserialize(diskPath, int64(sb.S_inode_start+sb.S_inode_size*indexInode))
+	if err != nil {
+		return false, err
+	}
+	outcome, err := inode.HasPermissionsToWrite(userID, groupID)
+	if err != nil {
+		return false, err
+	}
+	if !outcome {
+		return false, nil
+	}
+	err = sb.clearFileBlocks(diskPath, inode)
+	if err != nil {
+		return false, err
+	}
+	err = sb.FreeBitmapInode(diskPath, indexInode)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (sb *SuperBlock) RemoveInodo0(diskPath string, indexInode int32, userID, groupID int32) (bool, error) {
+
+	resultRemoval := true
+	inode := &Inode{}
+	err := inode.Deserialize(diskPath, int64(sb.S_inode_start+sb.S_inode_size*indexInode))
+	if err != nil {
+		return false, err
+	}
+	outcome, err := inode.HasPermissionsToWrite(userID, groupID)
+	if err != nil {
+		return false, err
+	}
+	if !outcome {
+		return false, nil
+	}
+	indexes, pointers, err := sb.InodeBlocks(diskPath, inode)
+	if err != nil {
+		return false, err
+	}
+	for _, blockIndex := range indexes {
+		removed, err := sb.removeFolderBlockContent(diskPath, blockIndex, userID, groupID)
 		if err != nil {
 			return false, err
 		}
-		if row[0] && row[1] {
-			inode.I_block[i] = -1
-		} else if !row[0] || !row[1] {
+		if !removed {
 			resultRemoval = false
 		}
 	}
+	// Si algo se preservo la carpeta conserva todos sus bloques para que sigan en orden
+	if !resultRemoval {
+		return false, nil
+	}
+	for _, blockIndex := range append(indexes, pointers...) {
+		err = sb.FreeBitmapBlock(diskPath, blockIndex)
+		if err != nil {
+			return false, err
+		}
+	}
+	for i := range inode.I_block {
+		inode.I_block[i] = -1
+	}
 	err = inode.Serialize(diskPath, int64(sb.S_inode_start+sb.S_inode_size*indexInode))
 	if err != nil {
 		return false, err
 	}
+	err = sb.FreeBitmapInode(diskPath, indexInode)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
 
-	return resultRemoval, nil
+// removeFolderBlockContent elimina las entradas de un bloque carpeta, devuelve true si quedo vacio
+func (sb *SuperBlock) removeFolderBlockContent(diskPath string, blockIndex int32, userID, groupID int32) (bool, error) {
+	block := &FolderBlock{}
+	err := block.Deserialize(diskPath, int64(sb.S_block_start+sb.S_block_size*blockIndex))
+	if err != nil {
+		return false, err
+	}
+	row := []bool{true, true}
+	for indexContent := 2; indexContent < len(block.B_content); indexContent++ {
+		content := block.B_content[indexContent]
+		if content.B_inodo == -1 {
+			continue
+		}
+		tipoInodo, err := sb.TypeOfInode(diskPath, content.B_inodo)
+		if err != nil {
+			return false, err
+		}
+		if tipoInodo == 0 {
+			row[indexContent-2], err = sb.RemoveInodo0(diskPath, content.B_inodo, userID, groupID)
+		} else {
+			row[indexContent-2], err = sb.RemoveInodo1(diskPath, content.B_inodo, userID, groupID)
+		}
+		if err != nil {
+			return false, err
+		}
+		if row[indexContent-2] {
+			for j := range content.B_name {
+				content.B_name[j] = 0
+			}
+			copy(content.B_name[:], []byte("-"))
+			content.B_inodo = -1
+			block.B_content[indexContent] = content
+		}
+	}
+	err = block.Serialize(diskPath, int64(sb.S_block_start+(blockIndex*sb.S_block_size)))
+	if err != nil {
+		return false, err
+	}
+	return row[0] && row[1], nil
 }
 
-func (sb *SuperBlock) CommandFind(diskPath string, indexInode int32, level int, regex string) (string, error) {
-	buffer := ""
+// CommandFind devuelve las rutas bajo path cuyo nombre cumple regex, en el orden del recorrido y cada carpeta antes que su contenido
+func (sb *SuperBlock) CommandFind(diskPath string, indexInode int32, path string, regex string, userID, groupID int32) ([]string, error) {
+	var matches []string
 	inode := &Inode{}
 	err := inode.Deserialize(diskPath, int64(sb.S_inode_start+sb.S_inode_size*indexInode))
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	outcome, err := inode.HasPermissionsToRead(utils.LogedUserID, utils.LogedUserGroupID)
+	outcome, err := inode.HasPermissionsToRead(userID, groupID)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	if !outcome {
-		return "", nil
+		return nil, nil
+	}
+	indexes, err := sb.FolderBlockIndexes(diskPath, inode)
+	if err != nil {
+		return nil, err
 	}
-	for _, blockIndex := range inode.I_block {
+	re := regexp.MustCompile(regex)
+	for _, blockIndex := range indexes {
 		if blockIndex == -1 {
 			continue
 		}
 		block := &FolderBlock{}
 		err := block.Deserialize(diskPath, int64(sb.S_block_start+sb.S_block_size*blockIndex))
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 		for indexContent := 2; indexContent < len(block.B_content); indexContent++ {
 			content := block.B_content[indexContent]
 			if content.B_inodo == -1 {
 				continue
 			}
-			flag, err := sb.HasPermissionToCommandFind(diskPath, content.B_inodo)
+			flag, err := sb.HasPermissionToCommandFind(diskPath, content.B_inodo, userID, groupID)
 			if err != nil {
-				return "", err
+				return nil, err
 			}
 
 			if !flag {
 				continue
 			}
+			contentName := strings.Trim(string(content.B_name[:]), "\x00")
+			contentPath := strings.TrimSuffix(path, "/") + "/" + contentName
+			if re.MatchString(contentName) {
+				matches = append(matches, contentPath)
+			}
 			tipoInodo, err := sb.TypeOfInode(diskPath, content.B_inodo)
 			if err != nil {
-				return "", err
+				return nil, err
 			}
 			if tipoInodo == 0 {
-				// o que coincide o que viene algo
-				contentName := strings.Trim(string(content.B_name[:]), "\x00")
-				resultado, err := sb.CommandFind(diskPath, content.B_inodo, level+1, regex)
+				resultado, err := sb.CommandFind(diskPath, content.B_inodo, contentPath, regex, userID, groupID)
 				if err != nil {
-					return "", err
-				}
-				if resultado != "" {
-					buffer += strings.Repeat("   ", level) + contentName + "\n" + resultado
-					continue
-				}
-				re := regexp.MustCompile(regex)
-				if re.MatchString(contentName) {
-					buffer += strings.Repeat("   ", level) + contentName + "\n"
-				}
-			} else {
-				contentName := strings.Trim(string(content.B_name[:]), "\x00")
-				re := regexp.MustCompile(regex)
-				if re.MatchString(contentName) {
-					buffer += strings.Repeat("   ", level) + contentName + "\n"
+					return nil, err
 				}
+				matches = append(matches, resultado...)
 			}
 		}
 	}
-	return buffer, nil
+	return matches, nil
 }
 
-func (sb *SuperBlock) HasPermissionToCommandFind(diskPath string, indexInode int32) (bool, error) {
+func (sb *SuperBlock) HasPermissionToCommandFind(diskPath string, indexInode int32, userID, groupID int32) (bool, error) {
 	inode := &Inode{}
 	err := inode.Deserialize(diskPath, int64(sb.S_inode_start+sb.S_inode_size*indexInode))
 	if err != nil {
 		return false, err
 	}
-	outcome, err := inode.HasPermissionsToRead(utils.LogedUserID, utils.LogedUserGroupID)
+	outcome, err := inode.HasPermissionsToRead(userID, groupID)
 	if err != nil {
 		return false, err
 	}
@@ -0,0 +1,127 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"strings"
+)
+
+/*
+Formato del password en users.txt: $p$<salt>$<hash>
+
+	salt: 6 bytes aleatorios
+	hash: 18 bytes de PBKDF2-HMAC-SHA256 con passwordIterations
+
+Ambos van en base64 sin padding y con el alfabeto de URL, asi no tienen ',' de users.txt ni '/' del journal.
+Son 36 caracteres, caben en la entrada de mkusr del journal junto al usuario y el grupo.
+*/
+const (
+	passwordPrefix     = "$p$"
+	passwordSaltSize   = 6
+	passwordKeySize    = 18
+	passwordIterations = 10000
+	HiddenPassword     = "********"
+)
+
+var passwordEncoding = base64.RawURLEncoding
+
+// HashPassword devuelve el password con sal nueva en el formato de users.txt
+func HashPassword(password string) (string, error) {
+	salt := make([]byte, passwordSaltSize)
+	_, err := rand.Read(salt)
+	if err != nil {
+		return "", err
+	}
+	key := pbkdf2SHA256([]byte(password), salt, passwordIterations, passwordKeySize)
+	return passwordPrefix + passwordEncoding.EncodeToString(salt) + "$" + passwordEncoding.EncodeToString(key), nil
+}
+
+// IsPasswordHash indica si el valor guardado ya es un hash, si no es un password en texto plano de un users.txt viejo.
+// Solo sirve para leer users.txt, lo que escribe el usuario siempre se pasa por HashPassword.
+func IsPasswordHash(stored string) bool {
+	_, _, ok := splitPasswordHash(stored)
+	return ok
+}
+
+// splitPasswordHash separa la sal y la llave, tienen que medir lo que genera HashPassword
+func splitPasswordHash(stored string) ([]byte, []byte, bool) {
+	if !strings.HasPrefix(stored, passwordPrefix) {
+		return nil, nil, false
+	}
+	parts := strings.Split(strings.TrimPrefix(stored, passwordPrefix), "$")
+	if len(parts) != 2 {
+		return nil, nil, false
+	}
+	salt, err := passwordEncoding.DecodeString(parts[0])
+	if err != nil || len(salt) != passwordSaltSize {
+		return nil, nil, false
+	}
+	key, err := passwordEncoding.DecodeString(parts[1])
+	if err != nil || len(key) != passwordKeySize {
+		return nil, nil, false
+	}
+	return salt, key, true
+}
+
+// CheckPassword compara el password con lo guardado en users.txt, sea hash o texto plano
+func CheckPassword(stored, password string) bool {
+	salt, key, ok := splitPasswordHash(stored)
+	if !ok {
+		return subtle.ConstantTimeCompare([]byte(stored), []byte(password)) == 1
+	}
+	return hmac.Equal(key, pbkdf2SHA256([]byte(password), salt, passwordIterations, passwordKeySize))
+}
+
+// HideUsersPasswords reemplaza el password de cada usuario del contenido de users.txt
+func HideUsersPasswords(content string) string {
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		fields := strings.Split(line, ",")
+		if len(fields) == 5 && fields[1] == "U" {
+			fields[4] = HiddenPassword
+			lines[i] = strings.Join(fields, ",")
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// HideJournalPassword quita el password del contenido de una entrada del journal: mkusr guarda usuario/hash/grupo,
+// passwd usuario/hash y mkfile de /users.txt las lineas del archivo
+func HideJournalPassword(operation, path, content string) string {
+	fields := strings.Split(content, "/")
+	switch {
+	case operation == "mkusr" && len(fields) == 3, operation == "passwd" && len(fields) == 2:
+		fields[1] = HiddenPassword
+		return strings.Join(fields, "/")
+	case operation == "mkfile" && path == "/users.txt":
+		return HideUsersPasswords(content)
+	}
+	return content
+}
+
+// pbkdf2SHA256 es PBKDF2 (RFC 8018) con HMAC-SHA256
+func pbkdf2SHA256(password, salt []byte, iterations, keyLen int) []byte {
+	prf := hmac.New(sha256.New, password)
+	var key []byte
+	for block := uint32(1); len(key) < keyLen; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		binary.Write(prf, binary.BigEndian, block)
+		u := prf.Sum(nil)
+		t := append([]byte{}, u...)
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		key = append(key, t...)
+	}
+	return key[:keyLen]
+}
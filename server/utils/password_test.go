@@ -0,0 +1,37 @@
+package utils
+
+import "testing"
+
+func TestCheckPassword(t *testing.T) {
+	hash, err := HashPassword("clave")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsPasswordHash(hash) || !CheckPassword(hash, "clave") || CheckPassword(hash, "otra") {
+		t.Fatalf("el hash %s no valida su password", hash)
+	}
+
+	// Lo que no tiene la sal y la llave del tamaño de HashPassword es texto plano de un users.txt viejo
+	for _, stored := range []string{"123", "$p$AA$AA", "$p$" + hash[3:11] + "$AA", "$p$$", "$p$a$b$c"} {
+		if IsPasswordHash(stored) {
+			t.Errorf("%s no deberia pasar por hash", stored)
+		}
+		if !CheckPassword(stored, stored) || CheckPassword(stored, "x23") {
+			t.Errorf("%s deberia compararse como texto plano", stored)
+		}
+	}
+}
+
+func TestHideJournalPassword(t *testing.T) {
+	for _, c := range []struct{ operation, path, content, want string }{
+		{"mkusr", "", "ana/$p$abc$def/devs", "ana/" + HiddenPassword + "/devs"},
+		{"passwd", "", "ana/$p$abc$def", "ana/" + HiddenPassword},
+		{"mkfile", "/users.txt", "1,G,root\n1,U,root,root,123\n", "1,G,root\n1,U,root,root," + HiddenPassword + "\n"},
+		{"mkfile", "/docs/users.txt", "1,U,root,root,123", "1,U,root,root,123"},
+		{"login", "", "A105/ana", "A105/ana"},
+	} {
+		if got := HideJournalPassword(c.operation, c.path, c.content); got != c.want {
+			t.Errorf("%s %s: %q, se esperaba %q", c.operation, c.path, got, c.want)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	pathpkg "path"
 	"path/filepath"
 	"strings"
 )
@@ -26,9 +27,6 @@ var alphabet = []string{
 	"N", "O", "P", "Q", "R", "S", "T", "U", "V", "W", "X", "Y", "Z",
 }
 
-var LogedUserID int32 = 1
-var LogedUserGroupID int32 = 1
-
 var PathToLetter = make(map[string]string)
 
 var nextLetterIndex = 0
@@ -42,11 +40,20 @@ func GetLetterToDisk() string {
 	return letter
 }
 
+// LetterCounters devuelve los contadores de letras para guardarlos con el estado
+func LetterCounters() (int, int32) {
+	return nextLetterIndex, letterCounterDisks
+}
+
+func SetLetterCounters(letterIndex int, disks int32) {
+	nextLetterIndex = letterIndex
+	letterCounterDisks = disks
+}
+
 func GetLetter(path string) (string, int, error) {
 	if _, exists := PathToLetter[path]; !exists {
 		if nextLetterIndex < len(alphabet) {
 			PathToLetter[path] = alphabet[nextLetterIndex]
-			PathToPartitionCount[path] = 0
 			nextLetterIndex++
 		} else {
 			fmt.Println("Error: no hay más letras disponibles para asignar")
@@ -78,8 +85,20 @@ func GetFileNames(path string) (string, string) {
 	return dotFileName, outpuImage
 }
 
+// ResolvePath convierte path en una ruta absoluta desde workingDir, resolviendo ".", ".." y las "/" repetidas.
+// ".." en la raiz se queda en la raiz.
+func ResolvePath(workingDir, path string) string {
+	if !strings.HasPrefix(path, "/") {
+		if workingDir == "" {
+			workingDir = "/"
+		}
+		path = workingDir + "/" + path
+	}
+	return pathpkg.Clean(path)
+}
+
 func GetParentDirectories(path string) ([]string, string) {
-	path = filepath.Clean(path)
+	path = ResolvePath("/", path)
 	components := strings.Split(path, string(filepath.Separator))
 	var parentDirs []string
 	for i := 1; i < len(components)-1; i++ {
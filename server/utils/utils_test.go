@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResolvePath(t *testing.T) {
+	for _, c := range []struct {
+		workingDir string
+		path       string
+		resolved   string
+	}{
+		{"/", "a", "/a"},
+		{"", "a", "/a"},
+		{"/home/ana", "docs/a.txt", "/home/ana/docs/a.txt"},
+		{"/home/ana", "/etc", "/etc"},
+		{"/home/ana", ".", "/home/ana"},
+		{"/home/ana", "./docs", "/home/ana/docs"},
+		{"/home/ana", "..", "/home"},
+		{"/home/ana", "../luis/b.txt", "/home/luis/b.txt"},
+		{"/home/ana", "../../..", "/"},
+		{"/", "..", "/"},
+		{"/home", "docs//a///b/", "/home/docs/a/b"},
+		{"/", "//a/./b/../c", "/a/c"},
+		{"/home", "mis documentos/a.txt", "/home/mis documentos/a.txt"},
+	} {
+		if got := ResolvePath(c.workingDir, c.path); got != c.resolved {
+			t.Errorf("ResolvePath(%q, %q): se esperaba %q y llego %q", c.workingDir, c.path, c.resolved, got)
+		}
+	}
+}
+
+func TestGetParentDirectories(t *testing.T) {
+	for _, c := range []struct {
+		path    string
+		parents []string
+		dest    string
+	}{
+		{"/a.txt", nil, "a.txt"},
+		{"/home/ana/a.txt", []string{"home", "ana"}, "a.txt"},
+		{"/home/../etc//b/", []string{"etc"}, "b"},
+	} {
+		parents, dest := GetParentDirectories(c.path)
+		if !reflect.DeepEqual(parents, c.parents) || dest != c.dest {
+			t.Errorf("%s: se esperaba %q %q y llego %q %q", c.path, c.parents, c.dest, parents, dest)
+		}
+	}
+}